@@ -80,8 +80,9 @@ func (csModelContext) ModelType(t sqltypes.Type) (namespace, typename string, er
 	return "", "object", nil
 }
 
-func (csModelContext) AddFuncs(m template.FuncMap) {
+func (mc csModelContext) AddFuncs(m template.FuncMap) {
 	m["datareaderfunc"] = csDataReaderFunc
+	m["csdefault"] = modelTypeDefaultFunc(mc, csDefaultValue)
 }
 
 func csDataReaderFunc(t sqltypes.Type) (string, error) {
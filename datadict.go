@@ -0,0 +1,237 @@
+package sqlmodelgen
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// dataDictDialectParam is the name of the parameter that selects the SQL
+// dialect whose data type names are written in the data dictionary.
+const dataDictDialectParam = "dialect"
+
+var (
+	// DataDictMarkdownModelContext writes a Markdown data dictionary.
+	DataDictMarkdownModelContext interface {
+		ModelContext
+		TemplateDataWriter
+		ParameterizedModelContext
+	} = dataDictModelContext{format: "md", ddl: MSSQLDDLModelContext}
+
+	// DataDictHTMLModelContext writes an HTML data dictionary.
+	DataDictHTMLModelContext interface {
+		ModelContext
+		TemplateDataWriter
+		ParameterizedModelContext
+	} = dataDictModelContext{format: "html", ddl: MSSQLDDLModelContext}
+
+	_ TemplateFuncsAdder = dataDictModelContext{}
+
+	//go:embed datadict
+	dataDictFS embed.FS
+)
+
+// dataDictModelContext writes data dictionaries from the templates in
+// the datadict/<format> directory.  Column types are the data type names
+// of the ddl model context's dialect.
+type dataDictModelContext struct {
+	format string
+	ddl    *sqlDDLModelContext
+}
+
+func (mc dataDictModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return mc.ddl.ModelType(t)
+}
+
+func (mc dataDictModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s, ok := ps[dataDictDialectParam]; ok {
+		switch s {
+		case "mssql":
+			mc.ddl = MSSQLDDLModelContext
+		case "sqlite3":
+			mc.ddl = SQLiteSQLDDLModelContext
+		case "postgres":
+			mc.ddl = PostgresSQLDDLModelContext
+		case "mysql":
+			mc.ddl = MySQLSQLDDLModelContext
+		default:
+			return nil, errors.Errorf1(
+				"unsupported SQL dialect: %q", s,
+			)
+		}
+	}
+	return mc, nil
+}
+
+func (mc dataDictModelContext) AddFuncs(m template.FuncMap) {
+	m["dialect"] = func() string { return mc.ddl.dialectName }
+	m["anchor"] = dataDictAnchor
+	m["title"] = dataDictTitle
+	m["qualifiedtitle"] = dataDictQualifiedTitle
+	m["references"] = dataDictReferences
+	m["referencedby"] = dataDictReferencedBy
+	m["mdescape"] = dataDictMarkdownEscape
+}
+
+func (mc dataDictModelContext) WriteTemplateData(w io.Writer, td TemplateData) (err error) {
+	fsys, err := fs.Sub(dataDictFS, "datadict/"+mc.format)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to get %v data dictionary templates",
+			mc.format,
+		)
+	}
+	fm := make(template.FuncMap, 8)
+	t := AddFuncs(template.New("<datadict>"), fm, mc).Funcs(fm)
+	if t, err = t.ParseFS(fsys, "*.txt"); err != nil {
+		return errors.Errorf1From(
+			err, "failed to parse %v data dictionary templates",
+			mc.format,
+		)
+	}
+	bw := bufio.NewWriter(w)
+	if err = t.ExecuteTemplate(bw, "0root.txt", td); err != nil {
+		return errors.Errorf1From(
+			err, "error executing template: %v", t,
+		)
+	}
+	return bw.Flush()
+}
+
+// dataDictAnchor gets the ID of a database, schema, table or column's
+// anchor from its raw name and the raw names of its parents.  ASCII
+// letters and digits are kept and every other byte is written as "-" and
+// its two hex digits so that different names never get the same anchor.
+// (Percent-encoding would be decoded out of the links' fragments before
+// they're matched with the IDs.)
+func dataDictAnchor(v interface{}) (string, error) {
+	var names []string
+	switch v := v.(type) {
+	case *sqlstream.Database:
+		names = []string{v.RawName}
+	case *sqlstream.Schema:
+		names = []string{v.Database.RawName, v.RawName}
+	case *sqlstream.Table:
+		names = []string{v.Database.RawName, v.Schema.RawName, v.RawName}
+	case *sqlstream.Column:
+		t := v.Table
+		names = []string{t.Database.RawName, t.Schema.RawName, t.RawName, v.RawName}
+	default:
+		return "", errors.Errorf1(
+			"cannot get anchor of %[1]v (type: %[1]T)", v,
+		)
+	}
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		for _, b := range []byte(name) {
+			switch {
+			case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+				sb.WriteByte(b)
+			default:
+				fmt.Fprintf(&sb, "-%02x", b)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// dataDictTitle gets the name to show for a database, schema, table or
+// column:  Its SQL name or its raw name if it doesn't have one.
+func dataDictTitle(ns sqlstream.Names) string {
+	if ns.SQLName != "" {
+		return ns.SQLName
+	}
+	return ns.RawName
+}
+
+// dataDictQualifiedTitle gets the title of a table or column qualified with
+// the SQL names of its schema (and database, if the model has more than
+// one).
+func dataDictQualifiedTitle(v interface{}) (string, error) {
+	var t *sqlstream.Table
+	var names []string
+	switch v := v.(type) {
+	case *sqlstream.Table:
+		t = v
+	case *sqlstream.Column:
+		t = v.Table
+		names = []string{dataDictTitle(v.Names)}
+	default:
+		return "", errors.Errorf1(
+			"cannot get qualified title of %[1]v (type: %[1]T)", v,
+		)
+	}
+	names = append([]string{dataDictTitle(t.Names)}, names...)
+	if t.Schema.SQLName != "" {
+		names = append([]string{t.Schema.SQLName}, names...)
+	}
+	if len(t.Database.MetaModel.Databases) > 1 {
+		names = append([]string{dataDictTitle(t.Database.Names)}, names...)
+	}
+	return strings.Join(names, "."), nil
+}
+
+// dataDictReferences gets the column that c references or nil if c isn't a
+// foreign key column.
+func dataDictReferences(c *sqlstream.Column) *sqlstream.Column {
+	if c.FK != nil {
+		return c.FK.Column
+	}
+	if fk := ColumnExtOf(c).ForeignKey; fk != nil {
+		for i, fc := range fk.Columns {
+			if fc == c {
+				return fk.RefColumns[i]
+			}
+		}
+	}
+	return nil
+}
+
+// dataDictReferencedBy gets the columns that reference c, whether by their
+// FKs or as part of composite foreign keys.
+func dataDictReferencedBy(c *sqlstream.Column) []*sqlstream.Column {
+	cols := append([]*sqlstream.Column(nil), c.FKCols...)
+	has := func(c *sqlstream.Column) bool {
+		for _, x := range cols {
+			if x == c {
+				return true
+			}
+		}
+		return false
+	}
+	for _, db := range c.Table.Schema.Database.MetaModel.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				for _, fk := range TableExtOf(t).ForeignKeys {
+					for i, rc := range fk.RefColumns {
+						if rc == c && !has(fk.Columns[i]) {
+							cols = append(cols, fk.Columns[i])
+						}
+					}
+				}
+			}
+		}
+	}
+	return cols
+}
+
+// dataDictMarkdownEscape escapes s so that it can be put into a Markdown
+// table cell.
+func dataDictMarkdownEscape(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,145 @@
+package sqlmodelgen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testDataDictModelJSON has tables and columns whose names only differ
+// by their punctuation and case.
+const testDataDictModelJSON = `{
+	"databases": [{
+		"rawName": "shop",
+		"schemas": [{
+			"rawName": "sales",
+			"tables": [{
+				"rawName": "order line",
+				"sqlName": "OrderLine1",
+				"columns": [
+					{"rawName": "line id", "type": "int(bits: 32)", "pk": true},
+					{"rawName": "line-id", "type": "int(bits: 32)"}
+				]
+			}, {
+				"rawName": "order-line",
+				"sqlName": "OrderLine2",
+				"columns": [
+					{"rawName": "line id", "type": "int(bits: 32)", "pk": true, "fk": "order line.line id"}
+				]
+			}, {
+				"rawName": "Order Line",
+				"sqlName": "OrderLine3",
+				"columns": [
+					{"rawName": "line id", "type": "int(bits: 32)", "pk": true}
+				]
+			}, {
+				"rawName": "order.line",
+				"sqlName": "OrderLine4",
+				"columns": [
+					{"rawName": "id", "type": "int(bits: 32)", "pk": true}
+				]
+			}]
+		}, {
+			"rawName": "sales.order",
+			"tables": [{
+				"rawName": "line",
+				"sqlName": "OrderLine5",
+				"columns": [
+					{"rawName": "id", "type": "int(bits: 32)", "pk": true}
+				]
+			}]
+		}]
+	}]
+}`
+
+func TestDataDictAnchors(t *testing.T) {
+	mm := testMetaModel(t, testDataDictModelJSON)
+	anchors := make(map[string]string)
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				vs := []interface{}{tbl}
+				for _, c := range tbl.Columns {
+					vs = append(vs, c)
+				}
+				for _, v := range vs {
+					a, err := dataDictAnchor(v)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !regexp.MustCompile(`^[A-Za-z0-9.-]+$`).MatchString(a) {
+						t.Fatalf("anchor %q isn't a plain fragment", a)
+					}
+					name, _ := dataDictQualifiedTitle(v)
+					if other, ok := anchors[a]; ok {
+						t.Fatalf("%v and %v have the same anchor %q", other, name, a)
+					}
+					anchors[a] = name
+				}
+			}
+		}
+	}
+	if _, ok := anchors["shop.sales.order-20line.line-2did"]; !ok {
+		t.Fatalf("no anchor for order line's line-id in %v", anchors)
+	}
+}
+
+func TestDataDicts(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mc   ModelContext
+		// ids and links match the anchors' IDs and the links to
+		// them.
+		ids, links *regexp.Regexp
+		want       []string
+	}{{
+		name:  "md",
+		mc:    DataDictMarkdownModelContext,
+		ids:   regexp.MustCompile(`<a id="([^"]*)"></a>`),
+		links: regexp.MustCompile(`\]\(#([^)]*)\)`),
+		want: []string{
+			"    - [OrderLine2](#shop.sales.order-2dline)\n",
+			"#### <a id=\"shop.sales.order-20line\"></a>",
+			"| <a id=\"shop.sales.order-2dline.line-20id\"></a>`LineId` | `LineId` | `integer` | No | PK, FK | " +
+				"[Sales.OrderLine1.LineId](#shop.sales.order-20line.line-20id) |  |  |\n",
+		},
+	}, {
+		name:  "html",
+		mc:    DataDictHTMLModelContext,
+		ids:   regexp.MustCompile(` id="([^"]*)"`),
+		links: regexp.MustCompile(` href="#([^"]*)"`),
+		want: []string{
+			"<li><a href=\"#shop.sales.order-2dline\">OrderLine2</a></li>\n",
+			"<h4 id=\"shop.sales.order-20line\">",
+			"<tr id=\"shop.sales.order-2dline.line-20id\">\n<td><code>LineId</code></td>\n",
+			"<td><a href=\"#shop.sales.order-20line.line-20id\">Sales.OrderLine1.LineId</a></td>\n",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			mm := testMetaModel(t, testDataDictModelJSON)
+			mc := testWithParameters(t, tc.mc, map[string]string{"dialect": "postgres"})
+			src := testWrite(t, mc, mm)
+			ids := make(map[string]bool)
+			for _, m := range tc.ids.FindAllStringSubmatch(src, -1) {
+				if ids[m[1]] {
+					t.Fatalf("duplicate ID %q in:\n%v", m[1], src)
+				}
+				ids[m[1]] = true
+			}
+			links := tc.links.FindAllStringSubmatch(src, -1)
+			if len(links) == 0 {
+				t.Fatalf("no links in:\n%v", src)
+			}
+			for _, m := range links {
+				if !ids[m[1]] {
+					t.Fatalf("link to missing ID %q in:\n%v", m[1], src)
+				}
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(src, want) {
+					t.Fatalf("missing %q in:\n%v", want, src)
+				}
+			}
+		})
+	}
+}
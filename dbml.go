@@ -0,0 +1,1301 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// dbmlDatabaseParam is the name of the parameter that holds the raw name
+// of the database to write (DBML only has one) or the raw name of the
+// database that a parsed DBML file's tables go into.
+const dbmlDatabaseParam = "database"
+
+var (
+	// DBMLModelContext writes models as DBML for dbdiagram.io.
+	DBMLModelContext interface {
+		ModelContext
+		MetaModelWriter
+		ParameterizedModelContext
+	} = dbmlModelContext{}
+
+	// DBMLConfigParserModelContext reads DBML into a model
+	// configuration.
+	DBMLConfigParserModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = dbmlConfigParser{}
+)
+
+type dbmlModelContext struct {
+	database string
+}
+
+func (dbmlModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	typename, err = dbmlType(t)
+	return
+}
+
+func (mc dbmlModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[dbmlDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+func (mc dbmlModelContext) WriteMetaModel(w io.Writer, mm *sqlstream.MetaModel) error {
+	var db *sqlstream.Database
+	switch {
+	case mc.database != "":
+		for _, x := range mm.Databases {
+			if x.RawName == mc.database {
+				db = x
+			}
+		}
+		if db == nil {
+			return errors.Errorf1("database %q not found", mc.database)
+		}
+	case len(mm.Databases) == 1:
+		db = mm.Databases[0]
+	default:
+		return errors.Errorf1(
+			"DBML can only hold one database.  Please use the "+
+				"%q parameter to choose one",
+			dbmlDatabaseParam,
+		)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("Project ")
+	buf.WriteString(dbmlName(dataDictTitle(db.Names)))
+	buf.WriteString(" {\n")
+	if db.Doc != "" {
+		buf.WriteString("  Note: ")
+		buf.WriteString(dbmlString(db.Doc))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	for _, e := range DatabaseExtOf(db).Enums {
+		dbmlWriteEnum(&buf, e)
+	}
+	for _, sch := range db.Schemas {
+		for _, t := range sch.Tables {
+			if TableExtOf(t).Enum != nil {
+				// Lookup tables are added by their Enums.
+				continue
+			}
+			if err := dbmlWriteTable(&buf, t); err != nil {
+				return errors.Errorf1From(
+					err, "failed to write table %v",
+					t.RawName,
+				)
+			}
+		}
+	}
+	first := true
+	for _, sch := range db.Schemas {
+		for _, t := range sch.Tables {
+			for _, fk := range TableExtOf(t).ForeignKeys {
+				if TableExtOf(fk.RefTable).Enum != nil {
+					continue
+				}
+				if first {
+					buf.WriteByte('\n')
+					first = false
+				}
+				dbmlWriteRef(&buf, fk)
+			}
+		}
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write DBML to %v", w,
+		)
+	}
+	return nil
+}
+
+func dbmlWriteEnum(buf *bytes.Buffer, e *Enum) {
+	buf.WriteString("\nEnum ")
+	buf.WriteString(dbmlName(dataDictTitle(e.Names)))
+	buf.WriteString(" {\n")
+	for _, m := range e.Members {
+		buf.WriteString("  ")
+		if e.IsString() {
+			buf.WriteString(strconv.Quote(m.Value))
+		} else {
+			buf.WriteString(strconv.Quote(m.RawName))
+		}
+		if m.Doc != "" {
+			buf.WriteString(" [note: ")
+			buf.WriteString(dbmlString(m.Doc))
+			buf.WriteByte(']')
+		}
+		buf.WriteByte('\n')
+	}
+	if e.Doc != "" {
+		buf.WriteString("  Note: ")
+		buf.WriteString(dbmlString(e.Doc))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+}
+
+func dbmlWriteTable(buf *bytes.Buffer, t *sqlstream.Table) error {
+	var pks []*sqlstream.Column
+	for _, c := range t.Columns {
+		if c.PK {
+			pks = append(pks, c)
+		}
+	}
+	buf.WriteString("\nTable ")
+	buf.WriteString(dbmlTableName(t))
+	buf.WriteString(" {\n")
+	for _, c := range t.Columns {
+		x := ColumnExtOf(c)
+		typ := c.Type
+		nullable := false
+		if n, ok := typ.(sqltypes.Nullable); ok {
+			typ, nullable = n[0], true
+		}
+		typename, err := dbmlType(typ)
+		if err != nil {
+			return errors.Errorf1From(
+				err, "failed to get type of column %v", c.RawName,
+			)
+		}
+		if x.Enum != nil {
+			typename = dbmlName(dataDictTitle(x.Enum.Names))
+		}
+		var settings []string
+		if c.PK && len(pks) == 1 {
+			settings = append(settings, "pk")
+		}
+		if x.Generated {
+			settings = append(settings, "increment")
+		}
+		if !nullable && !(c.PK && len(pks) == 1) {
+			settings = append(settings, "not null")
+		}
+		for _, ix := range x.Indexes {
+			if ix.Unique && len(ix.Columns) == 1 && ix.Name == defaultIndexName(ix) &&
+				ix.Where == "" && len(ix.Include) == 0 {
+				settings = append(settings, "unique")
+				break
+			}
+		}
+		if x.Default != "" {
+			settings = append(settings, "default: "+dbmlDefault(x.Default))
+		}
+		if c.Doc != "" {
+			settings = append(settings, "note: "+dbmlString(c.Doc))
+		}
+		buf.WriteString("  ")
+		buf.WriteString(dbmlName(dataDictTitle(c.Names)))
+		buf.WriteByte(' ')
+		buf.WriteString(typename)
+		if len(settings) > 0 {
+			buf.WriteString(" [")
+			buf.WriteString(strings.Join(settings, ", "))
+			buf.WriteByte(']')
+		}
+		buf.WriteByte('\n')
+	}
+	var indexes []string
+	if len(pks) > 1 {
+		names := make([]string, len(pks))
+		for i, c := range pks {
+			names[i] = dbmlName(dataDictTitle(c.Names))
+		}
+		indexes = append(indexes, "("+strings.Join(names, ", ")+") [pk]")
+	}
+	for _, ix := range TableExtOf(t).Indexes {
+		if ix.Unique && len(ix.Columns) == 1 && ix.Name == defaultIndexName(ix) &&
+			ix.Where == "" && len(ix.Include) == 0 {
+			// written as a column setting
+			continue
+		}
+		names := make([]string, len(ix.Columns))
+		for i, c := range ix.Columns {
+			names[i] = dbmlName(dataDictTitle(c.Names))
+		}
+		s := strings.Join(names, ", ")
+		if len(names) > 1 {
+			s = "(" + s + ")"
+		}
+		var settings []string
+		if ix.Unique {
+			settings = append(settings, "unique")
+		}
+		if ix.Name != defaultIndexName(ix) {
+			settings = append(settings, "name: "+dbmlString(ix.Name))
+		}
+		if len(settings) > 0 {
+			s += " [" + strings.Join(settings, ", ") + "]"
+		}
+		indexes = append(indexes, s)
+	}
+	if len(indexes) > 0 {
+		buf.WriteString("\n  indexes {\n")
+		for _, s := range indexes {
+			buf.WriteString("    ")
+			buf.WriteString(s)
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("  }\n")
+	}
+	if t.Doc != "" {
+		buf.WriteString("\n  Note: ")
+		buf.WriteString(dbmlString(t.Doc))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// dbmlWriteRef writes a foreign key as a many-to-one (or one-to-one)
+// relationship.  Its name is only written if it isn't the name that the
+// foreign key would get anyway.
+func dbmlWriteRef(buf *bytes.Buffer, fk *ForeignKey) {
+	buf.WriteString("Ref")
+	if fk.Name != defaultForeignKeyName(fk) {
+		buf.WriteByte(' ')
+		buf.WriteString(dbmlName(fk.Name))
+	}
+	buf.WriteString(": ")
+	buf.WriteString(dbmlColumnsName(fk.Table, fk.Columns))
+	if fk.OneToOne {
+		buf.WriteString(" - ")
+	} else {
+		buf.WriteString(" > ")
+	}
+	buf.WriteString(dbmlColumnsName(fk.RefTable, fk.RefColumns))
+	buf.WriteByte('\n')
+}
+
+func dbmlTableName(t *sqlstream.Table) string {
+	name := dbmlName(dataDictTitle(t.Names))
+	if t.Schema.SQLName != "" {
+		name = dbmlName(t.Schema.SQLName) + "." + name
+	}
+	return name
+}
+
+func dbmlColumnsName(t *sqlstream.Table, cs []*sqlstream.Column) string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = dbmlName(dataDictTitle(c.Names))
+	}
+	if len(names) == 1 {
+		return dbmlTableName(t) + "." + names[0]
+	}
+	return dbmlTableName(t) + ".(" + strings.Join(names, ", ") + ")"
+}
+
+// dbmlName double-quotes name if it isn't a DBML identifier.
+func dbmlName(name string) string {
+	for i, r := range name {
+		if !dbmlIsWordRune(r) || (i == 0 && unicode.IsDigit(r)) {
+			return strconv.Quote(name)
+		}
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+// dbmlString single-quotes a note or other string.  Multi-line strings
+// are triple-quoted.
+func dbmlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	if strings.Contains(s, "\n") {
+		return "'''" + strings.ReplaceAll(s, "'''", `\'''`) + "'''"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// dbmlDefault gets the DBML default value of a column's SQL default
+// value:  Numbers, booleans and null are written as-is, SQL strings are
+// DBML strings and everything else is a backtick-quoted expression.
+func dbmlDefault(s string) string {
+	switch lower := strings.ToLower(s); {
+	case lower == "true" || lower == "false" || lower == "null":
+		return lower
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return dbmlString(strings.ReplaceAll(s[1:len(s)-1], "''", "'"))
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "`" + s + "`"
+}
+
+// dbmlType gets the DBML type of a column's type.  DBML doesn't check
+// types, so these are the SQL types that the DBML generator (and
+// dbdiagram.io's SQL exports) understand.
+func dbmlType(t sqltypes.Type) (string, error) {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		return dbmlType(t[0])
+	case sqltypes.BoolType:
+		return "boolean", nil
+	case sqltypes.IntType:
+		switch {
+		case t.Bits <= 8:
+			return "tinyint", nil
+		case t.Bits <= 16:
+			return "smallint", nil
+		case t.Bits <= 32:
+			return "int", nil
+		}
+		return "bigint", nil
+	case sqltypes.FloatType:
+		if t.Mantissa <= 24 {
+			return "real", nil
+		}
+		return "float", nil
+	case sqltypes.DecimalType:
+		switch {
+		case t.Scale > 0:
+			return "decimal(" + strconv.Itoa(t.Prec) + "," + strconv.Itoa(t.Scale) + ")", nil
+		case t.Prec > 0:
+			return "decimal(" + strconv.Itoa(t.Prec) + ")", nil
+		}
+		return "decimal", nil
+	case sqltypes.StringType:
+		switch {
+		case t.Length <= 0:
+			return "text", nil
+		case t.Var:
+			return "varchar(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "char(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.BytesType:
+		switch {
+		case t.Length <= 0:
+			return "blob", nil
+		case t.Var:
+			return "varbinary(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "binary(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.TimeType:
+		if t.Prec >= 24*time.Hour {
+			return "date", nil
+		}
+		return "timestamp", nil
+	}
+	return "", errors.Errorf1(
+		"Unknown model type: %[1]v (type: %[1]T)",
+		t,
+	)
+}
+
+type dbmlConfigParser struct {
+	database string
+}
+
+func (dbmlConfigParser) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc dbmlConfigParser) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[dbmlDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+func (mc dbmlConfigParser) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := mc.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+func (mc dbmlConfigParser) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return cfg, errors.Errorf1From(
+			err, "failed to read all bytes from %v", r,
+		)
+	}
+	src := string(bs)
+	toks, err := tokenize(src, &dbmlSyntax)
+	if err != nil {
+		return cfg, err
+	}
+	p := &dbmlParser{tokenScanner: tokenScanner{src: src, toks: toks}}
+	if err = p.parse(); err != nil {
+		return cfg, err
+	}
+	return p.config(mc.database)
+}
+
+// dbmlSyntax is the lexical syntax of DBML.
+var dbmlSyntax = tokenSyntax{
+	lineComment: "//",
+	puncts: []string{
+		"<>", "{", "}", "[", "]", "(", ")", ":", ",", ".", "<", ">",
+		"-", "~",
+	},
+	isWordRune: func(r rune) bool {
+		return r >= 0x80 || r == '#' || dbmlIsWordRune(r)
+	},
+	scan: dbmlScan,
+}
+
+// dbmlScan scans the string, quoted name or expression at the start of src.
+func dbmlScan(src string) (kind tokenKind, text string, n int, err error) {
+	if strings.HasPrefix(src, "'''") {
+		end := strings.Index(src[3:], "'''")
+		for end > 0 && src[3+end-1] == '\\' {
+			next := strings.Index(src[3+end+1:], "'''")
+			if next == -1 {
+				end = -1
+				break
+			}
+			end += 1 + next
+		}
+		if end == -1 {
+			return 0, "", 0, errors.Errorf("unterminated string")
+		}
+		return stringToken, dbmlUnescape(dbmlDedent(src[3 : 3+end])), 3 + end + 3, nil
+	}
+	c := src[0]
+	switch c {
+	case '\'':
+		kind = stringToken
+	case '"':
+		kind = quotedToken
+	case '`':
+		kind = exprToken
+	default:
+		return 0, "", 0, nil
+	}
+	j := 1
+	for j < len(src) && src[j] != c {
+		if src[j] == '\\' {
+			j++
+		}
+		j++
+	}
+	if j >= len(src) {
+		return 0, "", 0, errors.Errorf("unterminated string")
+	}
+	text = src[1:j]
+	if kind != exprToken {
+		text = dbmlUnescape(text)
+	}
+	return kind, text, j + 1, nil
+}
+
+func dbmlIsWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func dbmlUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+				continue
+			case 't':
+				sb.WriteByte('\t')
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// dbmlDedent removes the indentation of a multi-line string's lines the
+// way that DBML does.
+func dbmlDedent(s string) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) < 2 {
+		return s
+	}
+	indent := -1
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == -1 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		switch {
+		case strings.TrimSpace(lines[i]) == "":
+			lines[i] = ""
+		case indent > 0:
+			lines[i] = lines[i][indent:]
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+type dbmlParser struct {
+	tokenScanner
+
+	project     string
+	projectNote string
+	tables      []*dbmlTable
+	enums       []*dbmlEnum
+	refs        []*dbmlRef
+}
+
+type dbmlTable struct {
+	schema, name, alias, note string
+	columns                   []*dbmlColumn
+	indexes                   []*dbmlIndex
+}
+
+type dbmlColumn struct {
+	name, typ, note, def      string
+	args                      []string
+	pk, notNull, unique, incr bool
+}
+
+type dbmlIndex struct {
+	name        string
+	columns     []string
+	pk, unique  bool
+	expressions bool
+}
+
+type dbmlEnum struct {
+	name, note string
+	members    []config.CommonData
+}
+
+type dbmlRef struct {
+	name     string
+	line     int
+	from, to dbmlEndpoint
+	op       string
+}
+
+type dbmlEndpoint struct {
+	schema, table string
+	columns       []string
+}
+
+// name parses a (possibly double-quoted) name.
+func (p *dbmlParser) name() (string, error) {
+	t := p.tok()
+	if t.Kind != wordToken && t.Kind != quotedToken {
+		return "", p.unexpected("a name")
+	}
+	p.next()
+	return t.Text, nil
+}
+
+// qualifiedName parses a name and the names before it separated by dots.
+func (p *dbmlParser) qualifiedName() (names []string, err error) {
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.isPunct(".") || p.peek(1).Kind == punctToken {
+			return names, nil
+		}
+		p.next()
+	}
+}
+
+// str parses a string literal.
+func (p *dbmlParser) str() (string, error) {
+	t := p.tok()
+	if t.Kind != stringToken {
+		return "", p.unexpected("a string")
+	}
+	p.next()
+	return t.Text, nil
+}
+
+func (p *dbmlParser) parse() error {
+	for p.tok().Kind != eofToken {
+		var err error
+		switch {
+		case p.isWord("Project"):
+			err = p.parseProject()
+		case p.isWord("Table"):
+			err = p.parseTable()
+		case p.isWord("Ref"):
+			err = p.parseRef()
+		case p.isWord("Enum"):
+			err = p.parseEnum()
+		case p.isWord("TableGroup"), p.isWord("Note"), p.isWord("TablePartial"), p.isWord("Records"):
+			// not part of the model
+			logger.Verbose2("skipping %v on line %d", p.tok().Text, p.tok().Line)
+			err = p.skipBlock()
+		default:
+			err = p.unexpected("Project, Table, Ref, Enum or TableGroup")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// note parses the rest of a Note after its keyword:  Either ": 'text'"
+// or "{ 'text' }".
+func (p *dbmlParser) note() (string, error) {
+	if p.acceptPunct(":") {
+		return p.str()
+	}
+	if err := p.expectPunct("{"); err != nil {
+		return "", err
+	}
+	s, err := p.str()
+	if err != nil {
+		return "", err
+	}
+	return s, p.expectPunct("}")
+}
+
+func (p *dbmlParser) parseProject() (err error) {
+	p.next()
+	if !p.isPunct("{") {
+		if p.project, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	for !p.acceptPunct("}") {
+		if p.isWord("Note") {
+			p.next()
+			if p.projectNote, err = p.note(); err != nil {
+				return err
+			}
+			continue
+		}
+		// e.g. database_type: 'PostgreSQL'
+		if _, err = p.name(); err != nil {
+			return err
+		}
+		if err = p.expectPunct(":"); err != nil {
+			return err
+		}
+		p.next()
+	}
+	return nil
+}
+
+// settings parses a bracketed list of settings into their tokens.
+func (p *dbmlParser) settings() (settings [][]scanToken, err error) {
+	if !p.acceptPunct("[") {
+		return nil, nil
+	}
+	var setting []scanToken
+	for depth := 0; ; p.next() {
+		t := p.tok()
+		switch {
+		case t.Kind == eofToken:
+			return nil, p.unexpected(`"]"`)
+		case depth == 0 && (p.isPunct(",") || p.isPunct("]")):
+			if len(setting) > 0 {
+				settings = append(settings, setting)
+			}
+			setting = nil
+			if p.isPunct("]") {
+				p.next()
+				return settings, nil
+			}
+			continue
+		case p.isPunct("("):
+			depth++
+		case p.isPunct(")"):
+			depth--
+		}
+		setting = append(setting, t)
+	}
+}
+
+// settingKey gets the lower-case words of a setting before its colon and
+// the tokens after it.
+func dbmlSettingKey(setting []scanToken) (key string, value []scanToken) {
+	words := make([]string, 0, 2)
+	for i, t := range setting {
+		if t.Kind == punctToken && t.Text == ":" {
+			return strings.Join(words, " "), setting[i+1:]
+		}
+		words = append(words, strings.ToLower(t.Text))
+	}
+	return strings.Join(words, " "), nil
+}
+
+func (p *dbmlParser) parseTable() error {
+	p.next()
+	names, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	t := &dbmlTable{name: names[len(names)-1]}
+	if len(names) > 1 {
+		t.schema = names[len(names)-2]
+	}
+	if p.isWord("as") {
+		p.next()
+		if t.alias, err = p.name(); err != nil {
+			return err
+		}
+	}
+	settings, err := p.settings()
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		if key, value := dbmlSettingKey(s); key == "note" && len(value) == 1 {
+			t.note = value[0].Text
+		}
+	}
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	for !p.acceptPunct("}") {
+		switch {
+		case p.isWord("Note") && (p.peek(1).Text == ":" || p.peek(1).Text == "{"):
+			p.next()
+			if t.note, err = p.note(); err != nil {
+				return err
+			}
+		case p.isWord("indexes") && p.peek(1).Text == "{":
+			p.next()
+			if err = p.parseIndexes(t); err != nil {
+				return err
+			}
+		default:
+			if err = p.parseColumn(t); err != nil {
+				return errors.Errorf1From(
+					err, "failed to parse column of table %v",
+					t.name,
+				)
+			}
+		}
+	}
+	p.tables = append(p.tables, t)
+	return nil
+}
+
+func (p *dbmlParser) parseColumn(t *dbmlTable) (err error) {
+	c := &dbmlColumn{}
+	if c.name, err = p.name(); err != nil {
+		return err
+	}
+	typeNames, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	c.typ = typeNames[len(typeNames)-1]
+	if p.acceptPunct("(") {
+		for !p.acceptPunct(")") {
+			if p.tok().Kind == eofToken {
+				return p.unexpected(`")"`)
+			}
+			if !p.isPunct(",") {
+				c.args = append(c.args, p.tok().Text)
+			}
+			p.next()
+		}
+	}
+	if p.isPunct("[") && p.peek(1).Text == "]" {
+		return errors.Errorf2(
+			"array column %v on line %d is not supported",
+			c.name, p.tok().Line,
+		)
+	}
+	settings, err := p.settings()
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		key, value := dbmlSettingKey(s)
+		switch key {
+		case "pk", "primary key":
+			c.pk = true
+		case "not null":
+			c.notNull = true
+		case "unique":
+			c.unique = true
+		case "increment":
+			c.incr = true
+		case "note":
+			if len(value) == 1 {
+				c.note = value[0].Text
+			}
+		case "default":
+			if len(value) > 0 {
+				c.def = dbmlSQLDefault(value)
+			}
+		case "ref":
+			ref, err := p.inlineRef(t, c, value)
+			if err != nil {
+				return err
+			}
+			p.refs = append(p.refs, ref)
+		}
+	}
+	t.columns = append(t.columns, c)
+	return nil
+}
+
+// dbmlSQLDefault gets the SQL default value of a column from the tokens of
+// its DBML default value.
+func dbmlSQLDefault(value []scanToken) string {
+	switch value[0].Kind {
+	case stringToken:
+		return "'" + strings.ReplaceAll(value[0].Text, "'", "''") + "'"
+	case exprToken:
+		return value[0].Text
+	}
+	var sb strings.Builder
+	for _, t := range value {
+		sb.WriteString(t.Text)
+	}
+	return sb.String()
+}
+
+// inlineRef creates a reference from a column's ref setting, e.g.
+// "ref: > users.id".
+func (p *dbmlParser) inlineRef(t *dbmlTable, c *dbmlColumn, value []scanToken) (*dbmlRef, error) {
+	if len(value) == 0 {
+		return nil, errors.Errorf1("invalid ref of column %v", c.name)
+	}
+	last := value[len(value)-1]
+	q := &dbmlParser{tokenScanner: tokenScanner{
+		src: p.src,
+		toks: append(value[1:len(value):len(value)], scanToken{
+			Kind: eofToken, Start: last.End, End: last.End, Line: last.Line,
+		}),
+	}}
+	ref := &dbmlRef{
+		line: value[0].Line,
+		op:   value[0].Text,
+		from: dbmlEndpoint{schema: t.schema, table: t.name, columns: []string{c.name}},
+	}
+	var err error
+	if ref.to, err = q.endpoint(); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// endpoint parses a reference's [schema.]table.column or
+// [schema.]table.(column, ...).
+func (p *dbmlParser) endpoint() (e dbmlEndpoint, err error) {
+	names, err := p.qualifiedName()
+	if err != nil {
+		return e, err
+	}
+	if p.isPunct(".") {
+		p.next()
+		if err = p.expectPunct("("); err != nil {
+			return e, err
+		}
+		for !p.acceptPunct(")") {
+			name, err := p.name()
+			if err != nil {
+				return e, err
+			}
+			e.columns = append(e.columns, name)
+			p.acceptPunct(",")
+		}
+		names = append(names, "")
+	} else {
+		if len(names) < 2 {
+			return e, p.unexpected("a table and column name")
+		}
+		e.columns = []string{names[len(names)-1]}
+	}
+	e.table = names[len(names)-2]
+	if len(names) > 2 {
+		e.schema = names[len(names)-3]
+	}
+	return e, nil
+}
+
+func (p *dbmlParser) parseRef() (err error) {
+	p.next()
+	ref := &dbmlRef{line: p.tok().Line}
+	if !p.isPunct(":") && !p.isPunct("{") {
+		if ref.name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	short := p.acceptPunct(":")
+	if !short {
+		if err = p.expectPunct("{"); err != nil {
+			return err
+		}
+	}
+	if ref.from, err = p.endpoint(); err != nil {
+		return err
+	}
+	switch t := p.tok(); t.Text {
+	case ">", "<", "-", "<>":
+		ref.op = t.Text
+		p.next()
+	default:
+		return p.unexpected(`">", "<", "-" or "<>"`)
+	}
+	if ref.to, err = p.endpoint(); err != nil {
+		return err
+	}
+	// delete and update actions
+	if _, err = p.settings(); err != nil {
+		return err
+	}
+	if !short {
+		if err = p.expectPunct("}"); err != nil {
+			return err
+		}
+	}
+	p.refs = append(p.refs, ref)
+	return nil
+}
+
+func (p *dbmlParser) parseIndexes(t *dbmlTable) error {
+	if err := p.expectPunct("{"); err != nil {
+		return err
+	}
+	for !p.acceptPunct("}") {
+		ix := &dbmlIndex{}
+		switch tok := p.tok(); {
+		case p.acceptPunct("("):
+			for !p.acceptPunct(")") {
+				switch tok := p.tok(); tok.Kind {
+				case exprToken:
+					ix.expressions = true
+					p.next()
+				case wordToken, quotedToken:
+					ix.columns = append(ix.columns, tok.Text)
+					p.next()
+				default:
+					return p.unexpected("an index column")
+				}
+				p.acceptPunct(",")
+			}
+		case tok.Kind == exprToken:
+			ix.expressions = true
+			p.next()
+		default:
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			ix.columns = []string{name}
+		}
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			switch key, value := dbmlSettingKey(s); key {
+			case "pk":
+				ix.pk = true
+			case "unique":
+				ix.unique = true
+			case "name":
+				if len(value) == 1 {
+					ix.name = value[0].Text
+				}
+			}
+		}
+		if ix.expressions {
+			logger.Warn2(
+				"skipping index of table %v with expressions on "+
+					"line %d",
+				t.name, p.tok().Line,
+			)
+			continue
+		}
+		t.indexes = append(t.indexes, ix)
+	}
+	return nil
+}
+
+func (p *dbmlParser) parseEnum() error {
+	p.next()
+	names, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	e := &dbmlEnum{name: names[len(names)-1]}
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	for !p.acceptPunct("}") {
+		if p.isWord("Note") && (p.peek(1).Text == ":" || p.peek(1).Text == "{") {
+			p.next()
+			if e.note, err = p.note(); err != nil {
+				return err
+			}
+			continue
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		m := config.CommonData{Names: config.Names{RawName: name}}
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if key, value := dbmlSettingKey(s); key == "note" && len(value) == 1 {
+				m.Doc = value[0].Text
+			}
+		}
+		e.members = append(e.members, m)
+	}
+	p.enums = append(p.enums, e)
+	return nil
+}
+
+// table finds a table by its name or alias.  Tables without a schema
+// match any schema.
+func (p *dbmlParser) table(schema, name string) *dbmlTable {
+	for _, t := range p.tables {
+		if (t.name == name || t.alias == name) && (schema == "" || t.schema == schema) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (t *dbmlTable) column(name string) *dbmlColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// config creates the model configuration from the parsed DBML.  Tables
+// without a schema are in a schema without a name.
+func (p *dbmlParser) config(database string) (cfg Config, err error) {
+	dbCfg := config.Database{CommonData: config.CommonData{Doc: p.projectNote}}
+	switch {
+	case database != "":
+		dbCfg.RawName = database
+	case p.project != "":
+		dbCfg.CommonData = sqlDDLCommonData(p.project)
+		dbCfg.Doc = p.projectNote
+	default:
+		dbCfg.RawName = "main"
+	}
+	dbRawName := dbCfg.RawName
+	enumTypes := make(map[string]*dbmlEnum, len(p.enums))
+	for _, e := range p.enums {
+		enumTypes[e.name] = e
+		enumCfg := EnumConfig{
+			CommonData: sqlDDLCommonData(e.name),
+			Type:       sqltypes.StringType{Var: true}.String(),
+		}
+		enumCfg.Doc = e.note
+		for _, m := range e.members {
+			enumCfg.Members = append(enumCfg.Members, EnumMemberConfig{CommonData: m})
+		}
+		x := cfg.Ext.findDatabase(dbRawName)
+		if x == nil {
+			cfg.Ext.Databases = append(cfg.Ext.Databases, DatabaseConfigExt{RawName: dbRawName})
+			x = &cfg.Ext.Databases[len(cfg.Ext.Databases)-1]
+		}
+		x.Enums = append(x.Enums, enumCfg)
+	}
+	schemas := make(map[string]int)
+	for _, t := range p.tables {
+		i, ok := schemas[t.schema]
+		if !ok {
+			i = len(dbCfg.Schemas)
+			schemas[t.schema] = i
+			dbCfg.Schemas = append(dbCfg.Schemas, config.Schema{
+				CommonData: sqlDDLCommonData(t.schema),
+			})
+		}
+		schRawName := dbCfg.Schemas[i].RawName
+		tblCfg := config.Table{
+			CommonData: sqlDDLCommonData(t.name),
+			Columns:    make([]config.Column, len(t.columns)),
+		}
+		tblCfg.Doc = t.note
+		for _, ix := range t.indexes {
+			for _, name := range ix.columns {
+				c := t.column(name)
+				if c == nil {
+					return cfg, errors.Errorf2(
+						"index of table %v has undefined "+
+							"column %q",
+						t.name, name,
+					)
+				}
+				c.pk = c.pk || ix.pk
+			}
+		}
+		for j, c := range t.columns {
+			colCfg := &tblCfg.Columns[j]
+			colCfg.CommonData = sqlDDLCommonData(c.name)
+			colCfg.Doc = c.note
+			colCfg.PK = c.pk
+			var typ sqltypes.Type
+			generated := false
+			if e, ok := enumTypes[c.typ]; ok {
+				typ = sqltypes.StringType{Var: true}
+				cfg.Ext.Column(dbRawName, schRawName, tblCfg.RawName, colCfg.RawName).Enum = identifierRawName(e.name)
+			} else {
+				name := strings.ToLower(c.typ)
+				for _, suffix := range []string{" with time zone", " without time zone"} {
+					name = strings.TrimSuffix(name, suffix)
+				}
+				if typ, generated, err = sqlDDLType("postgres", name, c.args); err != nil {
+					return cfg, errors.Errorf2From(
+						err, "invalid type of %v.%v",
+						t.name, c.name,
+					)
+				}
+			}
+			if !c.notNull && !c.pk {
+				typ = sqltypes.Nullable{typ}
+			}
+			colCfg.Type = typ.String()
+			if c.incr || generated || c.def != "" {
+				x := cfg.Ext.Column(dbRawName, schRawName, tblCfg.RawName, colCfg.RawName)
+				x.Generated = c.incr || generated
+				x.Default = c.def
+			}
+			if c.unique {
+				x := cfg.Ext.Table(dbRawName, schRawName, tblCfg.RawName)
+				x.Indexes = append(x.Indexes, IndexConfig{
+					Unique:  true,
+					Columns: []IndexColumnConfig{{RawName: colCfg.RawName}},
+				})
+			}
+		}
+		for _, ix := range t.indexes {
+			if ix.pk {
+				continue
+			}
+			ixCfg := IndexConfig{Name: ix.name, Unique: ix.unique}
+			for _, name := range ix.columns {
+				ixCfg.Columns = append(ixCfg.Columns, IndexColumnConfig{RawName: identifierRawName(name)})
+			}
+			x := cfg.Ext.Table(dbRawName, schRawName, tblCfg.RawName)
+			x.Indexes = append(x.Indexes, ixCfg)
+		}
+		dbCfg.Schemas[i].Tables = append(dbCfg.Schemas[i].Tables, tblCfg)
+	}
+	cfg.Databases = []config.Database{dbCfg}
+	for _, ref := range p.refs {
+		if err = p.addRef(&cfg, ref); err != nil {
+			return cfg, errors.Errorf1From(
+				err, "invalid Ref on line %d", ref.line,
+			)
+		}
+	}
+	return
+}
+
+// addRef adds a reference as a column FK if it's an unnamed reference
+// from one column to a primary key or else as a ForeignKeyConfig.
+func (p *dbmlParser) addRef(cfg *Config, ref *dbmlRef) error {
+	from, to := ref.from, ref.to
+	switch ref.op {
+	case "<":
+		from, to = to, from
+	case "<>":
+		return errors.Errorf2(
+			"%v and %v are many-to-many.  Please add a table "+
+				"between them.",
+			ref.from.table, ref.to.table,
+		)
+	}
+	t, refTbl := p.table(from.schema, from.table), p.table(to.schema, to.table)
+	if t == nil {
+		return errors.Errorf1("undefined table %q", from.table)
+	}
+	if refTbl == nil {
+		return errors.Errorf1("undefined table %q", to.table)
+	}
+	if len(from.columns) != len(to.columns) {
+		return errors.Errorf2(
+			"%d columns reference %d columns",
+			len(from.columns), len(to.columns),
+		)
+	}
+	var pks []*dbmlColumn
+	for _, c := range refTbl.columns {
+		if c.pk {
+			pks = append(pks, c)
+		}
+	}
+	refsPK := len(pks) == len(to.columns)
+	for i, name := range to.columns {
+		if refTbl.column(name) == nil {
+			return errors.Errorf2("undefined column %v.%v", to.table, name)
+		}
+		refsPK = refsPK && pks[i].name == name
+	}
+	for _, name := range from.columns {
+		if t.column(name) == nil {
+			return errors.Errorf2("undefined column %v.%v", from.table, name)
+		}
+	}
+	dbRawName := cfg.Databases[0].RawName
+	schRawName := identifierRawName(t.schema)
+	tblRawName := identifierRawName(t.name)
+	refPath := identifierRawName(refTbl.name)
+	if refTbl.schema != t.schema {
+		refPath = identifierRawName(refTbl.schema) + "." + refPath
+	}
+	if ref.name == "" && len(from.columns) == 1 && refsPK {
+		colRawName := identifierRawName(from.columns[0])
+		col := drawIOColumn(&cfg.Config, drawIOColumnPath{dbRawName, schRawName, tblRawName, colRawName})
+		col.FK = refPath + "." + identifierRawName(to.columns[0])
+		if ref.op == "-" {
+			cfg.Ext.Column(dbRawName, schRawName, tblRawName, colRawName).OneToOne = true
+		}
+		return nil
+	}
+	fkCfg := ForeignKeyConfig{
+		Name:       ref.name,
+		References: refPath,
+		OneToOne:   ref.op == "-",
+	}
+	for _, name := range from.columns {
+		fkCfg.Columns = append(fkCfg.Columns, identifierRawName(name))
+	}
+	if !refsPK {
+		for _, name := range to.columns {
+			fkCfg.ReferencedColumns = append(fkCfg.ReferencedColumns, identifierRawName(name))
+		}
+	}
+	x := cfg.Ext.Table(dbRawName, schRawName, tblRawName)
+	x.ForeignKeys = append(x.ForeignKeys, fkCfg)
+	return nil
+}
@@ -0,0 +1,111 @@
+package sqlmodelgen
+
+import "testing"
+
+func TestDBMLParse(t *testing.T) {
+	testParseCases(t, DBMLConfigParserModelContext, []testParseCase{{
+		name: "tables and refs",
+		src: `Project shop {
+  database_type: 'PostgreSQL'
+  Note: '''
+    # Shop
+    Customers and their orders.
+  '''
+}
+
+// Customers are people.
+Table sales.customer as C {
+  id int [pk, increment]
+  name varchar(64) [not null, note: 'The customer\'s name']
+  email varchar(128) [unique]
+}
+
+Table sales.order {
+  id bigint [pk]
+  customer_id int [not null, ref: > sales.customer.id]
+  placed timestamp [default: ` + "`now()`" + `]
+
+  Note {
+    'Orders are placed by customers.'
+  }
+}
+
+Table sales."order line" {
+  order_id bigint [not null]
+  line_number smallint [not null]
+  price decimal(10, 2)
+
+  indexes {
+    (order_id, line_number) [pk]
+    price [name: 'IX_price']
+  }
+}
+
+Ref: sales."order line".order_id > sales.order.id
+
+TableGroup sales {
+  sales.customer
+}
+`,
+		want: `customer
+	id int32 pk
+	name varchar(64)
+	email nullable(varchar(128))
+	unique UX_customer_email (email)
+order
+	id int64 pk
+	customer_id int32
+	placed nullable(time(0s))
+	fk (customer_id) customer (id)
+order line
+	order_id int64 pk
+	line_number int16 pk
+	price nullable(decimal(10, 2))
+	fk (order_id) order (id)
+	index IX_price (price)
+`,
+	}, {
+		name: "composite ref",
+		src: `Table a {
+  x int
+  y int
+  indexes {
+    (x, y) [pk]
+  }
+}
+
+Table b {
+  id int [pk]
+  ax int
+  ay int
+}
+
+Ref fk_b_a: b.(ax, ay) > a.(x, y)
+`,
+		want: `a
+	x int32 pk
+	y int32 pk
+b
+	id int32 pk
+	ax nullable(int32)
+	ay nullable(int32)
+	fk (ax, ay) a (x, y)
+`,
+	}, {
+		name: "unterminated string",
+		src:  "Table t {\n  c int [note: 'oops]\n}\n",
+		err:  "line 2: unterminated string",
+	}, {
+		name: "unexpected character",
+		src:  "Table t {\n  c int ;\n}\n",
+		err:  `line 2: unexpected character ';'`,
+	}, {
+		name: "unknown block",
+		src:  "Tabel t {\n}\n",
+		err:  `line 1: expected Project, Table, Ref, Enum or TableGroup but got "Tabel"`,
+	}, {
+		name: "unclosed table",
+		src:  "Table t {\n  c int\n",
+		err:  "but reached the end",
+	}})
+}
@@ -0,0 +1,358 @@
+package sqlmodelgen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// sqlDefaultKind classifies a column's default value.
+type sqlDefaultKind int
+
+const (
+	// sqlDefaultExpr is any SQL expression that isn't recognized.  It is
+	// emitted as-is.
+	sqlDefaultExpr sqlDefaultKind = iota
+	sqlDefaultNull
+	sqlDefaultString
+	sqlDefaultNumber
+	sqlDefaultBool
+
+	// sqlDefaultNow is the current date and time (e.g. GETDATE(),
+	// CURRENT_TIMESTAMP, NOW(), etc.)
+	sqlDefaultNow
+
+	// sqlDefaultUUID is a new random UUID (e.g. NEWID(), UUID(), etc.)
+	sqlDefaultUUID
+)
+
+// sqlDefault is a column's default value, parsed just enough to be
+// translated into other SQL dialects and programming languages.
+type sqlDefault struct {
+	Kind sqlDefaultKind
+
+	// Value is the unquoted value of a string, the digits of a number,
+	// "true" or "false" for a bool, or the expression itself for
+	// everything else.
+	Value string
+}
+
+var sqlDefaultNumberPattern = regexp.MustCompile(
+	`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`,
+)
+
+// parseSQLDefault parses a column's default value.  Redundant parentheses
+// (e.g. SQL Server's "((0))") are removed first.
+func parseSQLDefault(s string) sqlDefault {
+	s = strings.TrimSpace(s)
+	for len(s) > 1 && s[0] == '(' && s[len(s)-1] == ')' && balancedParens(s[1:len(s)-1]) {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if len(s) > 2 && (s[0] == 'N' || s[0] == 'n') && s[1] == '\'' {
+		s = s[1:]
+	}
+	if len(s) > 1 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		inner := s[1 : len(s)-1]
+		if !strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			return sqlDefault{sqlDefaultString, strings.ReplaceAll(inner, "''", "'")}
+		}
+	}
+	if sqlDefaultNumberPattern.MatchString(s) {
+		return sqlDefault{sqlDefaultNumber, s}
+	}
+	switch strings.ToUpper(s) {
+	case "NULL":
+		return sqlDefault{sqlDefaultNull, s}
+	case "TRUE", "FALSE":
+		return sqlDefault{sqlDefaultBool, strings.ToLower(s)}
+	case "GETDATE()", "SYSDATETIME()", "CURRENT_TIMESTAMP",
+		"CURRENT_TIMESTAMP()", "NOW()", "LOCALTIMESTAMP",
+		"DATETIME('NOW')":
+		return sqlDefault{sqlDefaultNow, s}
+	case "NEWID()", "UUID()", "GEN_RANDOM_UUID()":
+		return sqlDefault{sqlDefaultUUID, s}
+	}
+	return sqlDefault{sqlDefaultExpr, s}
+}
+
+// balancedParens checks that the parentheses in s are balanced so that
+// "(a) + (b)" isn't mistaken for a parenthesized expression.
+func balancedParens(s string) bool {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// sqlDefaultExpression translates a column's default value into the SQL
+// dialect with the given name.  It returns a blank string if the column has
+// no default value.
+func sqlDefaultExpression(dialectName string, c *sqlstream.Column) string {
+	s := ColumnExtOf(c).Default
+	if s == "" {
+		return ""
+	}
+	d := parseSQLDefault(s)
+	switch d.Kind {
+	case sqlDefaultNull, sqlDefaultNumber:
+		return d.Value
+	case sqlDefaultString:
+		return "'" + strings.ReplaceAll(d.Value, "'", "''") + "'"
+	case sqlDefaultBool:
+		switch dialectName {
+		case "mssql", "sqlite3":
+			if d.Value == "true" {
+				return "1"
+			}
+			return "0"
+		}
+		return strings.ToUpper(d.Value)
+	case sqlDefaultNow:
+		if dialectName == "mssql" {
+			return "GETDATE()"
+		}
+		return "CURRENT_TIMESTAMP"
+	case sqlDefaultUUID:
+		switch dialectName {
+		case "mssql":
+			return "NEWID()"
+		case "postgres":
+			return "gen_random_uuid()"
+		case "mysql":
+			return "(UUID())"
+		case "sqlite3":
+			return "(lower(hex(randomblob(16))))"
+		}
+	}
+	return "(" + d.Value + ")"
+}
+
+// goDefaultValue translates a column's default value into a Go expression
+// of the given Go type.  It returns a blank string if the column has no
+// default value or it can't be expressed in Go.
+func goDefaultValue(c *sqlstream.Column, typename string) string {
+	s := ColumnExtOf(c).Default
+	if s == "" {
+		return ""
+	}
+	if m := enumMemberOfDefault(c); m != nil {
+		return m.Enum.ModelName + m.ModelName
+	}
+	d := parseSQLDefault(s)
+	var v, valid string
+	switch typename {
+	case "sql.NullBool":
+		typename, valid = "bool", "Bool"
+	case "sql.NullFloat64":
+		typename, valid = "float64", "Float64"
+	case "sql.NullInt32":
+		typename, valid = "int32", "Int32"
+	case "sql.NullInt64":
+		typename, valid = "int64", "Int64"
+	case "sql.NullString":
+		typename, valid = "string", "String"
+	case "sql.NullTime":
+		typename, valid = "time.Time", "Time"
+	}
+	switch d.Kind {
+	case sqlDefaultString:
+		if typename == "string" {
+			v = strconv.Quote(d.Value)
+		}
+	case sqlDefaultNumber:
+		switch typename {
+		case "int8", "int16", "int32", "int64":
+			if _, err := strconv.ParseInt(d.Value, 10, 64); err == nil {
+				v = d.Value
+			}
+		case "float32", "float64":
+			v = d.Value
+		}
+	case sqlDefaultBool:
+		if typename == "bool" {
+			v = d.Value
+		}
+	case sqlDefaultNow:
+		if typename == "time.Time" {
+			v = "time.Now()"
+		}
+	}
+	if v == "" || valid == "" {
+		return v
+	}
+	return "sql.Null" + valid + "{" + valid + ": " + v + ", Valid: true}"
+}
+
+// csDefaultValue translates a column's default value into a C# expression
+// of the given C# type.  It returns a blank string if the column has no
+// default value or it can't be expressed in C#.
+func csDefaultValue(c *sqlstream.Column, typename string) string {
+	s := ColumnExtOf(c).Default
+	if s == "" {
+		return ""
+	}
+	if m := enumMemberOfDefault(c); m != nil {
+		return m.Enum.ModelName + "." + m.ModelName
+	}
+	if ColumnExtOf(c).Enum != nil {
+		return ""
+	}
+	d := parseSQLDefault(s)
+	typename = strings.TrimSuffix(typename, "?")
+	switch d.Kind {
+	case sqlDefaultString:
+		if typename == "string" {
+			return strconv.Quote(d.Value)
+		}
+	case sqlDefaultNumber:
+		switch typename {
+		case "byte", "short", "int", "long":
+			if _, err := strconv.ParseInt(d.Value, 10, 64); err == nil {
+				return d.Value
+			}
+		case "float":
+			return d.Value + "f"
+		case "double":
+			return d.Value + "d"
+		case "decimal":
+			return d.Value + "m"
+		}
+	case sqlDefaultBool:
+		if typename == "bool" {
+			return d.Value
+		}
+	case sqlDefaultNow:
+		if typename == "DateTime" {
+			return "DateTime.Now"
+		}
+	}
+	return ""
+}
+
+// wvAceDefaultValue gets a column's default value as a WorkView ACE
+// "Default Value."  Only literals can be expressed in ACE.
+func wvAceDefaultValue(c *sqlstream.Column) string {
+	s := ColumnExtOf(c).Default
+	if s == "" {
+		return ""
+	}
+	switch d := parseSQLDefault(s); d.Kind {
+	case sqlDefaultString, sqlDefaultNumber, sqlDefaultBool:
+		return d.Value
+	}
+	return ""
+}
+
+// wvAceParseDefaultValue is the inverse of wvAceDefaultValue:  It makes a
+// SQL literal from the Default Value of an attribute of type t.
+func wvAceParseDefaultValue(v string, t sqltypes.Type) string {
+	switch t.(type) {
+	case sqltypes.BoolType:
+		switch strings.ToLower(v) {
+		case "true", "yes", "1":
+			return "TRUE"
+		case "false", "no", "0":
+			return "FALSE"
+		}
+	case sqltypes.IntType, sqltypes.FloatType, sqltypes.DecimalType:
+		if sqlDefaultNumberPattern.MatchString(v) {
+			return v
+		}
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// modelTypeDefaultFunc creates a template function that translates a
+// column's default value into the ModelContext's language with f.
+func modelTypeDefaultFunc(mc ModelContext, f func(c *sqlstream.Column, typename string) string) func(c *sqlstream.Column) (string, error) {
+	return func(c *sqlstream.Column) (string, error) {
+		_, typename, err := mc.ModelType(c.Type)
+		if err != nil {
+			return "", errors.Errorf2From(
+				err, "failed to get model type of column %v.%v",
+				c.Table.RawName, c.RawName,
+			)
+		}
+		return f(c, typename), nil
+	}
+}
+
+// fieldDefault is the default value of a field of a generated model.
+type fieldDefault struct {
+	Field string
+	Value string
+}
+
+// goDefaultsFunc creates a template function that gets the Go default
+// values of the fields of a table's model.  Only columns that are held
+// directly in the model's fields (i.e. not in its ID, key or foreign keys)
+// are initialized.
+func goDefaultsFunc(mc ModelContext) func(t *sqlstream.Table) ([]fieldDefault, error) {
+	f := modelTypeDefaultFunc(mc, goDefaultValue)
+	return func(t *sqlstream.Table) (fds []fieldDefault, err error) {
+	columnLoop:
+		for _, c := range t.Columns {
+			if c.PK || c.FK != nil {
+				continue
+			}
+			if fk := ColumnExtOf(c).ForeignKey; fk != nil && fk.RefKey != nil {
+				continue
+			}
+			if t.Key != nil {
+				for _, id := range t.Key.IDs {
+					if id.Column == c {
+						continue columnLoop
+					}
+				}
+			}
+			v, err := f(c)
+			if err != nil {
+				return nil, err
+			}
+			if v != "" {
+				fds = append(fds, fieldDefault{Field: c.ModelName, Value: v})
+			}
+		}
+		return
+	}
+}
+
+// goDefaultsNeedTime checks if any of the MetaModel's nullable date and time
+// columns default to the current time.  Their defaults are initialized with
+// time.Now(), but their sql.NullTime types don't cause the "time" package to
+// be imported.
+func goDefaultsNeedTime(mm *sqlstream.MetaModel) bool {
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, tbl := range sch.Tables {
+				for _, c := range tbl.Columns {
+					n, ok := c.Type.(sqltypes.Nullable)
+					if !ok {
+						continue
+					}
+					if _, ok = n[0].(sqltypes.TimeType); !ok {
+						continue
+					}
+					s := ColumnExtOf(c).Default
+					if s != "" && parseSQLDefault(s).Kind == sqlDefaultNow {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
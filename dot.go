@@ -0,0 +1,382 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// dotSchemasParam is the name of the parameter that holds a
+	// comma-separated list of the schemas to write.  Each schema is
+	// either a raw schema name or a "database.schema" raw name.
+	dotSchemasParam = "schemas"
+
+	// dotTableParam is the name of the parameter that holds the raw
+	// name of the table (optionally qualified with its schema and
+	// database raw names) to center the graph on.
+	dotTableParam = "table"
+
+	// dotHopsParam is the name of the parameter that holds how many
+	// foreign keys away from the dotTableParam table tables can be to
+	// be written.
+	dotHopsParam = "hops"
+
+	dotPKIcon = "&#128273;"
+	dotFKIcon = "&#128279;"
+)
+
+var (
+	// DotModelContext writes models as a Graphviz DOT digraph.
+	DotModelContext interface {
+		ModelContext
+		MetaModelWriter
+		ParameterizedModelContext
+	} = dotModelContext{hops: 1}
+)
+
+type dotModelContext struct {
+	schemas []string
+	table   string
+	hops    int
+}
+
+func (dotModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	typename, err = mermaidType(t)
+	return
+}
+
+func (mc dotModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[dotSchemasParam]; s != "" {
+		mc.schemas = nil
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				mc.schemas = append(mc.schemas, name)
+			}
+		}
+	}
+	if s := ps[dotTableParam]; s != "" {
+		mc.table = s
+	}
+	if s := ps[dotHopsParam]; s != "" {
+		if mc.table == "" {
+			return nil, errors.Errorf2(
+				"%v parameter requires the %v parameter",
+				dotHopsParam, dotTableParam,
+			)
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, errors.Errorf2(
+				"%v must be a non-negative integer, not %q",
+				dotHopsParam, s,
+			)
+		}
+		mc.hops = n
+	}
+	return mc, nil
+}
+
+func (mc dotModelContext) WriteMetaModel(w io.Writer, mm *sqlstream.MetaModel) error {
+	include, err := mc.includedTables(mm)
+	if err != nil {
+		return err
+	}
+	ids := make(map[*sqlstream.Table]string, len(include))
+	var buf bytes.Buffer
+	buf.WriteString("digraph models {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=plain, fontname=\"Helvetica\"];\n")
+	buf.WriteString("\tedge [fontname=\"Helvetica\"];\n")
+	for _, db := range mm.Databases {
+		for i, sch := range db.Schemas {
+			var tables []*sqlstream.Table
+			for _, t := range sch.Tables {
+				if include[t] {
+					tables = append(tables, t)
+				}
+			}
+			if len(tables) == 0 {
+				continue
+			}
+			buf.WriteString("\tsubgraph ")
+			buf.WriteString(dotQuote("cluster_" + db.RawName + "_" + strconv.Itoa(i)))
+			buf.WriteString(" {\n\t\tlabel=")
+			buf.WriteString(dotQuote(dotSchemaLabel(sch)))
+			buf.WriteString(";\n")
+			if sch.Doc != "" {
+				buf.WriteString("\t\ttooltip=")
+				buf.WriteString(dotQuote(sch.Doc))
+				buf.WriteString(";\n")
+			}
+			for _, t := range tables {
+				ids[t] = strings.Join([]string{db.RawName, sch.RawName, t.RawName}, ".")
+				if err := dotWriteTable(&buf, t, ids[t]); err != nil {
+					return errors.Errorf1From(
+						err, "failed to write table %v",
+						t.RawName,
+					)
+				}
+			}
+			buf.WriteString("\t}\n")
+		}
+	}
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				if !include[t] {
+					continue
+				}
+				for _, fk := range TableExtOf(t).ForeignKeys {
+					if !include[fk.RefTable] {
+						continue
+					}
+					dotWriteForeignKey(&buf, fk, ids)
+				}
+			}
+		}
+	}
+	buf.WriteString("}\n")
+	if _, err := buf.WriteTo(w); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write DOT graph to %v", w,
+		)
+	}
+	return nil
+}
+
+// includedTables gets the tables in the selected schemas that are within
+// mc.hops foreign keys (in either direction) of mc.table.
+func (mc dotModelContext) includedTables(mm *sqlstream.MetaModel) (map[*sqlstream.Table]bool, error) {
+	include := make(map[*sqlstream.Table]bool)
+	var center *sqlstream.Table
+	for _, name := range mc.schemas {
+		found := false
+		for _, db := range mm.Databases {
+			for _, sch := range db.Schemas {
+				if dotNameMatches(name, db.RawName, sch.RawName) {
+					found = true
+					for _, t := range sch.Tables {
+						include[t] = true
+					}
+				}
+			}
+		}
+		if !found {
+			return nil, errors.Errorf1("schema %q not found", name)
+		}
+	}
+	all := len(mc.schemas) == 0
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				if all {
+					include[t] = true
+				}
+				if mc.table != "" && dotNameMatches(mc.table, db.RawName, sch.RawName, t.RawName) {
+					if center != nil {
+						return nil, errors.Errorf1(
+							"table %q is ambiguous.  "+
+								"Qualify it with its "+
+								"schema name.",
+							mc.table,
+						)
+					}
+					center = t
+				}
+			}
+		}
+	}
+	if mc.table == "" {
+		return include, nil
+	}
+	if center == nil || !include[center] {
+		return nil, errors.Errorf1("table %q not found", mc.table)
+	}
+	// Tables are connected by their foreign keys and the foreign
+	// keys that reference them.
+	neighbors := make(map[*sqlstream.Table][]*sqlstream.Table)
+	for t := range include {
+		for _, fk := range TableExtOf(t).ForeignKeys {
+			if include[fk.RefTable] {
+				neighbors[t] = append(neighbors[t], fk.RefTable)
+				neighbors[fk.RefTable] = append(neighbors[fk.RefTable], t)
+			}
+		}
+	}
+	near := map[*sqlstream.Table]bool{center: true}
+	hop := []*sqlstream.Table{center}
+	for i := 0; i < mc.hops && len(hop) > 0; i++ {
+		var next []*sqlstream.Table
+		for _, t := range hop {
+			for _, n := range neighbors[t] {
+				if !near[n] {
+					near[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		hop = next
+	}
+	return near, nil
+}
+
+// dotNameMatches checks if name matches the trailing raw names of
+// qualified names.  For example, "sales.customer" matches
+// ("shop", "sales", "customer").
+func dotNameMatches(name string, rawNames ...string) bool {
+	parts := strings.Split(name, ".")
+	if len(parts) > len(rawNames) {
+		return false
+	}
+	rawNames = rawNames[len(rawNames)-len(parts):]
+	for i, part := range parts {
+		if part != rawNames[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func dotSchemaLabel(sch *sqlstream.Schema) string {
+	name := sch.SQLName
+	if name == "" {
+		name = sch.RawName
+	}
+	if len(sch.Database.MetaModel.Databases) > 1 {
+		name = sch.Database.RawName + "." + name
+	}
+	return name
+}
+
+// dotWriteTable writes a table as a node with an HTML table label.  Each
+// column is a row whose port is its index so that foreign keys can be
+// drawn from and to the columns.
+func dotWriteTable(buf *bytes.Buffer, t *sqlstream.Table, id string) error {
+	buf.WriteString("\t\t")
+	buf.WriteString(dotQuote(id))
+	buf.WriteString(" [")
+	if t.Doc != "" {
+		buf.WriteString("tooltip=")
+		buf.WriteString(dotQuote(t.Doc))
+		buf.WriteString(", ")
+	}
+	buf.WriteString("label=<\n")
+	buf.WriteString("\t\t\t<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\" CELLPADDING=\"4\">\n")
+	buf.WriteString("\t\t\t<TR><TD COLSPAN=\"3\" BGCOLOR=\"lightgrey\"><B>")
+	buf.WriteString(html.EscapeString(dotName(t.Names)))
+	buf.WriteString("</B></TD></TR>\n")
+	for i, c := range t.Columns {
+		typ := c.Type
+		nullable := false
+		if n, ok := typ.(sqltypes.Nullable); ok {
+			typ, nullable = n[0], true
+		}
+		typename, err := mermaidType(typ)
+		if err != nil {
+			return errors.Errorf1From(
+				err, "failed to get type of column %v", c.RawName,
+			)
+		}
+		if nullable {
+			typename += " NULL"
+		}
+		icons := ""
+		if c.PK {
+			icons += dotPKIcon
+		}
+		if c.FK != nil || ColumnExtOf(c).ForeignKey != nil {
+			icons += dotFKIcon
+		}
+		buf.WriteString("\t\t\t<TR><TD>")
+		buf.WriteString(icons)
+		buf.WriteString("</TD><TD ALIGN=\"LEFT\" PORT=\"c")
+		buf.WriteString(strconv.Itoa(i))
+		buf.WriteString("\">")
+		name := html.EscapeString(dotName(c.Names))
+		if c.PK {
+			name = "<U>" + name + "</U>"
+		}
+		buf.WriteString(name)
+		buf.WriteString("</TD><TD ALIGN=\"LEFT\">")
+		buf.WriteString(html.EscapeString(typename))
+		buf.WriteString("</TD></TR>\n")
+	}
+	buf.WriteString("\t\t\t</TABLE>\n\t\t>];\n")
+	return nil
+}
+
+// dotWriteForeignKey writes an edge from each of a foreign key's columns to
+// the column that it references.  Edges of nullable foreign keys are
+// dashed.
+func dotWriteForeignKey(buf *bytes.Buffer, fk *ForeignKey, ids map[*sqlstream.Table]string) {
+	for i, c := range fk.Columns {
+		ref := fk.RefColumns[i]
+		buf.WriteString("\t")
+		buf.WriteString(dotQuote(ids[fk.Table]))
+		buf.WriteString(":c")
+		buf.WriteString(strconv.Itoa(dotColumnIndex(c)))
+		buf.WriteString(" -> ")
+		buf.WriteString(dotQuote(ids[fk.RefTable]))
+		buf.WriteString(":c")
+		buf.WriteString(strconv.Itoa(dotColumnIndex(ref)))
+		attrs := make([]string, 0, 2)
+		if _, ok := c.Type.(sqltypes.Nullable); ok {
+			attrs = append(attrs, "style=dashed")
+		}
+		if fk.OneToOne {
+			attrs = append(attrs, "arrowtail=tee", "dir=both")
+		}
+		if len(attrs) > 0 {
+			buf.WriteString(" [")
+			buf.WriteString(strings.Join(attrs, ", "))
+			buf.WriteByte(']')
+		}
+		buf.WriteString(";\n")
+	}
+}
+
+func dotColumnIndex(c *sqlstream.Column) int {
+	for i, tc := range c.Table.Columns {
+		if tc == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// dotName gets the name to show:  The SQL name if there is one or else the
+// raw name.
+func dotName(ns sqlstream.Names) string {
+	if ns.SQLName != "" {
+		return ns.SQLName
+	}
+	return ns.RawName
+}
+
+// dotQuote quotes s as a DOT ID.
+func dotQuote(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			sb.WriteByte('\\')
+		case '\n':
+			sb.WriteString(`\n`)
+			continue
+		case '\r':
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,129 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDotWrite(t *testing.T) {
+	mm := testMetaModel(t, `{
+	"databases": [{
+		"rawName": "shop",
+		"schemas": [{
+			"rawName": "sales",
+			"tables": [{
+				"rawName": "customer",
+				"doc": "someone who buys \"things\"\nfrom us",
+				"columns": [
+					{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+					{"rawName": "a<b", "sqlName": "A<B", "type": "bool"}
+				]
+			}, {
+				"rawName": "order",
+				"columns": [
+					{"rawName": "order id", "type": "int(bits: 64)", "pk": true},
+					{"rawName": "customer id", "type": "nullable(int(bits: 32))", "fk": "customer.customer id"}
+				]
+			}, {
+				"rawName": "invoice",
+				"columns": [
+					{"rawName": "order id", "type": "int(bits: 64)", "pk": true, "fk": "order.order id", "oneToOne": true}
+				]
+			}]
+		}]
+	}]
+}`)
+	src := testWrite(t, DotModelContext, mm)
+	for _, want := range []string{
+		"\tsubgraph \"cluster_shop_0\" {\n\t\tlabel=\"Sales\";\n",
+		"\t\t\"shop.sales.customer\" [tooltip=\"someone who buys \\\"things\\\"\\nfrom us\", label=<\n",
+		"<TR><TD>&#128273;</TD><TD ALIGN=\"LEFT\" PORT=\"c0\"><U>CustomerId</U></TD><TD ALIGN=\"LEFT\">int32</TD></TR>",
+		"<TD ALIGN=\"LEFT\" PORT=\"c1\">A&lt;B</TD>",
+		"<TR><TD>&#128279;</TD><TD ALIGN=\"LEFT\" PORT=\"c1\">CustomerId</TD><TD ALIGN=\"LEFT\">int32 NULL</TD></TR>",
+		"\t\"shop.sales.order\":c1 -> \"shop.sales.customer\":c0 [style=dashed];\n",
+		"\t\"shop.sales.invoice\":c0 -> \"shop.sales.order\":c0 [arrowtail=tee, dir=both];\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("missing %q in:\n%v", want, src)
+		}
+	}
+}
+
+func TestDotTables(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		params map[string]string
+		want   []string
+		err    string
+	}{{
+		name: "all",
+		want: []string{"customer", "order", "order line", "shipment"},
+	}, {
+		name:   "schema",
+		params: map[string]string{"schemas": "shop.sales"},
+		want:   []string{"customer", "order", "order line", "shipment"},
+	}, {
+		name:   "no hops",
+		params: map[string]string{"table": "sales.order line", "hops": "0"},
+		want:   []string{"order line"},
+	}, {
+		name:   "one hop",
+		params: map[string]string{"table": "order line"},
+		want:   []string{"order", "order line", "shipment"},
+	}, {
+		name:   "two hops",
+		params: map[string]string{"table": "order line", "hops": "2"},
+		want:   []string{"customer", "order", "order line", "shipment"},
+	}, {
+		name:   "missing table",
+		params: map[string]string{"table": "invoice"},
+		err:    `table "invoice" not found`,
+	}, {
+		name:   "missing schema",
+		params: map[string]string{"schemas": "crm"},
+		err:    `schema "crm" not found`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			mm := testMetaModel(t, testModelJSON)
+			mc := testWithParameters(t, DotModelContext, tc.params).(MetaModelWriter)
+			var buf bytes.Buffer
+			err := mc.WriteMetaModel(&buf, mm)
+			switch {
+			case tc.err != "":
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			var got []string
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if strings.HasPrefix(line, "\t\t\"shop.sales.") {
+					name := strings.TrimPrefix(line, "\t\t\"shop.sales.")
+					got = append(got, name[:strings.IndexByte(name, '"')])
+				}
+			}
+			if strings.Join(got, ", ") != strings.Join(tc.want, ", ") {
+				t.Fatalf("got tables %v, want %v in:\n%v", got, tc.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestDotParameters(t *testing.T) {
+	for _, tc := range []struct {
+		params map[string]string
+		err    string
+	}{
+		{map[string]string{"hops": "2"}, "hops parameter requires the table parameter"},
+		{map[string]string{"table": "order", "hops": "-1"}, `hops must be a non-negative integer, not "-1"`},
+		{map[string]string{"table": "order", "hops": "many"}, `hops must be a non-negative integer, not "many"`},
+	} {
+		_, err := DotModelContext.WithParameters(tc.params)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("%v: got error %v, want %q", tc.params, err, tc.err)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/xml"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/skillian/expr/errors"
@@ -13,16 +15,59 @@ import (
 	"github.com/skillian/logging"
 )
 
+const (
+	// drawIOPagesParam is the name of the parameter that selects what
+	// the pages of a diagram become:  drawIOPagesSchemas or
+	// drawIOPagesDatabases.
+	drawIOPagesParam = "pages"
+
+	drawIOPagesSchemas   = "schemas"
+	drawIOPagesDatabases = "databases"
+
+	// drawIODatabaseParam is the name of the parameter that holds the
+	// raw name of the database when pages are schemas.  If blank, the
+	// diagram's file name is used.
+	drawIODatabaseParam = "database"
+
+	// drawIODefaultSchemaPage is the name of the page of the unnamed
+	// schema when pages are schemas.
+	drawIODefaultSchemaPage = "(default)"
+)
+
 var (
 	DrawIOModelContext interface {
 		ModelContext
 		ModelConfigParser
-	} = drawIOModelContext{}
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = drawIOModelContext{pages: drawIOPagesSchemas}
 
 	logger = logging.GetLogger("sqlmodelgen")
 )
 
-type drawIOModelContext struct{}
+type drawIOModelContext struct {
+	pages    string
+	database string
+}
+
+func (mc drawIOModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[drawIOPagesParam]; s != "" {
+		switch s {
+		case drawIOPagesSchemas, drawIOPagesDatabases:
+			mc.pages = s
+		default:
+			return nil, errors.Errorf3(
+				"%v must be %q or %q",
+				drawIOPagesParam, drawIOPagesSchemas,
+				drawIOPagesDatabases,
+			)
+		}
+	}
+	if s := ps[drawIODatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
 
 func (drawIOModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
 	return "", t.String(), nil
@@ -33,15 +78,19 @@ type DrawIOXMLObject struct {
 }
 
 type DrawIOXMLMXFile struct {
-	XMLName xml.Name         `xml:"mxfile"`
-	Version string           `xml:"version,attr"`
-	Diagram DrawIOXMLDiagram `xml:"diagram"`
+	XMLName  xml.Name           `xml:"mxfile"`
+	Version  string             `xml:"version,attr"`
+	Diagrams []DrawIOXMLDiagram `xml:"diagram"`
 }
 
 type DrawIOXMLDiagram struct {
 	DrawIOXMLObject
 	Name       string                `xml:"name,attr"`
 	GraphModel DrawIOXMLMXGraphModel `xml:"mxGraphModel"`
+
+	// Content is the compressed mxGraphModel of a page that was saved
+	// with Draw.io's default "Compressed" option.
+	Content string `xml:",chardata"`
 }
 
 type DrawIOXMLMXGraphModel struct {
@@ -49,16 +98,54 @@ type DrawIOXMLMXGraphModel struct {
 }
 
 type DrawIOXMLRoot struct {
-	Cells []DrawIOXMLCell `xml:"mxCell"`
+	// Cells are the <mxCell>s of the diagram and the <object>s (or
+	// <UserObject>s) that wrap the cells with custom data in the order
+	// that they're defined.
+	Cells []DrawIOXMLCell `xml:",any"`
 }
 
 type DrawIOXMLCell struct {
+	XMLName xml.Name
 	DrawIOXMLObject
 	Parent string `xml:"parent,attr"`
 	Value  string `xml:"value,attr"`
 	Source string `xml:"source,attr"`
 	Target string `xml:"target,attr"`
 	Style  string `xml:"style,attr"`
+
+	// Label is an <object>'s value.
+	Label string `xml:"label,attr"`
+
+	// Attrs are an <object>'s custom data, including its tooltip.
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	// Cell is the <mxCell> that an <object> wraps.
+	Cell *DrawIOXMLCell `xml:"mxCell"`
+}
+
+// drawIOUnwrapXMLCell merges an <object> and the <mxCell> it wraps into one
+// cell.  <mxCell>s are returned as-is.
+func drawIOUnwrapXMLCell(x DrawIOXMLCell) DrawIOXMLCell {
+	if x.Cell == nil {
+		return x
+	}
+	c := *x.Cell
+	c.XMLName = x.XMLName
+	c.ID = x.ID
+	c.Value = x.Label
+	c.Attrs = x.Attrs
+	c.Cell = nil
+	return c
+}
+
+// attr gets an attribute of an <object>'s custom data.
+func (x *DrawIOXMLCell) attr(name string) string {
+	for _, a := range x.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
 }
 
 type DrawIOCell struct {
@@ -71,8 +158,17 @@ type DrawIOCell struct {
 	Source   *DrawIOCell
 	Target   *DrawIOCell
 	Style    DrawIOCellStyle
-	// PK is true when the cell value ends with ": pk"
+	// PK is true when the cell value ends with ": pk" and UK is true
+	// when it ends with ": uk" (in either order).
 	PK bool
+	UK bool
+
+	// Doc, SQLName and ModelName come from the "tooltip" (or "doc"),
+	// "sqlName" and "modelName" data of cells wrapped in <object>s.
+	// A "type" attribute overrides the type in the cell's value.
+	Doc       string
+	SQLName   string
+	ModelName string
 }
 
 type DrawIOCellKind int
@@ -90,6 +186,11 @@ func drawIOCellKindOf(c *DrawIOCell) (k DrawIOCellKind) {
 		k = DrawIOTable
 	case c.Source != nil && c.Target != nil:
 		k = DrawIOArrow
+	case (c.Style.Kind == DrawIOCellStyleSwimLane ||
+		c.Style.Kind == DrawIOCellStyleGroup ||
+		c.Style.Container) &&
+		drawIOHasTables(c):
+		k = DrawIOContainer
 	default:
 		k = DrawIOBadKind
 	}
@@ -102,20 +203,49 @@ const (
 	DrawIOTable
 	DrawIOColumn
 	DrawIOArrow
+
+	// DrawIOContainer is a swimlane, group or other container of
+	// tables.  Its tables are in the schema named by its label.
+	DrawIOContainer
 )
 
+// drawIOHasTables checks if any of a cell's children are tables.  The
+// children's kinds must already be set.
+func drawIOHasTables(c *DrawIOCell) bool {
+	for _, child := range c.Children {
+		if child.Kind == DrawIOTable || child.Kind == DrawIOContainer {
+			return true
+		}
+	}
+	return false
+}
+
 type DrawIOCellStyleKind int
 
 const (
 	DrawIOCellStyleBadKind DrawIOCellStyleKind = iota
 	DrawIOCellStyleText
 	DrawIOCellStyleSwimLane
+	DrawIOCellStyleGroup
 )
 
 type DrawIOCellStyle struct {
 	Kind       DrawIOCellStyleKind
 	StartArrow DrawIOArrowERType
 	EndArrow   DrawIOArrowERType
+
+	// Container is true for shapes that other shapes can be dropped
+	// into.
+	Container bool
+}
+
+func (s *DrawIOCellStyle) setContainer(v string) (ok bool) {
+	switch v {
+	case "0", "1":
+		s.Container = v == "1"
+		return true
+	}
+	return false
 }
 
 func (s *DrawIOCellStyle) setStartArrow(v string) (ok bool) {
@@ -131,6 +261,7 @@ func (s *DrawIOCellStyle) setEndArrow(v string) (ok bool) {
 var drawIOCellStyleSetters = map[string]func(*DrawIOCellStyle, string) bool{
 	"startArrow": (*DrawIOCellStyle).setStartArrow,
 	"endArrow":   (*DrawIOCellStyle).setEndArrow,
+	"container":  (*DrawIOCellStyle).setContainer,
 }
 
 func drawIOParseCellStyle(v string) (s DrawIOCellStyle, err error) {
@@ -162,6 +293,8 @@ func drawIOParseCellStyle(v string) (s DrawIOCellStyle, err error) {
 					s.Kind = DrawIOCellStyleText
 				case "swimlane":
 					s.Kind = DrawIOCellStyleSwimLane
+				case "group":
+					s.Kind = DrawIOCellStyleGroup
 				default:
 					logger.Warn1(
 						"unhandled name-only style: %v",
@@ -220,20 +353,43 @@ const (
 	DrawIOMandOne
 )
 
+// Many is true if the arrow end means "zero or more" or "one or more."
+func (t DrawIOArrowERType) Many() bool {
+	return t == DrawIOZeroToMany || t == DrawIOOneToMany
+}
+
 var drawIOArrowERTypes = map[string]DrawIOArrowERType{
 	"ERzeroToOne":  DrawIOZeroToOne,
 	"ERzeroToMany": DrawIOZeroToMany,
 	"ERoneToMany":  DrawIOOneToMany,
 	"ERmandOne":    DrawIOMandOne,
+	"ERone":        DrawIOMandOne,
+	"ERmany":       DrawIOZeroToMany,
+	"none":         DrawIOBadArrowERType,
 }
 
+// DrawIOCellFromXML reads the first page of a Draw.io diagram.  Use
+// DrawIOPagesFromXML to read all of the pages.
 func DrawIOCellFromXML(r io.Reader) (*DrawIOCell, error) {
+	pages, err := DrawIOPagesFromXML(r)
+	if err != nil {
+		return nil, err
+	}
+	return pages[0], nil
+}
+
+// DrawIOPagesFromXML reads every page (i.e. <diagram>) of a Draw.io diagram
+// into its own root cell.
+func DrawIOPagesFromXML(r io.Reader) ([]*DrawIOCell, error) {
 	bs, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, errors.Errorf1From(
 			err, "failed to read all bytes from %v", r,
 		)
 	}
+	if bs, err = drawIOMXFileXML(bs); err != nil {
+		return nil, err
+	}
 	var f DrawIOXMLMXFile
 	if err = xml.Unmarshal(bs, &f); err != nil {
 		return nil, errors.Errorf2From(
@@ -241,16 +397,51 @@ func DrawIOCellFromXML(r io.Reader) (*DrawIOCell, error) {
 			len(bs), f,
 		)
 	}
-	root := &DrawIOCell{
-		// The diagram (sheet) name gets dropped into the root cell
-		// and becomes the database name.
-		Name: f.Diagram.Name,
+	if len(f.Diagrams) == 0 {
+		return nil, errors.Errorf("diagram has no pages")
 	}
-	cells := make(map[string]*DrawIOCell, len(f.Diagram.GraphModel.Root.Cells)+1)
+	pages := make([]*DrawIOCell, len(f.Diagrams))
+	for i := range f.Diagrams {
+		if err = drawIODecompressDiagram(&f.Diagrams[i]); err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to decompress page %q",
+				f.Diagrams[i].Name,
+			)
+		}
+		if pages[i], err = drawIOCellFromXMLDiagram(&f.Diagrams[i]); err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to read page %q", f.Diagrams[i].Name,
+			)
+		}
+	}
+	return pages, nil
+}
+
+func drawIOCellFromXMLDiagram(d *DrawIOXMLDiagram) (root *DrawIOCell, err error) {
+	root = &DrawIOCell{
+		// The diagram (page) name gets dropped into the root cell
+		// and becomes the schema or database name.
+		Name: d.Name,
+	}
+	cells := make(map[string]*DrawIOCell, len(d.GraphModel.Root.Cells)+1)
 	cells[""] = root
-	for _, x := range f.Diagram.GraphModel.Root.Cells {
+	xcells := make([]DrawIOXMLCell, len(d.GraphModel.Root.Cells))
+	for i, x := range d.GraphModel.Root.Cells {
+		xcells[i] = drawIOUnwrapXMLCell(x)
+	}
+	for _, x := range xcells {
 		cell := &DrawIOCell{
-			ID: x.ID,
+			ID:        x.ID,
+			SQLName:   x.attr("sqlName"),
+			ModelName: x.attr("modelName"),
+			Doc:       x.attr("tooltip"),
+		}
+		if cell.Doc == "" {
+			cell.Doc = x.attr("doc")
+		}
+		cell.Style, err = drawIOParseCellStyle(x.Style)
+		if err != nil {
+			return nil, err
 		}
 		if _, ok := cells[x.ID]; ok {
 			// sanity check
@@ -307,21 +498,46 @@ func DrawIOCellFromXML(r io.Reader) (*DrawIOCell, error) {
 			}
 		case x.Value != "":
 			// a cell
-			// TODO: Have to distinguish between table and column cells:
-			switch parent.Style.Kind {
-			case DrawIOCellStyleSwimLane:
+			switch {
+			case parent.Style.Kind == DrawIOCellStyleSwimLane &&
+				cell.Style.Kind != DrawIOCellStyleSwimLane &&
+				cell.Style.Kind != DrawIOCellStyleGroup &&
+				!cell.Style.Container:
+				// a column of a table.
+				typeAttr := x.attr("type")
 				pivot := strings.IndexByte(x.Value, ':')
 				if pivot == -1 {
-					return nil, errors.Errorf1(
-						"failed to get name of cell with ID %v",
-						x.ID,
-					)
+					if typeAttr == "" {
+						return nil, errors.Errorf1(
+							"failed to get name of cell with ID %v",
+							x.ID,
+						)
+					}
+					pivot = len(x.Value)
+				}
+				rest := strings.TrimSpace(strings.TrimPrefix(x.Value[pivot:], ":"))
+				// Flags follow the type or, when the type is in
+				// the type attribute, the name.
+			flags:
+				for rest != "" {
+					i := strings.LastIndexByte(rest, ':')
+					switch strings.TrimSpace(rest[i+1:]) {
+					case "pk":
+						cell.PK = true
+					case "uk":
+						cell.UK = true
+					default:
+						break flags
+					}
+					if i == -1 {
+						i = 0
+					}
+					rest = strings.TrimSpace(rest[:i])
 				}
-				cell.Name, x.Value = x.Value[:pivot], x.Value[pivot+1:]
-				if strings.HasSuffix(x.Value, ": pk") {
-					cell.PK, x.Value = true, x.Value[:len(x.Value)-len(": pk")]
+				cell.Name, cell.Type = x.Value[:pivot], strings.TrimSpace(rest)
+				if typeAttr != "" {
+					cell.Type = typeAttr
 				}
-				cell.Type = strings.TrimSpace(x.Value)
 			default:
 				cell.Name = x.Value
 			}
@@ -329,83 +545,173 @@ func DrawIOCellFromXML(r io.Reader) (*DrawIOCell, error) {
 		default:
 			logger.Warn("ignoring cell with ID %v", x.ID)
 		}
-		cell.Style, err = drawIOParseCellStyle(x.Style)
-		if err != nil {
-			return nil, err
-		}
 	}
-	for _, x := range f.Diagram.GraphModel.Root.Cells {
-		cell := cells[x.ID]
+	// Children come after their parents, so kinds are set in reverse
+	// to set the kinds of tables before the kinds of their containers.
+	for i := len(xcells) - 1; i >= 0; i-- {
+		cell := cells[xcells[i].ID]
 		cell.Kind = drawIOCellKindOf(cell)
 	}
 	return root, nil
 }
 
-func (drawIOModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (cfg config.Config, err error) {
-	root, err := DrawIOCellFromXML(r)
+// drawIOColumnPath is the raw database, schema, table and column names of
+// a column cell.
+type drawIOColumnPath [4]string
+
+func (p drawIOColumnPath) String() string { return strings.Join(p[:], ".") }
+
+// drawIOPage is where the tables of a page go.
+type drawIOPage struct {
+	database, schema string
+}
+
+func (mc drawIOModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := mc.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+func (mc drawIOModelContext) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	roots, err := DrawIOPagesFromXML(r)
 	if err != nil {
 		return cfg, err
 	}
-	cellList := make([]*DrawIOCell, 0, 16)
-	var recurseAppendCells func(*DrawIOCell)
-	recurseAppendCells = func(c *DrawIOCell) {
-		cellList = append(cellList, c)
-		for _, child := range c.Children {
-			recurseAppendCells(child)
+	dbName := mc.database
+	if dbName == "" {
+		dbName = drawIODatabaseName(r)
+	}
+	pagesByName := make(map[string]drawIOPage, len(roots))
+	for _, root := range roots {
+		page := drawIOPage{database: dbName, schema: root.Name}
+		switch {
+		case mc.pages == drawIOPagesDatabases:
+			page = drawIOPage{database: root.Name}
+		case root.Name == drawIODefaultSchemaPage:
+			page.schema = ""
 		}
+		pagesByName[root.Name] = page
 	}
-	recurseAppendCells(root)
-	cfg.Databases = []config.Database{
-		{
-			CommonData: config.CommonData{
-				Names: config.Names{
-					RawName: root.Name,
-				},
-			},
-			Schemas: []config.Schema{
-				{
-					Tables: make([]config.Table, 0, 8),
-				},
-			},
-		},
+	qualifiers := drawIOQualifiers(roots)
+	type arrow struct {
+		id     string
+		source drawIOColumnPath
+		target drawIOColumnPath
+		style  DrawIOCellStyle
 	}
-	sch := &cfg.Databases[0].Schemas[0]
-	tableIdxByID := make(map[string]int)
-	tblColIdxByID := make(map[string][2]int)
-	for _, cell := range cellList {
-		switch cell.Kind {
-		case DrawIOTable:
-			tableIdxByID[cell.ID] = len(sch.Tables)
-			sch.Tables = append(sch.Tables, config.Table{
-				CommonData: config.CommonData{
-					Names: config.Names{
-						RawName: cell.Name,
-					},
-				},
-				Columns: make([]config.Column, 0, len(cell.Children)),
-			})
-		case DrawIOColumn:
-			ti, ok := tableIdxByID[cell.Parent.ID]
-			if !ok {
-				return cfg, errors.Errorf2(
-					"failed to find table (ID: %v) for "+
-						"cell (ID: %v)",
-					cell.Parent.ID, cell.ID,
-				)
+	arrows := make([]arrow, 0, 16)
+	for _, root := range roots {
+		page := pagesByName[root.Name]
+		cellList := make([]*DrawIOCell, 0, 16)
+		var recurseAppendCells func(*DrawIOCell)
+		recurseAppendCells = func(c *DrawIOCell) {
+			cellList = append(cellList, c)
+			for _, child := range c.Children {
+				recurseAppendCells(child)
 			}
-			table := &sch.Tables[ti]
-			tblColIdxByID[cell.ID] = [2]int{ti, len(table.Columns)}
-			table.Columns = append(table.Columns, config.Column{
-				CommonData: config.CommonData{
-					Names: config.Names{
-						RawName: cell.Name,
+		}
+		recurseAppendCells(root)
+		// Cell IDs are only unique within a page.
+		tablePathByID := make(map[string]drawIOColumnPath)
+		colPathByID := make(map[string]drawIOColumnPath)
+		for _, cell := range cellList {
+			switch cell.Kind {
+			case DrawIOTable:
+				if _, _, ok := drawIOTableReference(cell.Name, qualifiers); ok {
+					// Tables on other pages are
+					// referenced by their qualified
+					// names and aren't redefined.
+					continue
+				}
+				if i := strings.LastIndexByte(cell.Name, '.'); i != -1 {
+					logger.Warn2(
+						"table %q is not a placeholder "+
+							"because no page or schema "+
+							"is named %q",
+						cell.Name, cell.Name[:i],
+					)
+				}
+				tblPage := page
+				if name := drawIOContainerName(cell); name != "" {
+					tblPage.schema = name
+				}
+				sch := drawIOSchema(&cfg.Config, tblPage)
+				tablePathByID[cell.ID] = drawIOColumnPath{
+					tblPage.database, tblPage.schema, cell.Name,
+				}
+				sch.Tables = append(sch.Tables, config.Table{
+					CommonData: config.CommonData{
+						Names: config.Names{
+							RawName:   cell.Name,
+							SQLName:   cell.SQLName,
+							ModelName: cell.ModelName,
+						},
+						Doc: cell.Doc,
 					},
-				},
-				Type: cell.Type,
-				PK:   cell.PK,
-			})
-		case DrawIOArrow:
-			si, ok := tblColIdxByID[cell.Source.ID]
+					Columns: make([]config.Column, 0, len(cell.Children)),
+				})
+			case DrawIOColumn:
+				if qualifier, tblName, ok := drawIOTableReference(cell.Parent.Name, qualifiers); ok {
+					// The qualifier is the name of a page,
+					// a page and schema or a schema in
+					// this page's database.
+					ref, ok := pagesByName[qualifier]
+					if !ok {
+						ref = drawIOPage{
+							database: page.database,
+							schema:   qualifier,
+						}
+						if i := strings.IndexByte(qualifier, '.'); i != -1 {
+							if p, ok := pagesByName[qualifier[:i]]; ok {
+								ref = drawIOPage{
+									database: p.database,
+									schema:   qualifier[i+1:],
+								}
+							}
+						}
+					}
+					colPathByID[cell.ID] = drawIOColumnPath{
+						ref.database, ref.schema, tblName, cell.Name,
+					}
+					continue
+				}
+				tp, ok := tablePathByID[cell.Parent.ID]
+				if !ok {
+					return cfg, errors.Errorf2(
+						"failed to find table (ID: %v) for "+
+							"cell (ID: %v)",
+						cell.Parent.ID, cell.ID,
+					)
+				}
+				table := drawIOTable(&cfg.Config, tp)
+				colPathByID[cell.ID] = drawIOColumnPath{
+					tp[0], tp[1], tp[2], cell.Name,
+				}
+				table.Columns = append(table.Columns, config.Column{
+					CommonData: config.CommonData{
+						Names: config.Names{
+							RawName:   cell.Name,
+							SQLName:   cell.SQLName,
+							ModelName: cell.ModelName,
+						},
+						Doc: cell.Doc,
+					},
+					Type: cell.Type,
+					PK:   cell.PK,
+				})
+				if cell.UK {
+					x := cfg.Ext.Table(tp[0], tp[1], tp[2])
+					x.Indexes = append(x.Indexes, IndexConfig{
+						Unique:  true,
+						Columns: []IndexColumnConfig{{RawName: cell.Name}},
+					})
+				}
+			}
+		}
+		for _, cell := range cellList {
+			if cell.Kind != DrawIOArrow {
+				continue
+			}
+			src, ok := colPathByID[cell.Source.ID]
 			if !ok {
 				return cfg, errors.Errorf2(
 					"failed to find source column (ID: %v) for "+
@@ -413,7 +719,7 @@ func (drawIOModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (cf
 					cell.Source.ID, cell.ID,
 				)
 			}
-			ti, ok := tblColIdxByID[cell.Target.ID]
+			trg, ok := colPathByID[cell.Target.ID]
 			if !ok {
 				return cfg, errors.Errorf2(
 					"failed to find target column (ID: %v) for "+
@@ -421,17 +727,212 @@ func (drawIOModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (cf
 					cell.Target.ID, cell.ID,
 				)
 			}
-			src := &sch.Tables[si[0]].Columns[si[1]]
-			trgTbl := &sch.Tables[ti[0]]
-			trgCol := &trgTbl.Columns[ti[1]]
-			src.FK = strings.Join([]string{
-				cfg.Databases[0].RawName,
-				sch.RawName,
-				trgTbl.RawName,
-				trgCol.RawName,
-			}, ".")
-			src.PK = true // TODO: Should we do this implicitly?
+			arrows = append(arrows, arrow{
+				id:     cell.ID,
+				source: src,
+				target: trg,
+				style:  cell.Style,
+			})
+		}
+	}
+	// Arrows are linked after every page is read because their columns
+	// might be on other pages.
+	for _, a := range arrows {
+		// The arrow's start is the referencing column and its end is
+		// the referenced column unless it was drawn the other way
+		// around, from the "one" end to the "many" end.
+		fkEnd, refEnd := a.style.StartArrow, a.style.EndArrow
+		if refEnd.Many() && !fkEnd.Many() {
+			a.source, a.target = a.target, a.source
+			fkEnd, refEnd = refEnd, fkEnd
+		}
+		if refEnd.Many() {
+			return cfg, errors.Errorf3(
+				"arrow (ID: %v) from %v to %v is many-to-many.  "+
+					"Please add a table between them.",
+				a.id, a.source, a.target,
+			)
+		}
+		src := drawIOColumn(&cfg.Config, a.source)
+		if src == nil {
+			return cfg, errors.Errorf2(
+				"failed to find source column %v of arrow (ID: %v)",
+				a.source, a.id,
+			)
+		}
+		if drawIOColumn(&cfg.Config, a.target) == nil {
+			return cfg, errors.Errorf2(
+				"failed to find target column %v of arrow (ID: %v)",
+				a.target, a.id,
+			)
+		}
+		src.FK = a.target.String()
+		switch refEnd {
+		case DrawIOZeroToOne, DrawIOMandOne:
+			if src.Type, err = nullableTypeString(src.Type, refEnd == DrawIOZeroToOne); err != nil {
+				return cfg, errors.Errorf2From(
+					err, "invalid type of column %v (arrow ID: %v)",
+					a.source, a.id,
+				)
+			}
+		}
+		switch fkEnd {
+		case DrawIOZeroToOne, DrawIOMandOne:
+			cfg.Ext.Column(
+				a.source[0], a.source[1], a.source[2], a.source[3],
+			).OneToOne = true
 		}
 	}
 	return
 }
+
+// nullableTypeString makes the column type, s, nullable or not nullable.
+func nullableTypeString(s string, nullable bool) (string, error) {
+	t, err := sqltypes.Parse(s)
+	if err != nil {
+		return s, err
+	}
+	n, ok := t.(sqltypes.Nullable)
+	switch {
+	case nullable && !ok:
+		t = sqltypes.Nullable{t}
+	case !nullable && ok:
+		t = n[0]
+	default:
+		return s, nil
+	}
+	return t.String(), nil
+}
+
+// drawIOTableReference checks if a table's name is qualified with the name
+// of the page or container that it's defined in (i.e. "page.table").  Such
+// tables are placeholders that arrows on other pages can point to.  Names
+// whose qualifiers aren't in qualifiers are the names of ordinary tables.
+func drawIOTableReference(name string, qualifiers map[string]struct{}) (pageName, tableName string, ok bool) {
+	i := strings.LastIndexByte(name, '.')
+	if i == -1 {
+		return "", name, false
+	}
+	if _, ok := qualifiers[name[:i]]; !ok {
+		return "", name, false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// drawIOQualifiers gets the qualifiers that placeholder tables can have:
+// the names of the pages and containers and the names of containers
+// qualified with the names of their pages.
+func drawIOQualifiers(roots []*DrawIOCell) map[string]struct{} {
+	qualifiers := make(map[string]struct{}, len(roots))
+	var walk func(root, c *DrawIOCell)
+	walk = func(root, c *DrawIOCell) {
+		if c.Kind == DrawIOContainer && c.Name != "" {
+			qualifiers[c.Name] = struct{}{}
+			qualifiers[root.Name+"."+c.Name] = struct{}{}
+		}
+		for _, child := range c.Children {
+			walk(root, child)
+		}
+	}
+	for _, root := range roots {
+		qualifiers[root.Name] = struct{}{}
+		walk(root, root)
+	}
+	return qualifiers
+}
+
+// drawIODatabaseName gets the name of the diagram's file without its
+// extensions (e.g. "MyDiagram" from "MyDiagram.drawio.xml").
+func drawIODatabaseName(r io.Reader) string {
+	if f, ok := r.(*os.File); ok {
+		name := filepath.Base(f.Name())
+		if i := strings.IndexByte(name, '.'); i > 0 {
+			name = name[:i]
+		}
+		return name
+	}
+	return "main"
+}
+
+// drawIOSchema gets or adds the schema of a page.
+func drawIOSchema(cfg *config.Config, page drawIOPage) *config.Schema {
+	var db *config.Database
+	for i := range cfg.Databases {
+		if cfg.Databases[i].RawName == page.database {
+			db = &cfg.Databases[i]
+			break
+		}
+	}
+	if db == nil {
+		cfg.Databases = append(cfg.Databases, config.Database{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: page.database,
+				},
+			},
+		})
+		db = &cfg.Databases[len(cfg.Databases)-1]
+	}
+	for i := range db.Schemas {
+		if db.Schemas[i].RawName == page.schema {
+			return &db.Schemas[i]
+		}
+	}
+	db.Schemas = append(db.Schemas, config.Schema{
+		CommonData: config.CommonData{
+			Names: config.Names{
+				RawName: page.schema,
+			},
+		},
+		Tables: make([]config.Table, 0, 8),
+	})
+	return &db.Schemas[len(db.Schemas)-1]
+}
+
+// drawIOTable finds a table in the configuration by the first three
+// elements of its path.
+func drawIOTable(cfg *config.Config, p drawIOColumnPath) *config.Table {
+	for i := range cfg.Databases {
+		db := &cfg.Databases[i]
+		if db.RawName != p[0] {
+			continue
+		}
+		for j := range db.Schemas {
+			sch := &db.Schemas[j]
+			if sch.RawName != p[1] {
+				continue
+			}
+			for k := range sch.Tables {
+				if sch.Tables[k].RawName == p[2] {
+					return &sch.Tables[k]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// drawIOColumn finds a column in the configuration by its path.
+func drawIOColumn(cfg *config.Config, p drawIOColumnPath) *config.Column {
+	tbl := drawIOTable(cfg, p)
+	if tbl == nil {
+		return nil
+	}
+	for i := range tbl.Columns {
+		if tbl.Columns[i].RawName == p[3] {
+			return &tbl.Columns[i]
+		}
+	}
+	return nil
+}
+
+// drawIOContainerName gets the name of the innermost named container of a
+// table or a blank string if the table isn't in a named container.
+func drawIOContainerName(c *DrawIOCell) string {
+	for p := c.Parent; p != nil; p = p.Parent {
+		if p.Kind == DrawIOContainer && p.Name != "" {
+			return p.Name
+		}
+	}
+	return ""
+}
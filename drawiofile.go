@@ -0,0 +1,194 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+)
+
+// drawIOPNGSignature starts every PNG file.
+var drawIOPNGSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// drawIOMXFileXML gets the <mxfile> XML out of a Draw.io file's bytes.
+// .drawio.png files keep it in a PNG text chunk and .drawio.svg files keep
+// it in the <svg> element's content attribute.  Anything else is assumed to
+// already be the <mxfile>.
+func drawIOMXFileXML(bs []byte) ([]byte, error) {
+	if bytes.HasPrefix(bs, drawIOPNGSignature) {
+		return drawIOPNGMXFile(bs)
+	}
+	d := xml.NewDecoder(bytes.NewReader(bs))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, errors.Errorf0From(
+				err, "failed to find the root XML element",
+			)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return bs, nil
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "content" {
+				return drawIOContentMXFile(attr.Value)
+			}
+		}
+		return nil, errors.Errorf(
+			"SVG has no embedded Draw.io diagram.  Please check " +
+				"that it was exported with \"Include a copy " +
+				"of my diagram\" checked.",
+		)
+	}
+}
+
+// drawIOContentMXFile gets the <mxfile> from the content embedded in an
+// SVG or PNG.  Older versions of Draw.io compressed the whole <mxfile>.
+func drawIOContentMXFile(content string) ([]byte, error) {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "<") {
+		return []byte(content), nil
+	}
+	s, err := drawIODecompress(content)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// drawIOPNGMXFile gets the <mxfile> out of the "mxfile" text chunk of a
+// PNG.
+func drawIOPNGMXFile(bs []byte) ([]byte, error) {
+	bs = bs[len(drawIOPNGSignature):]
+	for len(bs) >= 12 {
+		length := binary.BigEndian.Uint32(bs)
+		if uint64(length)+12 > uint64(len(bs)) {
+			break
+		}
+		kind, data := string(bs[4:8]), bs[8:8+length]
+		bs = bs[12+length:]
+		keyword, text, err := drawIOPNGText(kind, data)
+		if err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to read PNG %v chunk", kind,
+			)
+		}
+		if keyword != "mxfile" {
+			continue
+		}
+		s, err := url.PathUnescape(text)
+		if err != nil {
+			return nil, errors.Errorf0From(
+				err, "failed to URL-decode PNG mxfile chunk",
+			)
+		}
+		return drawIOContentMXFile(s)
+	}
+	return nil, errors.Errorf(
+		"PNG has no embedded Draw.io diagram.  Please check that it " +
+			"was exported with \"Include a copy of my diagram\" " +
+			"checked.",
+	)
+}
+
+// drawIOPNGText gets the keyword and text out of a PNG tEXt, zTXt or iTXt
+// chunk.  Other chunks have no keyword.
+func drawIOPNGText(kind string, data []byte) (keyword, text string, err error) {
+	switch kind {
+	case "tEXt", "zTXt", "iTXt":
+	default:
+		return "", "", nil
+	}
+	i := bytes.IndexByte(data, 0)
+	if i == -1 {
+		return "", "", errors.Errorf("keyword is not terminated")
+	}
+	keyword, data = string(data[:i]), data[i+1:]
+	compressed := false
+	switch kind {
+	case "zTXt":
+		if len(data) < 1 {
+			return "", "", errors.Errorf("missing compression method")
+		}
+		compressed, data = true, data[1:]
+	case "iTXt":
+		if len(data) < 2 {
+			return "", "", errors.Errorf("missing compression flag")
+		}
+		compressed, data = data[0] != 0, data[2:]
+		// skip the language tag and translated keyword.
+		for j := 0; j < 2; j++ {
+			if i = bytes.IndexByte(data, 0); i == -1 {
+				return "", "", errors.Errorf(
+					"language or translated keyword " +
+						"is not terminated",
+				)
+			}
+			data = data[i+1:]
+		}
+	}
+	if compressed {
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", "", errors.Errorf0From(
+				err, "failed to open zlib stream",
+			)
+		}
+		if data, err = ioutil.ReadAll(zr); err != nil {
+			return "", "", errors.Errorf0From(
+				err, "failed to decompress text",
+			)
+		}
+	}
+	return keyword, string(data), nil
+}
+
+// drawIODecompressDiagram decodes a page that was saved compressed into
+// its GraphModel.  Uncompressed pages are left as-is.
+func drawIODecompressDiagram(d *DrawIOXMLDiagram) error {
+	content := strings.TrimSpace(d.Content)
+	if content == "" || len(d.GraphModel.Root.Cells) > 0 {
+		return nil
+	}
+	s, err := drawIODecompress(content)
+	if err != nil {
+		return err
+	}
+	if err = xml.Unmarshal([]byte(s), &d.GraphModel); err != nil {
+		return errors.Errorf1From(
+			err, "failed to unmarshal decompressed XML into %T",
+			d.GraphModel,
+		)
+	}
+	return nil
+}
+
+// drawIODecompress decodes Draw.io's compressed format:  Base64-encoded,
+// raw-deflated, URL-encoded text.
+func drawIODecompress(s string) (string, error) {
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", errors.Errorf0From(err, "failed to decode base64")
+	}
+	fr := flate.NewReader(bytes.NewReader(bs))
+	defer fr.Close()
+	if bs, err = ioutil.ReadAll(fr); err != nil && err != io.ErrUnexpectedEOF {
+		return "", errors.Errorf0From(err, "failed to inflate")
+	}
+	if s, err = url.PathUnescape(string(bs)); err != nil {
+		return "", errors.Errorf0From(err, "failed to URL-decode")
+	}
+	return s, nil
+}
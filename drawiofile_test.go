@@ -0,0 +1,248 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testDrawIOCompress encodes s the way that Draw.io saves compressed
+// diagrams.
+func testDrawIOCompress(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fw.Write([]byte(url.PathEscape(s))); err != nil {
+		t.Fatal(err)
+	}
+	if err = fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// testZlib compresses a PNG zTXt or iTXt chunk's text.
+func testZlib(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testPNGChunk is the kind and data of a PNG chunk.
+type testPNGChunk struct {
+	kind string
+	data []byte
+}
+
+// testPNG creates a PNG file out of its chunks.  The chunks only have to
+// be good enough for drawIOPNGMXFile; the image itself is never decoded.
+func testPNG(chunks ...testPNGChunk) []byte {
+	buf := bytes.NewBuffer(append([]byte(nil), drawIOPNGSignature...))
+	chunks = append([]testPNGChunk{{"IHDR", make([]byte, 13)}}, chunks...)
+	chunks = append(chunks, testPNGChunk{"IEND", nil})
+	for _, c := range chunks {
+		var n [4]byte
+		binary.BigEndian.PutUint32(n[:], uint32(len(c.data)))
+		buf.Write(n[:])
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(c.kind))
+		crc.Write(c.data)
+		buf.WriteString(c.kind)
+		buf.Write(c.data)
+		binary.BigEndian.PutUint32(n[:], crc.Sum32())
+		buf.Write(n[:])
+	}
+	return buf.Bytes()
+}
+
+// testDrawIOSVG wraps content in an exported .drawio.svg.
+func testDrawIOSVG(content string) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">
+<svg xmlns="http://www.w3.org/2000/svg" width="10px" height="10px" content="` +
+		html.EscapeString(content) + `"><rect width="10" height="10"/></svg>`)
+}
+
+// testDrawIOCompressPages compresses the <mxGraphModel> of every page of
+// an <mxfile>.
+func testDrawIOCompressPages(t *testing.T, mxfile string) string {
+	t.Helper()
+	re := regexp.MustCompile(`(?s)(<diagram[^>]*>)\s*(<mxGraphModel.*?</mxGraphModel>)\s*(</diagram>)`)
+	n := 0
+	s := re.ReplaceAllStringFunc(mxfile, func(m string) string {
+		n++
+		parts := re.FindStringSubmatch(m)
+		return parts[1] + testDrawIOCompress(t, parts[2]) + parts[3]
+	})
+	if n == 0 {
+		t.Fatalf("no pages to compress in:\n%v", mxfile)
+	}
+	return s
+}
+
+func TestDrawIODecompress(t *testing.T) {
+	const want = `<mxGraphModel><root><mxCell id="0" value="a &amp; b: 100%"/></root></mxGraphModel>`
+	got, err := drawIODecompress(testDrawIOCompress(t, want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for _, tc := range []struct {
+		name, src, err string
+	}{
+		{"base64", "not base64!", "failed to decode base64"},
+		{"deflate", base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0xff}), "failed to inflate"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := drawIODecompress(tc.src)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("got error %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestDrawIOPNGMXFile(t *testing.T) {
+	const mxfile = `<mxfile host="test"><diagram name="Page-1">100% &lt;done&gt;</diagram></mxfile>`
+	escaped := url.PathEscape(mxfile)
+	iTXt := func(compressed bool, text []byte) []byte {
+		flag := byte(0)
+		if compressed {
+			flag = 1
+		}
+		data := append([]byte("mxfile\x00"), flag, 0)
+		data = append(data, "en\x00mxfile\x00"...)
+		return append(data, text...)
+	}
+	for _, tc := range []struct {
+		name string
+		png  []byte
+		err  string
+	}{{
+		name: "tEXt",
+		png: testPNG(
+			testPNGChunk{"tEXt", []byte("Software\x00draw.io")},
+			testPNGChunk{"tEXt", []byte("mxfile\x00" + escaped)},
+		),
+	}, {
+		name: "zTXt",
+		png: testPNG(testPNGChunk{
+			"zTXt", append([]byte("mxfile\x00\x00"), testZlib(t, escaped)...),
+		}),
+	}, {
+		name: "iTXt",
+		png:  testPNG(testPNGChunk{"iTXt", iTXt(false, []byte(escaped))}),
+	}, {
+		name: "compressed iTXt",
+		png:  testPNG(testPNGChunk{"iTXt", iTXt(true, testZlib(t, escaped))}),
+	}, {
+		name: "compressed mxfile",
+		png: testPNG(testPNGChunk{
+			"tEXt", []byte("mxfile\x00" + url.PathEscape(testDrawIOCompress(t, mxfile))),
+		}),
+	}, {
+		name: "no diagram",
+		png:  testPNG(testPNGChunk{"tEXt", []byte("Software\x00draw.io")}),
+		err:  "PNG has no embedded Draw.io diagram",
+	}, {
+		name: "unterminated keyword",
+		png:  testPNG(testPNGChunk{"tEXt", []byte("mxfile")}),
+		err:  "keyword is not terminated",
+	}, {
+		name: "bad zlib",
+		png:  testPNG(testPNGChunk{"zTXt", []byte("mxfile\x00\x00not zlib")}),
+		err:  "failed to open zlib stream",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := drawIOPNGMXFile(tc.png)
+			switch {
+			case tc.err != "":
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case string(got) != mxfile:
+				t.Fatalf("got %q, want %q", got, mxfile)
+			}
+		})
+	}
+}
+
+func TestDrawIOMXFileXML(t *testing.T) {
+	const mxfile = `<mxfile host="test"><diagram name="Page-1">x</diagram></mxfile>`
+	for _, tc := range []struct {
+		name string
+		src  []byte
+		err  string
+	}{
+		{name: "mxfile", src: []byte("<?xml version=\"1.0\"?>\n" + mxfile)},
+		{name: "svg", src: testDrawIOSVG(mxfile)},
+		{name: "compressed svg", src: testDrawIOSVG(testDrawIOCompress(t, mxfile))},
+		{name: "png", src: testPNG(testPNGChunk{"tEXt", []byte("mxfile\x00" + url.PathEscape(mxfile))})},
+		{name: "svg without diagram", src: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), err: "SVG has no embedded Draw.io diagram"},
+		{name: "empty", src: nil, err: "failed to find the root XML element"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := drawIOMXFileXML(tc.src)
+			switch {
+			case tc.err != "":
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case !strings.HasSuffix(string(got), mxfile):
+				t.Fatalf("got %q, want %q", got, mxfile)
+			}
+		})
+	}
+}
+
+// TestDrawIOFiles parses the same diagram saved in each of the formats
+// that Draw.io saves or exports it in.
+func TestDrawIOFiles(t *testing.T) {
+	mm := testMetaModel(t, testModelJSON)
+	want := modelSummary(mm)
+	mxfile := testWrite(t, DrawIOWriterModelContext, mm)
+	compressed := testDrawIOCompressPages(t, mxfile)
+	for _, tc := range []struct {
+		name string
+		src  []byte
+	}{
+		{"compressed", []byte(compressed)},
+		{"svg", testDrawIOSVG(mxfile)},
+		{"compressed svg", testDrawIOSVG(compressed)},
+		{"png", testPNG(testPNGChunk{"zTXt", append([]byte("mxfile\x00\x00"), testZlib(t, url.PathEscape(compressed))...)})},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := testParse(t, DrawIOModelContext.(ExtModelConfigParser), string(tc.src))
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			if got := modelSummary(parsed); got != want {
+				t.Fatalf("parsed:\n%v\nwant:\n%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,601 @@
+package sqlmodelgen
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// drawIOLayoutParam is the name of the parameter that selects how
+	// tables are arranged:  drawIOLayoutGrid or drawIOLayoutLayered.
+	drawIOLayoutParam = "layout"
+
+	// drawIOLayoutGrid arranges tables in rows and columns in the
+	// order that they're defined.
+	drawIOLayoutGrid = "grid"
+
+	// drawIOLayoutLayered arranges tables in columns from left to
+	// right so that tables are to the right of the tables that they
+	// reference.
+	drawIOLayoutLayered = "layered"
+
+	drawIORowHeight   = 26
+	drawIOMinWidth    = 160
+	drawIOCharWidth   = 7
+	drawIOTableGap    = 60
+	drawIOPadding     = 20
+	drawIOTableStyle  = "swimlane;fontStyle=1;childLayout=stackLayout;horizontal=1;startSize=26;horizontalStack=0;resizeParent=1;resizeLast=0;collapsible=1;marginBottom=0;"
+	drawIOColumnStyle = "text;strokeColor=none;fillColor=none;align=left;verticalAlign=top;spacingLeft=4;spacingRight=4;overflow=hidden;rotatable=0;points=[[0,0.5],[1,0.5]];portConstraint=eastwest;"
+	drawIOSchemaStyle = "swimlane;container=1;startSize=26;fontStyle=1;"
+	drawIOArrowStyle  = "edgeStyle=entityRelationEdgeStyle;fontSize=12;html=1;endFill=0;startFill=0;"
+)
+
+var (
+	// DrawIOWriterModelContext writes models as a Draw.io ERD that the
+	// DrawIOModelContext can read back.
+	DrawIOWriterModelContext interface {
+		ModelContext
+		MetaModelWriter
+		ParameterizedModelContext
+	} = drawIOWriterModelContext{
+		pages:  drawIOPagesSchemas,
+		layout: drawIOLayoutGrid,
+	}
+)
+
+type drawIOWriterModelContext struct {
+	pages  string
+	layout string
+}
+
+func (drawIOWriterModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc drawIOWriterModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	pmc, err := DrawIOModelContext.WithParameters(ps)
+	if err != nil {
+		return nil, err
+	}
+	mc.pages = pmc.(drawIOModelContext).pages
+	if s := ps[drawIOLayoutParam]; s != "" {
+		switch s {
+		case drawIOLayoutGrid, drawIOLayoutLayered:
+			mc.layout = s
+		default:
+			return nil, errors.Errorf3(
+				"%v must be %q or %q",
+				drawIOLayoutParam, drawIOLayoutGrid,
+				drawIOLayoutLayered,
+			)
+		}
+	}
+	return mc, nil
+}
+
+// drawIOXMLWriteFile is the <mxfile> that's written.  DrawIOXMLMXFile is
+// only good for reading because of its <object>s.
+type drawIOXMLWriteFile struct {
+	XMLName  xml.Name                `xml:"mxfile"`
+	Host     string                  `xml:"host,attr"`
+	Diagrams []drawIOXMLWriteDiagram `xml:"diagram"`
+}
+
+type drawIOXMLWriteDiagram struct {
+	ID    string `xml:"id,attr"`
+	Name  string `xml:"name,attr"`
+	Model struct {
+		Grid string `xml:"grid,attr"`
+		Root struct {
+			// Cells are drawIOXMLWriteCells and
+			// drawIOXMLWriteObjects.
+			Cells []interface{}
+		} `xml:"root"`
+	} `xml:"mxGraphModel"`
+}
+
+type drawIOXMLWriteCell struct {
+	XMLName  xml.Name `xml:"mxCell"`
+	ID       string   `xml:"id,attr,omitempty"`
+	Value    string   `xml:"value,attr,omitempty"`
+	Style    string   `xml:"style,attr,omitempty"`
+	Parent   string   `xml:"parent,attr,omitempty"`
+	Vertex   string   `xml:"vertex,attr,omitempty"`
+	Edge     string   `xml:"edge,attr,omitempty"`
+	Source   string   `xml:"source,attr,omitempty"`
+	Target   string   `xml:"target,attr,omitempty"`
+	Geometry *drawIOXMLWriteGeometry
+}
+
+type drawIOXMLWriteGeometry struct {
+	XMLName  xml.Name `xml:"mxGeometry"`
+	X        int      `xml:"x,attr,omitempty"`
+	Y        int      `xml:"y,attr,omitempty"`
+	Width    int      `xml:"width,attr,omitempty"`
+	Height   int      `xml:"height,attr,omitempty"`
+	Relative string   `xml:"relative,attr,omitempty"`
+	As       string   `xml:"as,attr"`
+}
+
+// drawIOXMLWriteObject wraps a table or column cell with its docs and
+// names.
+type drawIOXMLWriteObject struct {
+	XMLName   xml.Name `xml:"object"`
+	ID        string   `xml:"id,attr"`
+	Label     string   `xml:"label,attr"`
+	Tooltip   string   `xml:"tooltip,attr,omitempty"`
+	SQLName   string   `xml:"sqlName,attr,omitempty"`
+	ModelName string   `xml:"modelName,attr,omitempty"`
+	Cell      drawIOXMLWriteCell
+}
+
+// drawIOWritePage is a page of the diagram and its tables, grouped by the
+// containers that they go into.
+type drawIOWritePage struct {
+	name   string
+	groups []drawIOWriteGroup
+
+	// qualify gets the qualifier of placeholders of tables on other
+	// pages.  ok is false if the table is on this page.
+	qualify func(t *sqlstream.Table) (qualifier string, ok bool)
+}
+
+// drawIOWriteGroup is a container of tables.  Tables that aren't in a
+// container are in a group with no name.
+type drawIOWriteGroup struct {
+	name   string
+	tables []*sqlstream.Table
+}
+
+// drawIOWriter writes the cells of one page.  Arrows are kept apart from
+// the other cells because the cells they connect, including placeholders,
+// have to come first.
+type drawIOWriter struct {
+	layout   string
+	cells    []interface{}
+	arrows   []interface{}
+	nextID   int
+	colIDs   map[*sqlstream.Column]string
+	holders  map[*sqlstream.Table]*drawIOWritePlaceholder
+	holderTs []*sqlstream.Table
+}
+
+// drawIOWritePlaceholder is a table on another page that columns on this
+// page refer to.
+type drawIOWritePlaceholder struct {
+	name    string
+	columns []*sqlstream.Column
+}
+
+func (mc drawIOWriterModelContext) WriteMetaModel(w io.Writer, mm *sqlstream.MetaModel) error {
+	pages := mc.pagesOf(mm)
+	if len(pages) == 0 {
+		return errors.Errorf("at least one table is required")
+	}
+	f := drawIOXMLWriteFile{
+		Host:     "sqlmodelgen",
+		Diagrams: make([]drawIOXMLWriteDiagram, len(pages)),
+	}
+	for i, page := range pages {
+		d := &f.Diagrams[i]
+		d.ID = "page" + strconv.Itoa(i+1)
+		d.Name = page.name
+		d.Model.Grid = "1"
+		dw := drawIOWriter{
+			layout:  mc.layout,
+			colIDs:  make(map[*sqlstream.Column]string),
+			holders: make(map[*sqlstream.Table]*drawIOWritePlaceholder),
+		}
+		if err := dw.writePage(&page); err != nil {
+			return errors.Errorf1From(
+				err, "failed to write page %q", page.name,
+			)
+		}
+		d.Model.Root.Cells = append(dw.cells, dw.arrows...)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Errorf1From(err, "failed to write to %v", w)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(f); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write Draw.io XML to %v", w,
+		)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// drawIOSchemaPageName gets the name of a schema's page when pages are
+// schemas.  The unnamed schema's page is named drawIODefaultSchemaPage so
+// that placeholders of its tables can be qualified.
+func drawIOSchemaPageName(sch *sqlstream.Schema) string {
+	if sch.RawName == "" {
+		return drawIODefaultSchemaPage
+	}
+	return sch.RawName
+}
+
+// pagesOf groups the tables of a MetaModel into pages the same way that the
+// DrawIOModelContext reads them.
+func (mc drawIOWriterModelContext) pagesOf(mm *sqlstream.MetaModel) (pages []drawIOWritePage) {
+	for _, db := range mm.Databases {
+		if mc.pages == drawIOPagesDatabases {
+			page := drawIOWritePage{name: db.RawName}
+			for _, sch := range db.Schemas {
+				if len(sch.Tables) > 0 {
+					page.groups = append(page.groups, drawIOWriteGroup{
+						name:   sch.RawName,
+						tables: sch.Tables,
+					})
+				}
+			}
+			db := db
+			page.qualify = func(t *sqlstream.Table) (string, bool) {
+				switch {
+				case t.Schema.Database != db && t.Schema.RawName == "":
+					return t.Schema.Database.RawName, true
+				case t.Schema.Database != db:
+					return t.Schema.Database.RawName + "." + t.Schema.RawName, true
+				}
+				return "", false
+			}
+			if len(page.groups) > 0 {
+				pages = append(pages, page)
+			}
+			continue
+		}
+		for _, sch := range db.Schemas {
+			if len(sch.Tables) == 0 {
+				continue
+			}
+			sch := sch
+			pages = append(pages, drawIOWritePage{
+				name: drawIOSchemaPageName(sch),
+				groups: []drawIOWriteGroup{
+					{tables: sch.Tables},
+				},
+				qualify: func(t *sqlstream.Table) (string, bool) {
+					if t.Schema != sch {
+						return drawIOSchemaPageName(t.Schema), true
+					}
+					return "", false
+				},
+			})
+		}
+	}
+	return
+}
+
+func (dw *drawIOWriter) newID(prefix string) string {
+	dw.nextID++
+	return prefix + strconv.Itoa(dw.nextID)
+}
+
+func (dw *drawIOWriter) writePage(page *drawIOWritePage) error {
+	dw.cells = append(
+		dw.cells,
+		drawIOXMLWriteCell{ID: "0"},
+		drawIOXMLWriteCell{ID: "1", Parent: "0"},
+	)
+	x := drawIOPadding
+	for _, g := range page.groups {
+		parent, offsetY := "1", drawIOPadding
+		var container *drawIOXMLWriteCell
+		if g.name != "" {
+			container = &drawIOXMLWriteCell{
+				ID:     dw.newID("schema"),
+				Value:  g.name,
+				Style:  drawIOSchemaStyle,
+				Parent: "1",
+				Vertex: "1",
+			}
+			dw.cells = append(dw.cells, container)
+			parent, offsetY = container.ID, drawIORowHeight+drawIOPadding
+		}
+		positions, width, height := drawIOArrange(g.tables, dw.layout)
+		for i, t := range g.tables {
+			if container == nil {
+				positions[i][0] += x
+				positions[i][1] += offsetY
+			} else {
+				positions[i][0] += drawIOPadding
+				positions[i][1] += offsetY
+			}
+			dw.writeTable(t, parent, positions[i])
+		}
+		if container != nil {
+			width += 2 * drawIOPadding
+			height += offsetY + drawIOPadding
+			container.Geometry = &drawIOXMLWriteGeometry{
+				X: x, Y: drawIOPadding,
+				Width: width, Height: height,
+				As: "geometry",
+			}
+		}
+		x += width + drawIOTableGap
+	}
+	for _, g := range page.groups {
+		for _, t := range g.tables {
+			if err := dw.writeArrows(t, page); err != nil {
+				return errors.Errorf1From(
+					err, "failed to write arrows of table %v",
+					t.RawName,
+				)
+			}
+		}
+	}
+	// Placeholders are only known after the arrows that need them.
+	y := drawIOPadding
+	for _, t := range dw.holderTs {
+		h := dw.holders[t]
+		width := drawIOTableWidth(h.name, h.columns)
+		dw.cells = append(dw.cells, dw.tableCells(h.name, sqlstream.Names{}, "", h.columns, "1", [2]int{x, y}, width)...)
+		y += drawIORowHeight*(len(h.columns)+1) + drawIOTableGap
+	}
+	return nil
+}
+
+func (dw *drawIOWriter) writeTable(t *sqlstream.Table, parent string, pos [2]int) {
+	width := drawIOTableWidth(t.RawName, t.Columns)
+	dw.cells = append(dw.cells, dw.tableCells(t.RawName, t.Names, t.Doc, t.Columns, parent, pos, width)...)
+}
+
+// tableCells creates the cells of a table and its columns.  Placeholders
+// have no names besides their labels.
+func (dw *drawIOWriter) tableCells(name string, names sqlstream.Names, doc string, columns []*sqlstream.Column, parent string, pos [2]int, width int) []interface{} {
+	tableID := dw.newID("table")
+	cells := make([]interface{}, 0, len(columns)+1)
+	cells = append(cells, drawIOXMLWriteObject{
+		ID:        tableID,
+		Label:     name,
+		Tooltip:   doc,
+		SQLName:   names.SQLName,
+		ModelName: names.ModelName,
+		Cell: drawIOXMLWriteCell{
+			Style:  drawIOTableStyle,
+			Parent: parent,
+			Vertex: "1",
+			Geometry: &drawIOXMLWriteGeometry{
+				X: pos[0], Y: pos[1],
+				Width:  width,
+				Height: drawIORowHeight * (len(columns) + 1),
+				As:     "geometry",
+			},
+		},
+	})
+	for i, c := range columns {
+		colID, ok := dw.colIDs[c]
+		if !ok {
+			colID = dw.newID("column")
+			dw.colIDs[c] = colID
+		}
+		obj := drawIOXMLWriteObject{
+			ID:    colID,
+			Label: drawIOColumnLabel(c),
+			Cell: drawIOXMLWriteCell{
+				Style:  drawIOColumnStyle,
+				Parent: tableID,
+				Vertex: "1",
+				Geometry: &drawIOXMLWriteGeometry{
+					Y:      drawIORowHeight * (i + 1),
+					Width:  width,
+					Height: drawIORowHeight,
+					As:     "geometry",
+				},
+			},
+		}
+		if names.SQLName != "" {
+			// Placeholders only need the names of their
+			// columns.
+			obj.Tooltip, obj.SQLName, obj.ModelName = c.Doc, c.SQLName, c.ModelName
+		}
+		cells = append(cells, obj)
+	}
+	return cells
+}
+
+// writeArrows connects a table's foreign key columns to the columns that
+// they reference.
+func (dw *drawIOWriter) writeArrows(t *sqlstream.Table, page *drawIOWritePage) error {
+	for _, fk := range TableExtOf(t).ForeignKeys {
+		for i, c := range fk.Columns {
+			ref := fk.RefColumns[i]
+			refID, onPage := dw.colIDs[ref]
+			if q, ok := page.qualify(ref.Table); ok {
+				refID = dw.placeholderColumnID(q, ref)
+			} else if !onPage {
+				return errors.Errorf2(
+					"column %v references column %v which "+
+						"isn't on the page",
+					c.RawName, ref.RawName,
+				)
+			}
+			startArrow, endArrow := "ERzeroToMany", "ERmandOne"
+			if fk.OneToOne {
+				startArrow = "ERzeroToOne"
+			}
+			if _, ok := c.Type.(sqltypes.Nullable); ok {
+				endArrow = "ERzeroToOne"
+			}
+			dw.arrows = append(dw.arrows, drawIOXMLWriteCell{
+				ID: dw.newID("arrow"),
+				Style: drawIOArrowStyle +
+					"startArrow=" + startArrow + ";" +
+					"endArrow=" + endArrow + ";",
+				Parent: "1",
+				Edge:   "1",
+				Source: dw.colIDs[c],
+				Target: refID,
+				Geometry: &drawIOXMLWriteGeometry{
+					Relative: "1",
+					As:       "geometry",
+				},
+			})
+		}
+	}
+	return nil
+}
+
+// placeholderColumnID gets the ID of a column of a table on another page,
+// adding the placeholder and its column if they don't yet exist.  The
+// cells of placeholders are created after every arrow is written.
+func (dw *drawIOWriter) placeholderColumnID(qualifier string, c *sqlstream.Column) string {
+	h, ok := dw.holders[c.Table]
+	if !ok {
+		h = &drawIOWritePlaceholder{name: qualifier + "." + c.Table.RawName}
+		dw.holders[c.Table] = h
+		dw.holderTs = append(dw.holderTs, c.Table)
+	}
+	id, ok := dw.colIDs[c]
+	if !ok {
+		id = dw.newID("column")
+		dw.colIDs[c] = id
+		h.columns = append(h.columns, c)
+	}
+	return id
+}
+
+// drawIOColumnLabel gets a column's "name: type" label with a ": pk" suffix
+// if it's part of the primary key and a ": uk" suffix if it has a unique
+// index of its own.
+func drawIOColumnLabel(c *sqlstream.Column) string {
+	label := c.RawName
+	if c.Type != nil {
+		label += ": " + c.Type.String()
+	}
+	if c.PK {
+		label += ": pk"
+	}
+	for _, ix := range ColumnExtOf(c).Indexes {
+		if ix.Unique && len(ix.Columns) == 1 {
+			label += ": uk"
+			break
+		}
+	}
+	return label
+}
+
+func drawIOTableWidth(name string, columns []*sqlstream.Column) int {
+	n := len(name)
+	for _, c := range columns {
+		if m := len(drawIOColumnLabel(c)); m > n {
+			n = m
+		}
+	}
+	if w := n*drawIOCharWidth + 2*drawIOPadding; w > drawIOMinWidth {
+		return w
+	}
+	return drawIOMinWidth
+}
+
+// drawIOArrange gets the positions of tables, relative to the top left
+// corner of the area that they're arranged in, and the size of the area.
+func drawIOArrange(tables []*sqlstream.Table, layout string) (positions [][2]int, width, height int) {
+	columnsOf := make([]int, len(tables))
+	rowsOf := make([]int, len(tables))
+	switch layout {
+	case drawIOLayoutLayered:
+		layers := drawIOLayers(tables)
+		counts := make(map[int]int)
+		for i := range tables {
+			columnsOf[i] = layers[i]
+			rowsOf[i] = counts[layers[i]]
+			counts[layers[i]]++
+		}
+	default:
+		perRow := int(math.Ceil(math.Sqrt(float64(len(tables)))))
+		for i := range tables {
+			columnsOf[i], rowsOf[i] = i%perRow, i/perRow
+		}
+	}
+	colWidths := make(map[int]int)
+	rowHeights := make(map[int]int)
+	maxCol, maxRow := 0, 0
+	for i, t := range tables {
+		if w := drawIOTableWidth(t.RawName, t.Columns); w > colWidths[columnsOf[i]] {
+			colWidths[columnsOf[i]] = w
+		}
+		if h := drawIORowHeight * (len(t.Columns) + 1); h > rowHeights[rowsOf[i]] {
+			rowHeights[rowsOf[i]] = h
+		}
+		if columnsOf[i] > maxCol {
+			maxCol = columnsOf[i]
+		}
+		if rowsOf[i] > maxRow {
+			maxRow = rowsOf[i]
+		}
+	}
+	xs := make([]int, maxCol+2)
+	for c := 0; c <= maxCol; c++ {
+		xs[c+1] = xs[c] + colWidths[c] + drawIOTableGap
+	}
+	ys := make([]int, maxRow+2)
+	for r := 0; r <= maxRow; r++ {
+		ys[r+1] = ys[r] + rowHeights[r] + drawIOTableGap
+	}
+	positions = make([][2]int, len(tables))
+	for i := range tables {
+		positions[i] = [2]int{xs[columnsOf[i]], ys[rowsOf[i]]}
+	}
+	if len(tables) > 0 {
+		width, height = xs[maxCol+1]-drawIOTableGap, ys[maxRow+1]-drawIOTableGap
+	}
+	return
+}
+
+// drawIOLayers gets the layer of each table:  Tables that don't reference
+// any of the other tables are in layer 0 and every other table is in the
+// layer after the last layer of the tables that it references.  References
+// that form cycles are ignored.
+func drawIOLayers(tables []*sqlstream.Table) []int {
+	indexes := make(map[*sqlstream.Table]int, len(tables))
+	for i, t := range tables {
+		indexes[t] = i
+	}
+	const (
+		unvisited = iota - 2
+		visiting
+	)
+	layers := make([]int, len(tables))
+	for i := range layers {
+		layers[i] = unvisited
+	}
+	var layerOf func(i int) int
+	layerOf = func(i int) int {
+		switch layers[i] {
+		case unvisited:
+		case visiting:
+			return -1
+		default:
+			return layers[i]
+		}
+		layers[i] = visiting
+		layer := 0
+		for _, c := range tables[i].Columns {
+			if c.FK == nil {
+				continue
+			}
+			j, ok := indexes[c.FK.Column.Table]
+			if !ok || j == i {
+				continue
+			}
+			if l := layerOf(j) + 1; l > layer {
+				layer = l
+			}
+		}
+		layers[i] = layer
+		return layer
+	}
+	for i := range tables {
+		layerOf(i)
+	}
+	return layers
+}
@@ -1,6 +1,9 @@
 package sqlmodelgen
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 func englishPluralize(noun string) string {
 	if strings.HasSuffix(noun, "s") ||
@@ -10,3 +13,29 @@ func englishPluralize(noun string) string {
 	}
 	return noun + "s"
 }
+
+// identifierRawName splits an identifier into lower case words (e.g.
+// "PurchaseOrderID" and "purchase_order_id" both become "purchase order
+// id").
+func identifierRawName(ident string) string {
+	rs := []rune(ident)
+	sb := strings.Builder{}
+	space := false
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			space = sb.Len() > 0
+			continue
+		case i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) ||
+				(unicode.IsUpper(rs[i-1]) && i+1 < len(rs) && unicode.IsLower(rs[i+1]))):
+			space = sb.Len() > 0
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package sqlmodelgen
+
+import (
+	"strings"
+
+	"github.com/skillian/expr/stream/sqlstream"
+)
+
+// sqlCheckExpression gets the expression of a check constraint in the SQL
+// dialect with the given name.
+func sqlCheckExpression(dialectName string, ck *Check) string {
+	if ck.Enum == nil {
+		return ck.Expression
+	}
+	q := "\""
+	if dialectName == "mysql" {
+		q = "`"
+	}
+	values := make([]string, len(ck.Enum.Members))
+	for i, m := range ck.Enum.Members {
+		values[i] = m.SQLValue()
+	}
+	return q + ck.Column.SQLName + q + " IN (" + strings.Join(values, ", ") + ")"
+}
+
+// enumMemberOfDefault gets the member of a column's enumeration that is the
+// column's default value.  It returns nil if the column isn't an
+// enumeration or its default value isn't one of the enumeration's members.
+func enumMemberOfDefault(c *sqlstream.Column) *EnumMember {
+	ext := ColumnExtOf(c)
+	if ext.Enum == nil || ext.Default == "" {
+		return nil
+	}
+	d := parseSQLDefault(ext.Default)
+	switch d.Kind {
+	case sqlDefaultString, sqlDefaultNumber:
+	default:
+		return nil
+	}
+	for _, m := range ext.Enum.Members {
+		if m.Value == d.Value {
+			return m
+		}
+	}
+	return nil
+}
+
+// enumsNeedStrconv checks if any of the MetaModel's enumerations are ints.
+// The Scan methods generated for them parse []byte values with strconv.
+func enumsNeedStrconv(mm *sqlstream.MetaModel) bool {
+	for _, db := range mm.Databases {
+		for _, e := range DatabaseExtOf(db).Enums {
+			if !e.IsString() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasEnums checks if any of the MetaModel's databases have enumerations.
+func hasEnums(mm *sqlstream.MetaModel) bool {
+	for _, db := range mm.Databases {
+		if len(DatabaseExtOf(db).Enums) > 0 {
+			return true
+		}
+	}
+	return false
+}
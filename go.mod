@@ -16,4 +16,5 @@ require (
 	github.com/skillian/logging v0.0.0-20210425124543-4b3b9b919a80
 	github.com/skillian/textwrap v0.0.0-20190707153458-15c7ee8d44ed
 	github.com/xuri/excelize/v2 v2.4.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,69 @@
+package sqlmodelgen
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+var (
+	// goCommonFs holds the templates that the gosql and gomodels
+	// targets share, e.g. their enums.
+	//go:embed gocommon/*.txt
+	goCommonFs embed.FS
+
+	goCommonModelFs fs.FS = func() fs.FS {
+		fsys, err := fs.Sub(goCommonFs, "gocommon")
+		if err != nil {
+			panic(err)
+		}
+		return fsys
+	}()
+)
+
+// unionFS combines file systems into one.  A file in an earlier file
+// system hides the files with the same name in the later ones.
+type unionFS []fs.FS
+
+var _ fs.ReadDirFS = unionFS{}
+
+func (u unionFS) Open(name string) (fs.File, error) {
+	for _, fsys := range u {
+		f, err := fsys.Open(name)
+		if err == nil || !os.IsNotExist(err) {
+			return f, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (u unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	seen := make(map[string]struct{}, 8)
+	found := false
+	for _, fsys := range u {
+		des, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, de := range des {
+			if _, ok := seen[de.Name()]; ok {
+				continue
+			}
+			seen[de.Name()] = struct{}{}
+			entries = append(entries, de)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
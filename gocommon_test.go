@@ -0,0 +1,52 @@
+package sqlmodelgen
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestGoEnums(t *testing.T) {
+	mm := testMetaModel(t, `{
+	"databases": [{
+		"rawName": "shop",
+		"enums": [{
+			"rawName": "order status",
+			"members": [{"rawName": "pending", "value": 1}, {"rawName": "shipped"}]
+		}],
+		"schemas": [{
+			"tables": [{
+				"rawName": "order",
+				"columns": [
+					{"rawName": "order id", "type": "int(bits: 64)", "pk": true},
+					{"rawName": "status", "enum": "order status"}
+				]
+			}]
+		}]
+	}]
+}`)
+	for _, mc := range []ModelContext{GoSQLModelContext, GoModelsModelContext} {
+		src := testWrite(t, mc, mm)
+		for _, want := range []string{
+			"type OrderStatus int32",
+			"OrderStatusShipped OrderStatus = 2",
+			"func (e *OrderStatus) Scan(src interface{}) error {",
+		} {
+			if !strings.Contains(src, want) {
+				t.Fatalf("%T: missing %q in:\n%v", mc, want, src)
+			}
+		}
+	}
+}
+
+func TestUnionFS(t *testing.T) {
+	for _, fsys := range []interface{ FS() fs.FS }{GoSQLModelContext, GoModelsModelContext} {
+		names, err := fs.Glob(fsys.FS(), "*.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := strings.Join(names, " "), "0root.txt database.txt enum.txt table.txt"; got != want {
+			t.Fatalf("%T: got templates %v, want %v", fsys, got, want)
+		}
+	}
+}
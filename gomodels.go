@@ -5,8 +5,10 @@ import (
 	"io/fs"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
 )
 
@@ -26,7 +28,7 @@ var (
 		if err != nil {
 			panic(err)
 		}
-		return fsys
+		return unionFS{fsys, goCommonModelFs}
 	}()
 )
 
@@ -94,6 +96,23 @@ func (goModelsModelContext) ModelType(t sqltypes.Type) (namespace, typename stri
 	return "", "interface{}", nil
 }
 
+func (goModelsModelContext) EnsureNamespaces(c *sqlstream.MetaModel) (nss []string) {
+	if goDefaultsNeedTime(c) {
+		nss = append(nss, "time")
+	}
+	if hasEnums(c) {
+		nss = append(nss, "database/sql/driver", "fmt")
+		if enumsNeedStrconv(c) {
+			nss = append(nss, "strconv")
+		}
+	}
+	return
+}
+
+func (mc goModelsModelContext) AddFuncs(m template.FuncMap) {
+	m["godefaults"] = goDefaultsFunc(mc)
+}
+
 func (goModelsModelContext) OrganizeNamespaces(nss []string) []string {
 	stdlib := make([]string, 0, len(nss))
 	external := make([]string, 0, len(nss))
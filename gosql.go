@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream"
@@ -27,7 +28,7 @@ var (
 		if err != nil {
 			panic(err)
 		}
-		return fsys
+		return unionFS{fsys, goCommonModelFs}
 	}()
 )
 
@@ -109,9 +110,22 @@ dbLoop:
 			}
 		}
 	}
+	if goDefaultsNeedTime(c) {
+		nss = append(nss, "time")
+	}
+	if hasEnums(c) {
+		nss = append(nss, "database/sql/driver", "fmt")
+		if enumsNeedStrconv(c) {
+			nss = append(nss, "strconv")
+		}
+	}
 	return nss
 }
 
+func (mc goSQLModelContext) AddFuncs(m template.FuncMap) {
+	m["godefaults"] = goDefaultsFunc(mc)
+}
+
 func (goSQLModelContext) OrganizeNamespaces(nss []string) []string {
 	stdlib := make([]string, 0, len(nss))
 	external := make([]string, 0, len(nss))
@@ -0,0 +1,570 @@
+package sqlmodelgen
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// goStructsTag is the struct tag that holds a field's column name.
+	goStructsTag = "db"
+
+	// goStructsDatabaseParam is the name of the parameter that holds
+	// the raw name of the database.  If blank, the package name is
+	// used.
+	goStructsDatabaseParam = "database"
+)
+
+var (
+	// GoStructsModelContext reads the structs of a Go package whose
+	// fields have `db:"..."` tags into a model configuration.
+	GoStructsModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ParameterizedModelContext
+	} = goStructsModelContext{}
+)
+
+type goStructsModelContext struct {
+	database string
+}
+
+func (goStructsModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc goStructsModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[goStructsDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+// goStructsTable is a struct that is mapped to a table.
+type goStructsTable struct {
+	Name    string
+	SQLName string
+	Doc     string
+	Fields  []goStructsField
+
+	// pk is the index of the primary key field or -1 if the table
+	// has a composite key or no key at all.
+	pk int
+
+	typ types.Type
+}
+
+// goStructsField is a struct field that is mapped to a column.
+type goStructsField struct {
+	Name    string
+	Column  string
+	Options []string
+	Type    types.Type
+	Expr    ast.Expr
+	Doc     string
+	PK      bool
+}
+
+func (f *goStructsField) hasOption(opt string) bool {
+	for _, o := range f.Options {
+		if strings.EqualFold(o, opt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mc goStructsModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (cfg config.Config, err error) {
+	fset := token.NewFileSet()
+	files, err := goStructsParseFiles(fset, r)
+	if err != nil {
+		return cfg, err
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		// The package doesn't have to compile; types that can't be
+		// resolved fall back to their names in the source.
+		Error: func(err error) {
+			logger.Verbose1("type checking error: %v", err)
+		},
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+	}
+	_, _ = conf.Check(files[0].Name.Name, fset, files, info)
+	tables, err := goStructsTables(files, info)
+	if err != nil {
+		return cfg, err
+	}
+	dbName := mc.database
+	if dbName == "" {
+		dbName = files[0].Name.Name
+	}
+	cfg.Databases = []config.Database{
+		{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: dbName,
+				},
+			},
+			Schemas: []config.Schema{
+				{
+					Tables: make([]config.Table, 0, len(tables)),
+				},
+			},
+		},
+	}
+	sch := &cfg.Databases[0].Schemas[0]
+	tablesByName := make(map[string]*goStructsTable, len(tables))
+	for _, t := range tables {
+		tablesByName[t.Name] = t
+	}
+	for _, t := range tables {
+		tblCfg := config.Table{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: identifierRawName(t.Name),
+					SQLName: t.SQLName,
+				},
+				Doc: t.Doc,
+			},
+			Columns: make([]config.Column, 0, len(t.Fields)),
+		}
+		for i := range t.Fields {
+			f := &t.Fields[i]
+			colCfg := config.Column{
+				CommonData: config.CommonData{
+					Names: config.Names{
+						RawName: identifierRawName(f.Column),
+						SQLName: f.Column,
+					},
+					Doc: f.Doc,
+				},
+				PK: f.PK,
+			}
+			ref := goStructsReferencedTable(t, f, tablesByName)
+			if ref != nil && ref.pk == -1 {
+				logger.Warn(
+					"%v.%v refers to %v but it doesn't have "+
+						"a single primary key field",
+					t.Name, f.Name, ref.Name,
+				)
+				ref = nil
+			}
+			var typ sqltypes.Type
+			if ref != nil {
+				pk := &ref.Fields[ref.pk]
+				colCfg.FK = identifierRawName(ref.Name) + "." + identifierRawName(pk.Column)
+				if _, ok := f.Type.(*types.Pointer); ok {
+					// *Other fields hold the referenced
+					// table's primary key value and are only
+					// nullable when they're not part of this
+					// table's primary key.
+					typ, err = goStructsType(pk.Type, pk.Expr)
+					if err == nil && !f.PK {
+						typ = sqltypes.Nullable{typ}
+					}
+				}
+			}
+			if typ == nil && err == nil {
+				typ, err = goStructsType(f.Type, f.Expr)
+			}
+			if err != nil {
+				return cfg, errors.Errorf2From(
+					err, "failed to get the type of %v.%v",
+					t.Name, f.Name,
+				)
+			}
+			colCfg.Type = typ.String()
+			tblCfg.Columns = append(tblCfg.Columns, colCfg)
+		}
+		sch.Tables = append(sch.Tables, tblCfg)
+	}
+	return
+}
+
+// goStructsParseFiles parses the package of the file or directory that r
+// was opened from.  If r wasn't opened from a file, its contents are parsed
+// as a single file.
+func goStructsParseFiles(fset *token.FileSet, r io.Reader) ([]*ast.File, error) {
+	if nr, ok := r.(interface{ Name() string }); ok {
+		if fi, err := os.Stat(nr.Name()); err == nil {
+			return goStructsParseDir(fset, nr.Name(), fi.IsDir())
+		}
+	}
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to read all bytes from %v", r,
+		)
+	}
+	f, err := parser.ParseFile(fset, "models.go", bs, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf0From(err, "failed to parse Go source")
+	}
+	return []*ast.File{f}, nil
+}
+
+func goStructsParseDir(fset *token.FileSet, name string, isDir bool) ([]*ast.File, error) {
+	dir, pkgName := name, ""
+	if !isDir {
+		dir = filepath.Dir(name)
+		f, err := parser.ParseFile(fset, name, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, errors.Errorf1From(
+				err, "failed to parse %v", name,
+			)
+		}
+		pkgName = f.Name.Name
+	}
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Errorf1From(
+			err, "failed to parse package in %v", dir,
+		)
+	}
+	if pkgName == "" {
+		if len(pkgs) != 1 {
+			return nil, errors.Errorf2(
+				"expected 1 package in %v, not %d",
+				dir, len(pkgs),
+			)
+		}
+		for pkgName = range pkgs {
+		}
+	}
+	pkg, ok := pkgs[pkgName]
+	if !ok {
+		return nil, errors.Errorf2(
+			"no package %v in %v", pkgName, dir,
+		)
+	}
+	fileNames := make([]string, 0, len(pkg.Files))
+	for fileName := range pkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	files := make([]*ast.File, len(fileNames))
+	for i, fileName := range fileNames {
+		files[i] = pkg.Files[fileName]
+	}
+	return files, nil
+}
+
+// goStructsTables gets the structs with at least one db tag in the order
+// that they're declared.
+func goStructsTables(files []*ast.File, info *types.Info) (tables []*goStructsTable, err error) {
+	fieldExprs := make(map[token.Pos]*ast.Field)
+	embedded := make(map[types.Type]bool)
+	tableNames := make(map[string]string)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				for _, fld := range st.Fields.List {
+					for _, id := range fld.Names {
+						fieldExprs[id.Pos()] = fld
+					}
+					if len(fld.Names) == 0 {
+						fieldExprs[fld.Type.Pos()] = fld
+					}
+				}
+			}
+			return true
+		})
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				if recv, name, ok := goStructsTableNameMethod(fd); ok {
+					tableNames[recv] = name
+				}
+			}
+		}
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				obj, ok := info.Defs[ts.Name]
+				if !ok || obj == nil {
+					continue
+				}
+				st, ok := obj.Type().Underlying().(*types.Struct)
+				if !ok {
+					continue
+				}
+				t := &goStructsTable{
+					Name:    ts.Name.Name,
+					SQLName: tableNames[ts.Name.Name],
+					pk:      -1,
+					typ:     obj.Type(),
+				}
+				t.Fields = goStructsFields(st, fieldExprs, embedded, t.Fields)
+				if len(t.Fields) == 0 {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				t.Doc = strings.TrimSpace(doc.Text())
+				goStructsInitPK(t)
+				tables = append(tables, t)
+			}
+		}
+	}
+	// Structs that are only embedded into other structs aren't tables
+	// of their own.
+	n := 0
+	for _, t := range tables {
+		if !embedded[t.typ] {
+			tables[n] = t
+			n++
+		}
+	}
+	tables = tables[:n]
+	if len(tables) == 0 {
+		return nil, errors.Errorf1(
+			"no structs have fields with %q tags", goStructsTag,
+		)
+	}
+	return
+}
+
+// goStructsFields appends the tagged fields of st to fs.  The fields of
+// untagged embedded structs are included as if they were st's fields and
+// the embedded structs' types are added to embedded.
+func goStructsFields(st *types.Struct, fieldExprs map[token.Pos]*ast.Field, embedded map[types.Type]bool, fs []goStructsField) []goStructsField {
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag, ok := reflect.StructTag(st.Tag(i)).Lookup(goStructsTag)
+		if !ok {
+			if v.Embedded() {
+				t := v.Type()
+				if p, ok := t.(*types.Pointer); ok {
+					t = p.Elem()
+				}
+				if est, ok := t.Underlying().(*types.Struct); ok {
+					embedded[t] = true
+					fs = goStructsFields(est, fieldExprs, embedded, fs)
+				}
+			}
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" || !v.Exported() {
+			continue
+		}
+		f := goStructsField{
+			Name:    v.Name(),
+			Column:  parts[0],
+			Options: parts[1:],
+			Type:    v.Type(),
+		}
+		if f.Column == "" {
+			f.Column = strings.ToLower(f.Name)
+		}
+		if fld, ok := fieldExprs[v.Pos()]; ok {
+			f.Expr = fld.Type
+			doc := fld.Doc
+			if doc == nil {
+				doc = fld.Comment
+			}
+			f.Doc = strings.TrimSpace(doc.Text())
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
+// goStructsInitPK sets the primary key fields of a table:  Either the
+// fields with a "pk" tag option or else an ID or <Table>ID field.
+func goStructsInitPK(t *goStructsTable) {
+	pks := 0
+	for i := range t.Fields {
+		f := &t.Fields[i]
+		if f.hasOption("pk") || f.hasOption("primarykey") {
+			f.PK = true
+			pks++
+			t.pk = i
+		}
+	}
+	if pks == 0 {
+		for i := range t.Fields {
+			f := &t.Fields[i]
+			if prefix, ok := goStructsTrimID(f.Name); ok && (prefix == "" || prefix == t.Name) {
+				f.PK = true
+				pks++
+				t.pk = i
+				break
+			}
+		}
+	}
+	if pks != 1 {
+		t.pk = -1
+	}
+}
+
+// goStructsTrimID removes an "ID" or "Id" suffix from name.
+func goStructsTrimID(name string) (prefix string, ok bool) {
+	for _, suffix := range []string{"ID", "Id"} {
+		if strings.HasSuffix(name, suffix) {
+			return name[:len(name)-len(suffix)], true
+		}
+	}
+	return name, false
+}
+
+// goStructsReferencedTable gets the table that a field refers to:  Either
+// the table of a *Other field or the table named by the prefix of an
+// <Other>ID field.
+func goStructsReferencedTable(t *goStructsTable, f *goStructsField, tablesByName map[string]*goStructsTable) *goStructsTable {
+	if p, ok := f.Type.(*types.Pointer); ok {
+		if n, ok := p.Elem().(*types.Named); ok {
+			if ref, ok := tablesByName[n.Obj().Name()]; ok {
+				return ref
+			}
+		}
+	}
+	if prefix, ok := goStructsTrimID(f.Name); ok && prefix != t.Name {
+		return tablesByName[prefix]
+	}
+	return nil
+}
+
+// goStructsTableNameMethod checks if fd is a TableName method that returns
+// a string literal and if so, returns the receiver type's name and the
+// literal.
+func goStructsTableNameMethod(fd *ast.FuncDecl) (recv, name string, ok bool) {
+	if fd.Name.Name != "TableName" || fd.Recv == nil || len(fd.Recv.List) != 1 || fd.Body == nil || len(fd.Body.List) != 1 {
+		return
+	}
+	t := fd.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	id, ok := t.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	ret, ok := fd.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	if name, err := strconv.Unquote(lit.Value); err == nil {
+		return id.Name, name, true
+	}
+	return "", "", false
+}
+
+// goStructsNamedTypes are the types from other packages that map to
+// sqltypes.  They are keyed by package path and type name.
+var goStructsNamedTypes = map[string]sqltypes.Type{
+	"database/sql.NullBool":    sqltypes.Nullable{sqltypes.BoolType{}},
+	"database/sql.NullByte":    sqltypes.Nullable{sqltypes.IntType{Bits: 8}},
+	"database/sql.NullFloat64": sqltypes.Nullable{sqltypes.FloatType{Mantissa: 53}},
+	"database/sql.NullInt16":   sqltypes.Nullable{sqltypes.IntType{Bits: 16}},
+	"database/sql.NullInt32":   sqltypes.Nullable{sqltypes.IntType{Bits: 32}},
+	"database/sql.NullInt64":   sqltypes.Nullable{sqltypes.IntType{Bits: 64}},
+	"database/sql.NullString":  sqltypes.Nullable{sqltypes.StringType{Var: true}},
+	"database/sql.NullTime":    sqltypes.Nullable{sqltypes.TimeType{}},
+	"time.Time":                sqltypes.TimeType{},
+}
+
+// goStructsType maps a field's Go type to a sqltypes.Type.  If the type
+// couldn't be resolved, expr is used to look it up by name.
+func goStructsType(t types.Type, expr ast.Expr) (sqltypes.Type, error) {
+	switch t := t.(type) {
+	case *types.Pointer:
+		var elemExpr ast.Expr
+		if star, ok := expr.(*ast.StarExpr); ok {
+			elemExpr = star.X
+		}
+		et, err := goStructsType(t.Elem(), elemExpr)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := et.(sqltypes.Nullable); ok {
+			return et, nil
+		}
+		return sqltypes.Nullable{et}, nil
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil {
+			if st, ok := goStructsNamedTypes[pkg.Path()+"."+t.Obj().Name()]; ok {
+				return st, nil
+			}
+		}
+		return goStructsType(t.Underlying(), nil)
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return sqltypes.BytesType{Var: true}, nil
+		}
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Bool:
+			return sqltypes.BoolType{}, nil
+		case types.Int8, types.Uint8:
+			return sqltypes.IntType{Bits: 8}, nil
+		case types.Int16, types.Uint16:
+			return sqltypes.IntType{Bits: 16}, nil
+		case types.Int32, types.Uint32:
+			return sqltypes.IntType{Bits: 32}, nil
+		case types.Int, types.Uint, types.Int64, types.Uint64:
+			return sqltypes.IntType{Bits: 64}, nil
+		case types.Float32:
+			return sqltypes.FloatType{Mantissa: 24}, nil
+		case types.Float64:
+			return sqltypes.FloatType{Mantissa: 53}, nil
+		case types.String:
+			return sqltypes.StringType{Var: true}, nil
+		case types.Invalid:
+			if expr != nil {
+				return goStructsTypeOfExpr(expr)
+			}
+		}
+	}
+	return nil, errors.Errorf1("unsupported Go type: %v", t)
+}
+
+// goStructsTypeOfExpr maps the types in goStructsNamedTypes by their
+// names in the source when their packages couldn't be imported.
+func goStructsTypeOfExpr(expr ast.Expr) (sqltypes.Type, error) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			for k, st := range goStructsNamedTypes {
+				if strings.HasSuffix(k, "/"+pkg.Name+"."+sel.Sel.Name) ||
+					k == pkg.Name+"."+sel.Sel.Name {
+					return st, nil
+				}
+			}
+		}
+	}
+	return nil, errors.Errorf1(
+		"unsupported Go type: %v", types.ExprString(expr),
+	)
+}
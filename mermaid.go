@@ -0,0 +1,669 @@
+package sqlmodelgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// mermaidMarkdownParam is the name of the parameter that, when
+	// "true", wraps the diagram in a ```mermaid Markdown code block.
+	mermaidMarkdownParam = "markdown"
+
+	// mermaidDatabaseParam is the name of the parameter that holds the
+	// raw name of the database that a parsed diagram's tables go into.
+	mermaidDatabaseParam = "database"
+)
+
+var (
+	// MermaidModelContext writes models as a Mermaid erDiagram.
+	MermaidModelContext interface {
+		ModelContext
+		MetaModelWriter
+		ParameterizedModelContext
+	} = mermaidModelContext{}
+
+	// MermaidConfigParserModelContext reads a Mermaid erDiagram into a
+	// model configuration.
+	MermaidConfigParserModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = mermaidConfigParser{database: "main"}
+)
+
+type mermaidModelContext struct {
+	markdown bool
+}
+
+func (mermaidModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	typename, err = mermaidType(t)
+	return
+}
+
+func (mc mermaidModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s, ok := ps[mermaidMarkdownParam]; ok {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.Errorf1From(
+				err, "invalid %v parameter", mermaidMarkdownParam,
+			)
+		}
+		mc.markdown = b
+	}
+	return mc, nil
+}
+
+func (mc mermaidModelContext) WriteMetaModel(w io.Writer, mm *sqlstream.MetaModel) error {
+	names := mermaidEntityNames(mm)
+	var buf bytes.Buffer
+	if mc.markdown {
+		buf.WriteString("```mermaid\n")
+	}
+	buf.WriteString("erDiagram\n")
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				if err := mermaidWriteEntity(&buf, t, names[t]); err != nil {
+					return errors.Errorf1From(
+						err, "failed to write table %v",
+						t.RawName,
+					)
+				}
+			}
+		}
+	}
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				for _, fk := range TableExtOf(t).ForeignKeys {
+					mermaidWriteRelationship(&buf, fk, names)
+				}
+			}
+		}
+	}
+	if mc.markdown {
+		buf.WriteString("```\n")
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write Mermaid diagram to %v", w,
+		)
+	}
+	return nil
+}
+
+// mermaidEntityNames gets the entity names of the tables:  Their model
+// names, qualified with their schemas' model names if more than one table
+// has the same model name.
+func mermaidEntityNames(mm *sqlstream.MetaModel) map[*sqlstream.Table]string {
+	counts := make(map[string]int)
+	names := make(map[*sqlstream.Table]string)
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				names[t] = mermaidIdentifier(t.ModelName, t.RawName)
+				counts[names[t]]++
+			}
+		}
+	}
+	for t, name := range names {
+		if counts[name] > 1 && t.Schema.ModelName != "" {
+			names[t] = t.Schema.ModelName + "_" + name
+		}
+	}
+	return names
+}
+
+// mermaidIdentifier gets a name that Mermaid accepts without quotes.
+func mermaidIdentifier(modelName, rawName string) string {
+	if modelName != "" {
+		return modelName
+	}
+	return strings.ReplaceAll(rawName, " ", "_")
+}
+
+func mermaidWriteEntity(buf *bytes.Buffer, t *sqlstream.Table, name string) error {
+	for _, line := range strings.Split(strings.TrimSpace(t.Doc), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			buf.WriteString("\t%% ")
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString("\t")
+	buf.WriteString(name)
+	buf.WriteString(" {\n")
+	for _, c := range t.Columns {
+		typename, err := mermaidType(c.Type)
+		if err != nil {
+			return errors.Errorf1From(
+				err, "failed to get type of column %v", c.RawName,
+			)
+		}
+		buf.WriteString("\t\t")
+		buf.WriteString(typename)
+		buf.WriteByte(' ')
+		buf.WriteString(mermaidIdentifier(c.ModelName, c.RawName))
+		keys := make([]string, 0, 3)
+		if c.PK {
+			keys = append(keys, "PK")
+		}
+		if c.FK != nil || ColumnExtOf(c).ForeignKey != nil {
+			keys = append(keys, "FK")
+		}
+		for _, ix := range ColumnExtOf(c).Indexes {
+			if ix.Unique && len(ix.Columns) == 1 {
+				keys = append(keys, "UK")
+				break
+			}
+		}
+		if len(keys) > 0 {
+			buf.WriteByte(' ')
+			buf.WriteString(strings.Join(keys, ", "))
+		}
+		if doc := mermaidQuote(c.Doc); doc != "" {
+			buf.WriteString(` "`)
+			buf.WriteString(doc)
+			buf.WriteByte('"')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("\t}\n")
+	return nil
+}
+
+// mermaidWriteRelationship writes a foreign key as a relationship from the
+// referenced table to the referencing table.  Its label lists the raw
+// names of the foreign key's columns.
+func mermaidWriteRelationship(buf *bytes.Buffer, fk *ForeignKey, names map[*sqlstream.Table]string) {
+	refCard, card, line := "||", "o{", "--"
+	labels := make([]string, len(fk.Columns))
+	for i, c := range fk.Columns {
+		labels[i] = c.RawName
+		if _, ok := c.Type.(sqltypes.Nullable); ok {
+			refCard = "|o"
+		}
+		if !c.PK {
+			// non-identifying
+			line = ".."
+		}
+	}
+	if fk.OneToOne {
+		card = "o|"
+	}
+	buf.WriteString("\t")
+	buf.WriteString(names[fk.RefTable])
+	buf.WriteByte(' ')
+	buf.WriteString(refCard + line + card)
+	buf.WriteByte(' ')
+	buf.WriteString(names[fk.Table])
+	buf.WriteString(` : "`)
+	buf.WriteString(mermaidQuote(strings.Join(labels, ", ")))
+	buf.WriteString("\"\n")
+}
+
+// mermaidQuote makes s safe to put between Mermaid's double quotes, which
+// can't be escaped.
+func mermaidQuote(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, `"`, "'")
+}
+
+// mermaidType gets a column type's Mermaid attribute type.  Mermaid types
+// can't have spaces, colons or commas, so they're more like SQL types than
+// sqltypes.Type strings.
+func mermaidType(t sqltypes.Type) (string, error) {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		s, err := mermaidType(t[0])
+		if err != nil {
+			return "", err
+		}
+		return "nullable(" + s + ")", nil
+	case sqltypes.BoolType:
+		return "bool", nil
+	case sqltypes.IntType:
+		return "int" + strconv.Itoa(t.Bits), nil
+	case sqltypes.FloatType:
+		switch {
+		case t.Mantissa == 24:
+			return "float", nil
+		case t.Mantissa == 53 || t.Mantissa == 0:
+			return "double", nil
+		}
+		return "float(" + strconv.Itoa(t.Mantissa) + ")", nil
+	case sqltypes.DecimalType:
+		switch {
+		case t.Scale > 0:
+			return "decimal(" + strconv.Itoa(t.Prec) + "_" + strconv.Itoa(t.Scale) + ")", nil
+		case t.Prec > 0:
+			return "decimal(" + strconv.Itoa(t.Prec) + ")", nil
+		}
+		return "decimal", nil
+	case sqltypes.StringType:
+		switch {
+		case t.Length <= 0:
+			return "text", nil
+		case t.Var:
+			return "varchar(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "char(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.BytesType:
+		switch {
+		case t.Length <= 0:
+			return "blob", nil
+		case t.Var:
+			return "varbinary(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "binary(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.TimeType:
+		if t.Prec >= 24*time.Hour {
+			return "date", nil
+		}
+		return "datetime", nil
+	}
+	return "", errors.Errorf1(
+		"Unknown model type: %[1]v (type: %[1]T)",
+		t,
+	)
+}
+
+var mermaidTypePattern = regexp.MustCompile(`^([a-z]+)([0-9]*)(?:\(([0-9]+)(?:_([0-9]+))?\))?$`)
+
+// mermaidParseType parses the types that mermaidType writes.
+func mermaidParseType(s string) (sqltypes.Type, error) {
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "nullable(") && strings.HasSuffix(lower, ")") {
+		t, err := mermaidParseType(s[len("nullable(") : len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		return sqltypes.Nullable{t}, nil
+	}
+	m := mermaidTypePattern.FindStringSubmatch(lower)
+	if m == nil {
+		return nil, errors.Errorf1("invalid type: %q", s)
+	}
+	arg := func(i int) int {
+		n, _ := strconv.Atoi(m[i])
+		return n
+	}
+	switch m[1] + m[2] {
+	case "bool", "boolean":
+		return sqltypes.BoolType{}, nil
+	case "int8", "int16", "int32", "int64":
+		return sqltypes.IntType{Bits: arg(2)}, nil
+	case "int", "integer":
+		return sqltypes.IntType{Bits: 32}, nil
+	case "float":
+		if m[3] != "" {
+			return sqltypes.FloatType{Mantissa: arg(3)}, nil
+		}
+		return sqltypes.FloatType{Mantissa: 24}, nil
+	case "double":
+		return sqltypes.FloatType{Mantissa: 53}, nil
+	case "decimal", "numeric":
+		return sqltypes.DecimalType{Prec: arg(3), Scale: arg(4)}, nil
+	case "text", "string":
+		return sqltypes.StringType{Var: true, Length: arg(3)}, nil
+	case "varchar":
+		return sqltypes.StringType{Var: true, Length: arg(3)}, nil
+	case "char":
+		return sqltypes.StringType{Length: arg(3)}, nil
+	case "blob", "varbinary":
+		return sqltypes.BytesType{Var: true, Length: arg(3)}, nil
+	case "binary":
+		return sqltypes.BytesType{Length: arg(3)}, nil
+	case "date":
+		return sqltypes.TimeType{Prec: 24 * time.Hour}, nil
+	case "datetime", "timestamp":
+		return sqltypes.TimeType{}, nil
+	}
+	return nil, errors.Errorf1("unsupported type: %q", s)
+}
+
+type mermaidConfigParser struct {
+	database string
+}
+
+func (mermaidConfigParser) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc mermaidConfigParser) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[mermaidDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+// mermaidEntity is an entity parsed from an erDiagram.
+type mermaidEntity struct {
+	name       string
+	doc        string
+	attributes []mermaidAttribute
+}
+
+type mermaidAttribute struct {
+	typ  string
+	name string
+	keys []string
+	doc  string
+}
+
+func (a *mermaidAttribute) hasKey(k string) bool {
+	for _, key := range a.keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// mermaidRelationship is a relationship parsed from an erDiagram.
+type mermaidRelationship struct {
+	line                int
+	left, right         string
+	leftCard, rightCard string
+	label               string
+}
+
+var (
+	mermaidName       = `("[^"]*"|[A-Za-z_][A-Za-z0-9_-]*)`
+	mermaidEntityLine = regexp.MustCompile(
+		`^` + mermaidName + `(?:\s*\[\s*"([^"]*)"\s*\])?\s*\{\s*(\})?$`,
+	)
+	mermaidAttributeLine = regexp.MustCompile(
+		`^([A-Za-z_*][A-Za-z0-9_\-\[\]()]*)\s+([A-Za-z_*][A-Za-z0-9_\-\[\]()]*)` +
+			`((?:\s*,?\s*(?:PK|FK|UK))*)\s*(?:"([^"]*)")?$`,
+	)
+	mermaidRelationshipLine = regexp.MustCompile(
+		`^` + mermaidName + `\s*([|}][|o])(?:--|\.\.)([o|][|{])\s*` +
+			mermaidName + `\s*:\s*("[^"]*"|.*)$`,
+	)
+	mermaidEntityOnlyLine = regexp.MustCompile(`^` + mermaidName + `$`)
+)
+
+func (mc mermaidConfigParser) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := mc.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+func (mc mermaidConfigParser) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return cfg, errors.Errorf1From(
+			err, "failed to read all bytes from %v", r,
+		)
+	}
+	entities, rels, err := mermaidParse(bs)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Databases = []config.Database{
+		{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: mc.database,
+				},
+			},
+			Schemas: []config.Schema{
+				{
+					Tables: make([]config.Table, 0, len(entities)),
+				},
+			},
+		},
+	}
+	sch := &cfg.Databases[0].Schemas[0]
+	entitiesByName := make(map[string]*mermaidEntity, len(entities))
+	for _, e := range entities {
+		entitiesByName[e.name] = e
+		tbl := config.Table{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: identifierRawName(e.name),
+				},
+				Doc: e.doc,
+			},
+			Columns: make([]config.Column, len(e.attributes)),
+		}
+		for i, a := range e.attributes {
+			t, err := mermaidParseType(a.typ)
+			if err != nil {
+				return cfg, errors.Errorf2From(
+					err, "invalid type of %v.%v", e.name, a.name,
+				)
+			}
+			tbl.Columns[i] = config.Column{
+				CommonData: config.CommonData{
+					Names: config.Names{
+						RawName: identifierRawName(a.name),
+					},
+					Doc: a.doc,
+				},
+				Type: t.String(),
+				PK:   a.hasKey("PK"),
+			}
+			if a.hasKey("UK") {
+				x := cfg.Ext.Table(mc.database, "", tbl.RawName)
+				x.Indexes = append(x.Indexes, IndexConfig{
+					Unique:  true,
+					Columns: []IndexColumnConfig{{RawName: tbl.Columns[i].RawName}},
+				})
+			}
+		}
+		sch.Tables = append(sch.Tables, tbl)
+	}
+	for _, rel := range rels {
+		if err = mc.addRelationship(&cfg, entitiesByName, rel); err != nil {
+			return cfg, errors.Errorf1From(
+				err, "invalid relationship on line %d", rel.line,
+			)
+		}
+	}
+	return
+}
+
+// addRelationship sets the FKs of the columns of the "many" side of a
+// relationship (or the right side of a one-to-one relationship) to the
+// primary key columns of the other side.  The FK columns are the columns
+// named by the relationship's label or else the FK attributes that are
+// named like the other entity's primary key.
+func (mc mermaidConfigParser) addRelationship(cfg *Config, entitiesByName map[string]*mermaidEntity, rel mermaidRelationship) error {
+	leftOne := rel.leftCard == "||" || rel.leftCard == "|o"
+	rightOne := rel.rightCard == "||" || rel.rightCard == "o|"
+	refName, name, refCard, oneToOne := rel.left, rel.right, rel.leftCard, rightOne
+	switch {
+	case !leftOne && !rightOne:
+		return errors.Errorf2(
+			"%v and %v are many-to-many.  Please add an "+
+				"entity between them.",
+			rel.left, rel.right,
+		)
+	case !leftOne:
+		refName, name, refCard, oneToOne = rel.right, rel.left, rel.rightCard, false
+	}
+	ref, ok := entitiesByName[refName]
+	if !ok {
+		return errors.Errorf1("undefined entity: %v", refName)
+	}
+	e, ok := entitiesByName[name]
+	if !ok {
+		return errors.Errorf1("undefined entity: %v", name)
+	}
+	var pks []string
+	for _, a := range ref.attributes {
+		if a.hasKey("PK") {
+			pks = append(pks, identifierRawName(a.name))
+		}
+	}
+	if len(pks) == 0 {
+		return errors.Errorf1("%v has no primary key", ref.name)
+	}
+	cols := make([]string, 0, len(pks))
+	for _, label := range strings.Split(rel.label, ",") {
+		label = identifierRawName(strings.TrimSpace(label))
+		for _, a := range e.attributes {
+			if identifierRawName(a.name) == label {
+				cols = append(cols, label)
+				break
+			}
+		}
+	}
+	if len(cols) != len(pks) {
+		// Fall back to FK attributes named after the referenced
+		// entity's primary key with or without its name as a
+		// prefix (e.g. "customer id" or "id").
+		cols = cols[:0]
+		refRawName := identifierRawName(ref.name)
+		for _, pk := range pks {
+			for _, a := range e.attributes {
+				n := identifierRawName(a.name)
+				if a.hasKey("FK") && (n == pk || n == refRawName+" "+pk) {
+					cols = append(cols, n)
+					break
+				}
+			}
+		}
+	}
+	if len(cols) != len(pks) {
+		return errors.Errorf2(
+			"failed to find the columns of %v that reference %v",
+			name, refName,
+		)
+	}
+	tblRawName := identifierRawName(e.name)
+	tbl := drawIOTable(&cfg.Config, drawIOColumnPath{mc.database, "", tblRawName})
+	for i, colName := range cols {
+		col := drawIOColumn(&cfg.Config, drawIOColumnPath{mc.database, "", tblRawName, colName})
+		col.FK = identifierRawName(ref.name) + "." + pks[i]
+		var err error
+		if col.Type, err = nullableTypeString(col.Type, refCard == "|o" || refCard == "o|"); err != nil {
+			return err
+		}
+		if oneToOne {
+			cfg.Ext.Column(mc.database, "", tbl.RawName, colName).OneToOne = true
+		}
+	}
+	return nil
+}
+
+// mermaidParse parses the entities and relationships of an erDiagram.
+// Markdown code fences, front matter, titles and other statements are
+// skipped.
+func mermaidParse(bs []byte) (entities []*mermaidEntity, rels []mermaidRelationship, err error) {
+	sc := bufio.NewScanner(bytes.NewReader(bs))
+	var entity *mermaidEntity
+	var doc []string
+	lineNum, started, frontMatter := 0, false, false
+	getEntity := func(name string) *mermaidEntity {
+		name = strings.Trim(name, `"`)
+		for _, e := range entities {
+			if e.name == name {
+				return e
+			}
+		}
+		e := &mermaidEntity{name: name}
+		entities = append(entities, e)
+		return e
+	}
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "---" && !started:
+			frontMatter = !frontMatter
+			continue
+		case frontMatter || strings.HasPrefix(line, "```"):
+			continue
+		case strings.HasPrefix(line, "%%"):
+			doc = append(doc, strings.TrimSpace(line[2:]))
+			continue
+		case line == "":
+			continue
+		case !started:
+			started = line == "erDiagram"
+			continue
+		}
+		if entity != nil {
+			if line == "}" {
+				entity = nil
+				continue
+			}
+			m := mermaidAttributeLine.FindStringSubmatch(line)
+			if m == nil {
+				return nil, nil, errors.Errorf2(
+					"invalid attribute on line %d: %q",
+					lineNum, line,
+				)
+			}
+			a := mermaidAttribute{typ: m[1], name: m[2], doc: m[4]}
+			for _, k := range strings.FieldsFunc(m[3], func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			}) {
+				a.keys = append(a.keys, k)
+			}
+			entity.attributes = append(entity.attributes, a)
+			continue
+		}
+		if m := mermaidEntityLine.FindStringSubmatch(line); m != nil {
+			// Relationships refer to entities by their names,
+			// not their aliases (e.g. p in p["Person"]), so
+			// the alias is only kept as documentation.
+			entity = getEntity(m[1])
+			if m[2] != "" {
+				doc = append([]string{m[2]}, doc...)
+			}
+			if len(doc) > 0 {
+				entity.doc = strings.Join(doc, "\n")
+			}
+			if m[3] != "" {
+				entity = nil
+			}
+			doc = nil
+			continue
+		}
+		doc = nil
+		if m := mermaidRelationshipLine.FindStringSubmatch(line); m != nil {
+			left, right := getEntity(m[1]), getEntity(m[4])
+			rels = append(rels, mermaidRelationship{
+				line:      lineNum,
+				left:      left.name,
+				right:     right.name,
+				leftCard:  m[2],
+				rightCard: m[3],
+				label:     strings.Trim(m[5], `"`),
+			})
+			continue
+		}
+		if m := mermaidEntityOnlyLine.FindStringSubmatch(line); m != nil {
+			getEntity(m[1])
+			continue
+		}
+		// titles, directions, styles, etc.
+		logger.Verbose2("skipping line %d: %q", lineNum, line)
+	}
+	if err = sc.Err(); err != nil {
+		return nil, nil, errors.Errorf0From(err, "failed to read diagram")
+	}
+	if entity != nil {
+		return nil, nil, errors.Errorf1(
+			"entity %v is missing its closing brace", entity.name,
+		)
+	}
+	return
+}
@@ -0,0 +1,99 @@
+package sqlmodelgen
+
+import "testing"
+
+func TestMermaidParse(t *testing.T) {
+	testParseCases(t, MermaidConfigParserModelContext, []testParseCase{{
+		name: "entities and relationships",
+		src: "```mermaid\n" + `---
+title: Shop
+---
+erDiagram
+    %% is someone who buys things.
+    customer {
+        int32 customer_id PK
+        varchar(64) name
+        nullable(varchar(128)) email UK "where receipts go"
+    }
+    order {
+        int64 order_id PK
+        int32 customer_id FK
+        decimal(10_2) total
+    }
+    order_line {
+        int64 order_id PK, FK
+        int16 line_number PK
+    }
+    customer ||--o{ order : places
+    order ||..|{ order_line : "order_id"
+` + "```\n",
+		want: `Customer
+	CustomerId int32 pk
+	Name varchar(64)
+	Email nullable(varchar(128))
+	unique UX_Customer_Email (Email)
+Order
+	OrderId int64 pk
+	CustomerId int32
+	Total decimal(10, 2)
+	fk (CustomerId) Customer (CustomerId)
+OrderLine
+	OrderId int64 pk
+	LineNumber int16 pk
+	fk (OrderId) Order (OrderId)
+`,
+	}, {
+		name: "aliases",
+		src: `erDiagram
+    p["Person"] {
+        int id PK
+        string name
+    }
+    car {
+        int id PK
+        int p_id FK
+    }
+    p ||--o{ car : has
+`,
+		want: `P
+	Id int32 pk
+	Name varchar(0)
+Car
+	Id int32 pk
+	PId int32
+	fk (PId) P (Id)
+`,
+	}, {
+		name: "many-to-many",
+		src: `erDiagram
+    a {
+        int id PK
+    }
+    b {
+        int id PK
+    }
+    a }o--o{ b : has
+`,
+		err: "a and b are many-to-many",
+	}, {
+		name: "unsupported type",
+		src: `erDiagram
+    a {
+        geography location
+    }
+`,
+		err: `unsupported type: "geography"`,
+	}, {
+		name: "invalid attribute",
+		src: `erDiagram
+    a {
+        int
+    }
+`,
+		err: `invalid attribute on line 3: "int"`,
+	}, {
+		name: "missing closing brace",
+		src:  "erDiagram\n    a {\n        int id PK\n",
+		err:  "entity a is missing its closing brace",
+	}})
+}
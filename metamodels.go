@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/skillian/expr/errors"
@@ -20,7 +22,7 @@ func MetaModelFromJSON(r io.Reader) (*sqlstream.MetaModel, error) {
 		return nil, errors.Errorf1From(
 			err, "failed to load JSON from %v", r)
 	}
-	var j config.Config
+	var j Config
 	if err = json.Unmarshal(data, &j); err != nil {
 		return nil, errors.Errorf1From(
 			err, "failed to parse %q as JSON", string(data))
@@ -28,10 +30,13 @@ func MetaModelFromJSON(r io.Reader) (*sqlstream.MetaModel, error) {
 	return MetaModelFromConfig(j)
 }
 
-// MetaModelFromConfig transforms the acyclic config.Config into a MetaModel.
-func MetaModelFromConfig(c config.Config) (mm *sqlstream.MetaModel, err error) {
+// MetaModelFromConfig transforms the acyclic Config into a MetaModel.
+// The Config's extensions are linked to the MetaModel and can be retrieved
+// with MetaModelExtOf.
+func MetaModelFromConfig(c Config) (mm *sqlstream.MetaModel, err error) {
 	mm = &sqlstream.MetaModel{}
-	if err = (&metaModelBuilder{MetaModel: mm /*, ModelContext: mc*/}).init(&c); err != nil {
+	b := &metaModelBuilder{MetaModel: mm /*, ModelContext: mc*/}
+	if err = b.init(&c); err != nil {
 		js, err2 := json.MarshalIndent(c, "", "\t")
 		if err2 != nil {
 			js = []byte("(error!)")
@@ -47,6 +52,9 @@ func MetaModelFromConfig(c config.Config) (mm *sqlstream.MetaModel, err error) {
 			err, "failed to initialize configuration "+
 				"from JSON:\n\n%q", string(js))
 	}
+	metaModelExts.Lock()
+	metaModelExts.m[mm] = b.ext
+	metaModelExts.Unlock()
 	return
 }
 
@@ -54,6 +62,7 @@ type metaModelBuilder struct {
 	*sqlstream.MetaModel
 	//ModelContext
 	namespaces map[string]struct{}
+	ext        *MetaModelExt
 	caches     struct {
 		columns   []sqlstream.Column
 		tables    []sqlstream.Table
@@ -65,12 +74,16 @@ type metaModelBuilder struct {
 	}
 }
 
-func (b *metaModelBuilder) init(c *config.Config) (err error) {
+func (b *metaModelBuilder) init(c *Config) (err error) {
 	b.namespaces = make(map[string]struct{}, 8)
+	b.ext = newMetaModelExt()
 	tempIDs := make([]*sqlstream.TableID, 0, 16)
 	if err = b.MetaModel.DatabaseNamers.Init(&c.DatabaseNamers); err != nil {
 		return
 	}
+	if c.Config, err = addEnumLookupTables(c); err != nil {
+		return
+	}
 	//b.MetaModel.Namespace = c.Namespace
 	b.MetaModel.Databases = make([]*sqlstream.Database, 0, len(c.Databases))
 	b.MetaModel.DatabasesByName = make(map[string]*sqlstream.Database, len(c.Databases))
@@ -124,8 +137,12 @@ func (b *metaModelBuilder) init(c *config.Config) (err error) {
 			}
 		}
 	}
+	// Link up the extensions...
+	if err = b.initExt(c); err != nil {
+		return err
+	}
 	// Link up the FKs...
-	if err = b.iterDBSchemaTableColumn(c, func(x dbSchemaTableColumn) error {
+	if err = b.iterDBSchemaTableColumn(&c.Config, func(x dbSchemaTableColumn) error {
 		if x.colCfg.FK == "" {
 			return nil
 		}
@@ -171,8 +188,12 @@ func (b *metaModelBuilder) init(c *config.Config) (err error) {
 	}); err != nil {
 		return err
 	}
+	// Group the FKs into foreign keys...
+	if err = b.initForeignKeys(c); err != nil {
+		return err
+	}
 	// Create the DataColumns list for non FKs and PKs...
-	if err = b.iterDBSchemaTableColumn(c, func(x dbSchemaTableColumn) error {
+	if err = b.iterDBSchemaTableColumn(&c.Config, func(x dbSchemaTableColumn) error {
 		if x.column.PK {
 			return nil
 		}
@@ -194,6 +215,671 @@ func (b *metaModelBuilder) init(c *config.Config) (err error) {
 	return
 }
 
+// initExt links the Config's extensions to the MetaModel's tables and
+// columns.
+func (b *metaModelBuilder) initExt(c *Config) (err error) {
+	for _, dbCfg := range c.Databases {
+		db := b.MetaModel.DatabasesByName[dbCfg.RawName]
+		dbExt := &DatabaseExt{}
+		b.ext.Databases[db] = dbExt
+		enumsByName := make(map[string]*Enum)
+		if dbCfgExt := c.Ext.findDatabase(dbCfg.RawName); dbCfgExt != nil {
+			for i := range dbCfgExt.Enums {
+				e, err := b.newEnum(db, &dbCfgExt.Enums[i])
+				if err != nil {
+					return errors.ErrorfFrom(
+						err, "invalid enum at index %d "+
+							"of database %v",
+						i, dbCfg.RawName,
+					)
+				}
+				dbExt.Enums = append(dbExt.Enums, e)
+				enumsByName[e.RawName] = e
+			}
+		}
+		for _, schCfg := range dbCfg.Schemas {
+			schema := db.SchemasByName[schCfg.RawName]
+			for _, tblCfg := range schCfg.Tables {
+				table := schema.TablesByName[tblCfg.RawName]
+				tblExt := &TableExt{}
+				b.ext.Tables[table] = tblExt
+				tblCfgExt := c.Ext.findTable(dbCfg.RawName, schCfg.RawName, tblCfg.RawName)
+				if tblCfgExt != nil {
+					tblExt.RenamedFrom = tblCfgExt.RenamedFrom
+				}
+				for _, colCfg := range tblCfg.Columns {
+					column := table.ColumnsByName[colCfg.RawName]
+					colExt := &ColumnExt{}
+					b.ext.Columns[column] = colExt
+					x := c.Ext.findColumn(
+						dbCfg.RawName, schCfg.RawName,
+						tblCfg.RawName, colCfg.RawName,
+					)
+					if x == nil {
+						continue
+					}
+					colExt.RenamedFrom = x.RenamedFrom
+					colExt.Generated = x.Generated
+					colExt.Default = x.Default
+					colExt.oneToOne = x.OneToOne
+					if x.Check != "" {
+						tblExt.Checks = append(tblExt.Checks, &Check{
+							Name:       "CK_" + table.SQLName + "_" + column.SQLName,
+							Table:      table,
+							Expression: x.Check,
+							Column:     column,
+						})
+					}
+					if x.Enum == "" {
+						continue
+					}
+					e, ok := enumsByName[x.Enum]
+					if !ok {
+						return errors.Errorf(
+							"column %v.%v.%v.%v references "+
+								"undefined enum %q",
+							dbCfg.RawName, schCfg.RawName,
+							tblCfg.RawName, colCfg.RawName,
+							x.Enum,
+						)
+					}
+					colExt.Enum = e
+					if column.Type == nil {
+						column.Type = e.Type
+					}
+					// Columns of enums with lookup tables are
+					// constrained by foreign keys instead and
+					// enums without members (e.g. data sets read
+					// from an ACE workbook) don't constrain
+					// anything yet.
+					if e.LookupTable == nil && len(e.Members) > 0 {
+						tblExt.Checks = append(tblExt.Checks, &Check{
+							Name: "CK_" + table.SQLName + "_" +
+								column.SQLName + "_" + e.SQLName,
+							Table:  table,
+							Column: column,
+							Enum:   e,
+						})
+					}
+				}
+				if tblCfgExt == nil {
+					continue
+				}
+				for i := range tblCfgExt.Indexes {
+					ix, err := b.newIndex(table, &tblCfgExt.Indexes[i])
+					if err != nil {
+						return errors.ErrorfFrom(
+							err, "invalid index at index %d "+
+								"of table %v.%v.%v",
+							i, dbCfg.RawName, schCfg.RawName,
+							tblCfg.RawName,
+						)
+					}
+					tblExt.Indexes = append(tblExt.Indexes, ix)
+				}
+				for i, ckCfg := range tblCfgExt.Checks {
+					if ckCfg.Expression == "" {
+						return errors.Errorf(
+							"check at index %d of table %v.%v.%v "+
+								"has no expression",
+							i, dbCfg.RawName, schCfg.RawName,
+							tblCfg.RawName,
+						)
+					}
+					ck := &Check{
+						Name:       ckCfg.Name,
+						Table:      table,
+						Expression: ckCfg.Expression,
+					}
+					if ck.Name == "" {
+						ck.Name = "CK_" + table.SQLName + "_" + strconv.Itoa(i+1)
+					}
+					tblExt.Checks = append(tblExt.Checks, ck)
+				}
+			}
+		}
+		for _, e := range dbExt.Enums {
+			if e.LookupTable != nil {
+				b.ext.Tables[e.LookupTable].Enum = e
+			}
+		}
+	}
+	return nil
+}
+
+// newEnum creates an Enum from its configuration.  Its lookup table, if it
+// has one, must have already been added by addEnumLookupTables.
+func (b *metaModelBuilder) newEnum(db *sqlstream.Database, cfg *EnumConfig) (*Enum, error) {
+	e := &Enum{
+		Doc:      cfg.Doc,
+		Database: db,
+		Members:  make([]*EnumMember, len(cfg.Members)),
+	}
+	e.Names.InitFromConfig(cfg.Names, &db.Namers.Table)
+	var err error
+	if e.Type, err = sqltypes.Parse(enumConfigType(cfg)); err != nil {
+		return nil, errors.Errorf1From(
+			err, "enum %q has an invalid type", cfg.RawName,
+		)
+	}
+	switch e.Type.(type) {
+	case sqltypes.IntType, sqltypes.StringType:
+	default:
+		return nil, errors.Errorf2(
+			"enum %q must be an int or a string, not %v",
+			cfg.RawName, e.Type,
+		)
+	}
+	next := int64(0)
+	for i := range cfg.Members {
+		mCfg := &cfg.Members[i]
+		m := &EnumMember{Doc: mCfg.Doc, Enum: e}
+		m.Names.InitFromConfig(mCfg.Names, &db.Namers.Column)
+		if e.IsString() {
+			m.Value = mCfg.RawName
+			if len(mCfg.Value) > 0 {
+				if err = json.Unmarshal(mCfg.Value, &m.Value); err != nil {
+					return nil, errors.Errorf1From(
+						err, "value of member %q must "+
+							"be a string", mCfg.RawName,
+					)
+				}
+			}
+		} else {
+			if len(mCfg.Value) > 0 {
+				if err = json.Unmarshal(mCfg.Value, &next); err != nil {
+					return nil, errors.Errorf1From(
+						err, "value of member %q must "+
+							"be an integer", mCfg.RawName,
+					)
+				}
+			}
+			m.Value = strconv.FormatInt(next, 10)
+			next++
+		}
+		e.Members[i] = m
+	}
+	if cfg.LookupTable != "" {
+		schName := cfg.Schema
+		if schName == "" {
+			schName = db.Schemas[0].RawName
+		}
+		e.LookupTable = db.SchemasByName[schName].TablesByName[cfg.LookupTable]
+	}
+	return e, nil
+}
+
+// enumConfigType gets the underlying type of an enumeration.
+func enumConfigType(cfg *EnumConfig) string {
+	if cfg.Type == "" {
+		return "int(bits: 32)"
+	}
+	return cfg.Type
+}
+
+// addEnumLookupTables adds the enumerations' lookup tables to a copy of
+// the Config's databases so that they are built like any other table.
+func addEnumLookupTables(c *Config) (config.Config, error) {
+	res := c.Config
+	copied := false
+	for i := range res.Databases {
+		x := c.Ext.findDatabase(res.Databases[i].RawName)
+		if x == nil {
+			continue
+		}
+		for _, e := range x.Enums {
+			if e.LookupTable == "" {
+				continue
+			}
+			if !copied {
+				res.Databases = append([]config.Database(nil), res.Databases...)
+				copied = true
+			}
+			db := &res.Databases[i]
+			if len(db.Schemas) == 0 {
+				return res, errors.Errorf2(
+					"database %v has no schema for the "+
+						"lookup table of enum %q",
+					db.RawName, e.RawName,
+				)
+			}
+			db.Schemas = append([]config.Schema(nil), db.Schemas...)
+			sch := &db.Schemas[0]
+			if e.Schema != "" {
+				sch = nil
+				for j := range db.Schemas {
+					if db.Schemas[j].RawName == e.Schema {
+						sch = &db.Schemas[j]
+						break
+					}
+				}
+				if sch == nil {
+					return res, errors.Errorf2(
+						"enum %q lookup table schema %q "+
+							"does not exist",
+						e.RawName, e.Schema,
+					)
+				}
+			}
+			for _, t := range sch.Tables {
+				if t.RawName == e.LookupTable {
+					return res, errors.Errorf2(
+						"enum %q lookup table %q already "+
+							"exists",
+						e.RawName, e.LookupTable,
+					)
+				}
+			}
+			tables := make([]config.Table, len(sch.Tables), len(sch.Tables)+1)
+			copy(tables, sch.Tables)
+			sch.Tables = append(tables, config.Table{
+				CommonData: config.CommonData{
+					Names: config.Names{RawName: e.LookupTable},
+					Doc:   e.Doc,
+				},
+				Columns: []config.Column{
+					{
+						CommonData: config.CommonData{
+							Names: config.Names{RawName: "value"},
+						},
+						Type: enumConfigType(&e),
+						PK:   true,
+					},
+					{
+						CommonData: config.CommonData{
+							Names: config.Names{RawName: "name"},
+						},
+						Type: "string(var: true, length: 128)",
+					},
+				},
+			})
+		}
+	}
+	return res, nil
+}
+
+func (b *metaModelBuilder) newIndex(t *sqlstream.Table, cfg *IndexConfig) (*Index, error) {
+	if len(cfg.Columns) == 0 {
+		return nil, errors.Errorf("index has no columns")
+	}
+	ix := &Index{
+		Name:    cfg.Name,
+		Unique:  cfg.Unique,
+		Table:   t,
+		Columns: make([]IndexColumn, len(cfg.Columns)),
+		Include: make([]*sqlstream.Column, len(cfg.Include)),
+		Where:   cfg.Where,
+	}
+	for i, colCfg := range cfg.Columns {
+		col, ok := t.ColumnsByName[colCfg.RawName]
+		if !ok {
+			return nil, errors.Errorf1("no column %q", colCfg.RawName)
+		}
+		ix.Columns[i].Column = col
+		switch strings.ToLower(colCfg.Order) {
+		case "", "asc":
+		case "desc":
+			ix.Columns[i].Descending = true
+		default:
+			return nil, errors.Errorf2(
+				"invalid order %q of column %q",
+				colCfg.Order, colCfg.RawName,
+			)
+		}
+	}
+	for i, name := range cfg.Include {
+		col, ok := t.ColumnsByName[name]
+		if !ok {
+			return nil, errors.Errorf1("no column %q", name)
+		}
+		ix.Include[i] = col
+	}
+	if ix.Name == "" {
+		ix.Name = defaultIndexName(ix)
+	}
+	for _, c := range ix.Columns {
+		colExt := b.ext.Columns[c.Column]
+		colExt.Indexes = append(colExt.Indexes, ix)
+	}
+	return ix, nil
+}
+
+// defaultIndexName gets the name of an index that isn't given one (e.g.
+// "UX_Customer_Email").  Writers that can leave out names that are the
+// default compare against it.
+func defaultIndexName(ix *Index) string {
+	parts := make([]string, 0, 2+len(ix.Columns))
+	if ix.Unique {
+		parts = append(parts, "UX")
+	} else {
+		parts = append(parts, "IX")
+	}
+	parts = append(parts, ix.Table.SQLName)
+	for _, c := range ix.Columns {
+		parts = append(parts, c.SQLName)
+	}
+	return strings.Join(parts, "_")
+}
+
+// initForeignKeys links the foreign keys declared in the Config's
+// extensions and infers the rest from the columns' FKs.
+func (b *metaModelBuilder) initForeignKeys(c *Config) (err error) {
+	for _, dbCfg := range c.Databases {
+		db := b.MetaModel.DatabasesByName[dbCfg.RawName]
+		for _, schCfg := range dbCfg.Schemas {
+			schema := db.SchemasByName[schCfg.RawName]
+			for _, tblCfg := range schCfg.Tables {
+				table := schema.TablesByName[tblCfg.RawName]
+				var fkCfgs []ForeignKeyConfig
+				if x := c.Ext.findTable(dbCfg.RawName, schCfg.RawName, tblCfg.RawName); x != nil {
+					fkCfgs = x.ForeignKeys
+				}
+				if err = b.initTableForeignKeys(table, fkCfgs); err != nil {
+					return errors.ErrorfFrom(
+						err, "failed to initialize foreign keys "+
+							"of table %v.%v.%v",
+						dbCfg.RawName, schCfg.RawName, tblCfg.RawName,
+					)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (b *metaModelBuilder) initTableForeignKeys(t *sqlstream.Table, fkCfgs []ForeignKeyConfig) error {
+	tblExt := b.ext.Tables[t]
+	for i := range fkCfgs {
+		fk, err := b.newDeclaredForeignKey(t, &fkCfgs[i])
+		if err != nil {
+			return errors.Errorf1From(
+				err, "invalid foreign key at index %d", i,
+			)
+		}
+		tblExt.ForeignKeys = append(tblExt.ForeignKeys, fk)
+	}
+	for _, col := range t.Columns {
+		colExt := b.ext.Columns[col]
+		if colExt.Enum == nil || colExt.Enum.LookupTable == nil ||
+			col.FK != nil || colExt.ForeignKey != nil {
+			continue
+		}
+		lt := colExt.Enum.LookupTable
+		tblExt.ForeignKeys = append(
+			tblExt.ForeignKeys,
+			b.newForeignKey(
+				t, []*sqlstream.Column{col},
+				[]*sqlstream.Column{lt.PK.Column}, "", "",
+			),
+		)
+	}
+	// Columns that reference the IDs of the same composite key are
+	// grouped into one foreign key if they reference each ID exactly
+	// once.  Otherwise, each column is its own foreign key.
+	groups := make(map[*sqlstream.TableKey][]*sqlstream.Column)
+	for _, col := range t.Columns {
+		if col.FK == nil || b.ext.Columns[col].ForeignKey != nil {
+			continue
+		}
+		if key := col.FK.Column.Table.Key; key != nil {
+			groups[key] = append(groups[key], col)
+		}
+	}
+	for _, col := range t.Columns {
+		if col.FK == nil || b.ext.Columns[col].ForeignKey != nil {
+			continue
+		}
+		key := col.FK.Column.Table.Key
+		if cols := groups[key]; key != nil && len(cols) == len(key.IDs) {
+			ordered := make([]*sqlstream.Column, len(key.IDs))
+			for i, id := range key.IDs {
+				for _, c := range cols {
+					if c.FK == id {
+						ordered[i] = c
+						break
+					}
+				}
+				if ordered[i] == nil {
+					ordered = nil
+					break
+				}
+			}
+			if ordered != nil {
+				refCols := make([]*sqlstream.Column, len(key.IDs))
+				for i, id := range key.IDs {
+					refCols[i] = id.Column
+				}
+				tblExt.ForeignKeys = append(
+					tblExt.ForeignKeys,
+					b.newForeignKey(t, ordered, refCols, "", ""),
+				)
+				continue
+			}
+		}
+		tblExt.ForeignKeys = append(
+			tblExt.ForeignKeys,
+			b.newForeignKey(
+				t, []*sqlstream.Column{col},
+				[]*sqlstream.Column{col.FK.Column}, "", "",
+			),
+		)
+	}
+	for _, fk := range tblExt.ForeignKeys {
+		for _, col := range fk.Columns {
+			if b.ext.Columns[col].oneToOne {
+				fk.OneToOne = true
+			}
+		}
+	}
+	if err := uniqueForeignKeyModelNames(tblExt.ForeignKeys); err != nil {
+		return err
+	}
+	sort.SliceStable(tblExt.ForeignKeys, func(i, j int) bool {
+		return columnIndex(t, tblExt.ForeignKeys[i].Columns[0]) <
+			columnIndex(t, tblExt.ForeignKeys[j].Columns[0])
+	})
+	return nil
+}
+
+// newDeclaredForeignKey resolves a ForeignKeyConfig.  Columns that are not
+// yet linked to the IDs they reference are linked so that declared
+// foreign keys don't also need their columns' FKs.
+func (b *metaModelBuilder) newDeclaredForeignKey(t *sqlstream.Table, cfg *ForeignKeyConfig) (*ForeignKey, error) {
+	if len(cfg.Columns) == 0 {
+		return nil, errors.Errorf("foreign key has no columns")
+	}
+	refTable, err := b.getTableUp(cfg.References, t)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]*sqlstream.Column, len(cfg.Columns))
+	for i, name := range cfg.Columns {
+		col, ok := t.ColumnsByName[name]
+		if !ok {
+			return nil, errors.Errorf1("no column %q", name)
+		}
+		if b.ext.Columns[col].ForeignKey != nil {
+			return nil, errors.Errorf1(
+				"column %q is already part of a foreign key",
+				name,
+			)
+		}
+		cols[i] = col
+	}
+	var refCols []*sqlstream.Column
+	if len(cfg.ReferencedColumns) == 0 {
+		switch {
+		case refTable.PK != nil:
+			refCols = []*sqlstream.Column{refTable.PK.Column}
+		case refTable.Key != nil:
+			refCols = make([]*sqlstream.Column, len(refTable.Key.IDs))
+			for i, id := range refTable.Key.IDs {
+				refCols[i] = id.Column
+			}
+		default:
+			return nil, errors.Errorf1(
+				"referenced table %q has no key",
+				refTable.RawName,
+			)
+		}
+	} else {
+		refCols = make([]*sqlstream.Column, len(cfg.ReferencedColumns))
+		for i, name := range cfg.ReferencedColumns {
+			col, ok := refTable.ColumnsByName[name]
+			if !ok {
+				return nil, errors.Errorf2(
+					"referenced table %q has no column %q",
+					refTable.RawName, name,
+				)
+			}
+			refCols[i] = col
+		}
+	}
+	if len(cols) != len(refCols) {
+		return nil, errors.Errorf2(
+			"foreign key has %d columns but references %d",
+			len(cols), len(refCols),
+		)
+	}
+	for i, col := range cols {
+		if col.FK != nil {
+			continue
+		}
+		id := tableIDOf(refCols[i])
+		if id == nil {
+			continue
+		}
+		col.FK = id
+		id.Column.FKCols = append(id.Column.FKCols, col)
+		if col.Type == nil {
+			col.Type = id.Column.Type
+		}
+	}
+	fk := b.newForeignKey(t, cols, refCols, cfg.Name, cfg.ModelName)
+	fk.OneToOne = cfg.OneToOne
+	return fk, nil
+}
+
+func (b *metaModelBuilder) newForeignKey(t *sqlstream.Table, cols, refCols []*sqlstream.Column, name, modelName string) *ForeignKey {
+	fk := &ForeignKey{
+		Name:       name,
+		ModelName:  modelName,
+		Table:      t,
+		Columns:    cols,
+		RefTable:   refCols[0].Table,
+		RefColumns: refCols,
+	}
+	if key := fk.RefTable.Key; key != nil && len(key.IDs) == len(refCols) {
+		fk.RefKey = key
+		for i, id := range key.IDs {
+			if id.Column != refCols[i] {
+				fk.RefKey = nil
+				break
+			}
+		}
+	}
+	if fk.Name == "" {
+		fk.Name = defaultForeignKeyName(fk)
+	}
+	if fk.ModelName == "" {
+		if fk.RefKey != nil {
+			fk.ModelName = fk.RefKey.ModelName
+			fk.keyModelName = true
+		} else {
+			fk.ModelName = cols[0].ModelName
+		}
+	}
+	for _, c := range cols {
+		b.ext.Columns[c].ForeignKey = fk
+	}
+	return fk
+}
+
+// defaultForeignKeyName gets the name of a foreign key that isn't given one
+// (e.g. "FK_Order_CustomerId_Customer_CustomerId").  The name of
+// single-column foreign keys must not change because the constraints that
+// existing databases were created with have it.
+func defaultForeignKeyName(fk *ForeignKey) string {
+	parts := make([]string, 0, 2+len(fk.Columns)+len(fk.RefColumns))
+	parts = append(parts, "FK", fk.Table.SQLName)
+	for _, c := range fk.Columns {
+		parts = append(parts, c.SQLName)
+	}
+	parts = append(parts, fk.RefTable.SQLName)
+	for _, c := range fk.RefColumns {
+		parts = append(parts, c.SQLName)
+	}
+	return strings.Join(parts, "_")
+}
+
+// uniqueForeignKeyModelNames makes sure that a table's foreign keys to the
+// same composite key don't all get the key's model name.  The model names
+// of foreign keys whose columns' model names all have the same prefix
+// before their referenced columns' model names (e.g. "BillingAddressID"
+// referencing "AddressID") get the prefix (e.g. "BillingAddressKey").
+func uniqueForeignKeyModelNames(fks []*ForeignKey) error {
+	counts := make(map[string]int, len(fks))
+	for _, fk := range fks {
+		counts[fk.ModelName]++
+	}
+	for _, fk := range fks {
+		if !fk.keyModelName || counts[fk.ModelName] < 2 {
+			continue
+		}
+		prefix := ""
+		for i, c := range fk.Columns {
+			p := strings.TrimSuffix(c.ModelName, fk.RefColumns[i].ModelName)
+			if p == c.ModelName || (i > 0 && p != prefix) {
+				prefix = ""
+				break
+			}
+			prefix = p
+		}
+		if prefix == "" {
+			continue
+		}
+		counts[fk.ModelName]--
+		fk.ModelName = prefix + fk.ModelName
+		counts[fk.ModelName]++
+	}
+	for _, fk := range fks {
+		if counts[fk.ModelName] > 1 && fk.keyModelName {
+			return errors.Errorf2(
+				"more than one foreign key to %v would be named "+
+					"%v.  Please give them each a modelName",
+				fk.RefTable.RawName, fk.ModelName,
+			)
+		}
+	}
+	return nil
+}
+
+// tableIDOf gets the TableID of a primary key or composite key column or
+// nil if the column is not part of its table's key.
+func tableIDOf(c *sqlstream.Column) *sqlstream.TableID {
+	t := c.Table
+	if t.PK != nil && t.PK.Column == c {
+		return t.PK
+	}
+	if t.Key != nil {
+		for _, id := range t.Key.IDs {
+			if id.Column == c {
+				return id
+			}
+		}
+	}
+	return nil
+}
+
+func columnIndex(t *sqlstream.Table, c *sqlstream.Column) int {
+	for i, x := range t.Columns {
+		if x == c {
+			return i
+		}
+	}
+	return -1
+}
+
 type dbSchemaTableColumn struct {
 	dbName  string
 	dbCfg   config.Database
@@ -246,11 +932,36 @@ func (b *metaModelBuilder) getPathUp(path string, start *sqlstream.Table) (inter
 	case 3:
 		root = start.Schema.Database.MetaModel
 	default:
-		return nil, errors.Errorf("%q does not seem to be a path")
+		return nil, errors.Errorf1("%q does not seem to be a path", path)
 	}
 	return b.getPathDown(path, root)
 }
 
+// getTableUp is like getPathUp but for paths to tables instead of
+// columns.
+func (b *metaModelBuilder) getTableUp(path string, start *sqlstream.Table) (*sqlstream.Table, error) {
+	var root interface{}
+	switch strings.Count(path, ".") {
+	case 0:
+		root = start.Schema
+	case 1:
+		root = start.Schema.Database
+	case 2:
+		root = start.Schema.Database.MetaModel
+	default:
+		return nil, errors.Errorf1("%q does not seem to be a path", path)
+	}
+	trg, err := b.getPathDown(path, root)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := trg.(*sqlstream.Table)
+	if !ok {
+		return nil, errors.Errorf1("%q is not a table", path)
+	}
+	return t, nil
+}
+
 func (b *metaModelBuilder) getPathDown(path string, start interface{}) (interface{}, error) {
 	parts := strings.Split(path, ".")
 	hop := start
@@ -0,0 +1,282 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// testModelJSON is the model that the writers' output is parsed back
+// into.  It has a single-column foreign key, a foreign key that's part of
+// a composite primary key, a composite foreign key and a unique index.
+const testModelJSON = `{
+	"databases": [{
+		"rawName": "shop",
+		"schemas": [{
+			"rawName": "sales",
+			"tables": [{
+				"rawName": "customer",
+				"columns": [
+					{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+					{"rawName": "name", "type": "string(var: true, length: 64)"},
+					{"rawName": "email", "type": "nullable(string(var: true, length: 128))"}
+				],
+				"indexes": [{"unique": true, "columns": ["email"]}]
+			}, {
+				"rawName": "order",
+				"columns": [
+					{"rawName": "order id", "type": "int(bits: 64)", "pk": true},
+					{"rawName": "customer id", "type": "int(bits: 32)", "fk": "customer.customer id"},
+					{"rawName": "shipped", "type": "bool"}
+				]
+			}, {
+				"rawName": "order line",
+				"columns": [
+					{"rawName": "order id", "type": "int(bits: 64)", "pk": true, "fk": "order.order id"},
+					{"rawName": "line number", "type": "int(bits: 16)", "pk": true},
+					{"rawName": "quantity", "type": "int(bits: 32)"}
+				]
+			}, {
+				"rawName": "shipment",
+				"columns": [
+					{"rawName": "shipment id", "type": "int(bits: 32)", "pk": true},
+					{"rawName": "order id", "type": "int(bits: 64)"},
+					{"rawName": "line number", "type": "int(bits: 16)"}
+				],
+				"foreignKeys": [{
+					"columns": ["order id", "line number"],
+					"references": "order line"
+				}]
+			}]
+		}]
+	}]
+}`
+
+// testMetaModel loads a model from its JSON configuration.
+func testMetaModel(t *testing.T, src string) *sqlstream.MetaModel {
+	t.Helper()
+	mm, err := MetaModelFromJSON(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("failed to load model: %v", err)
+	}
+	t.Cleanup(func() { ReleaseMetaModel(mm) })
+	return mm
+}
+
+// testWithParameters sets the parameters of a ParameterizedModelContext.
+func testWithParameters(t *testing.T, mc ModelContext, ps map[string]string) ModelContext {
+	t.Helper()
+	if len(ps) == 0 {
+		return mc
+	}
+	mc, err := mc.(ParameterizedModelContext).WithParameters(ps)
+	if err != nil {
+		t.Fatalf("invalid parameters %v: %v", ps, err)
+	}
+	return mc
+}
+
+// testParse parses src and creates a model from its configuration.
+func testParse(t *testing.T, p ExtModelConfigParser, src string) (*sqlstream.MetaModel, error) {
+	t.Helper()
+	cfg, err := p.ParseExtModelConfig(context.Background(), strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	mm, err := MetaModelFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { ReleaseMetaModel(mm) })
+	return mm, nil
+}
+
+// testWrite writes a model with a MetaModelWriter, a TemplateDataWriter or
+// the templates of a TemplateContext.
+func testWrite(t *testing.T, mc ModelContext, mm *sqlstream.MetaModel) string {
+	t.Helper()
+	var buf bytes.Buffer
+	switch x := mc.(type) {
+	case MetaModelWriter:
+		if err := x.WriteMetaModel(&buf, mm); err != nil {
+			t.Fatalf("failed to write model: %v", err)
+		}
+	case TemplateDataWriter:
+		td, err := TemplateDataFromMetaModel(mm, mc)
+		if err != nil {
+			t.Fatalf("failed to create template data: %v", err)
+		}
+		if err = x.WriteTemplateData(&buf, td); err != nil {
+			t.Fatalf("failed to write model: %v", err)
+		}
+	case TemplateContext:
+		td, err := TemplateDataFromMetaModel(mm, mc)
+		if err != nil {
+			t.Fatalf("failed to create template data: %v", err)
+		}
+		fm := make(template.FuncMap)
+		tmpl := AddFuncs(template.New("test"), fm, mc).Funcs(fm)
+		if tmpl, err = tmpl.ParseFS(x.FS(), "*.txt"); err != nil {
+			t.Fatalf("failed to parse templates: %v", err)
+		}
+		if err = tmpl.ExecuteTemplate(&buf, "0root.txt", td); err != nil {
+			t.Fatalf("failed to execute templates: %v", err)
+		}
+	default:
+		t.Fatalf("%T can't write models", mc)
+	}
+	return buf.String()
+}
+
+// modelSummary describes the tables, columns, foreign keys and indexes of
+// a model by their SQL names, one per line.
+func modelSummary(mm *sqlstream.MetaModel) string {
+	var sb strings.Builder
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				sb.WriteString(t.SQLName)
+				sb.WriteByte('\n')
+				for _, c := range t.Columns {
+					fmt.Fprintf(&sb, "\t%v %v", c.SQLName, testTypeName(c.Type))
+					if c.PK {
+						sb.WriteString(" pk")
+					}
+					sb.WriteByte('\n')
+				}
+				for _, fk := range TableExtOf(t).ForeignKeys {
+					fmt.Fprintf(
+						&sb, "\tfk (%v) %v (%v)\n",
+						testColumnNames(fk.Columns),
+						fk.RefTable.SQLName,
+						testColumnNames(fk.RefColumns),
+					)
+				}
+				for _, ix := range TableExtOf(t).Indexes {
+					cols := make([]*sqlstream.Column, len(ix.Columns))
+					for i, ic := range ix.Columns {
+						cols[i] = ic.Column
+					}
+					kind := "index"
+					if ix.Unique {
+						kind = "unique"
+					}
+					fmt.Fprintf(&sb, "\t%v %v (%v)\n", kind, ix.Name, testColumnNames(cols))
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// testTypeName names a type by the fields that the writers use.
+func testTypeName(t sqltypes.Type) string {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		return "nullable(" + testTypeName(t[0]) + ")"
+	case sqltypes.BoolType:
+		return "bool"
+	case sqltypes.IntType:
+		return fmt.Sprintf("int%d", t.Bits)
+	case sqltypes.FloatType:
+		return fmt.Sprintf("float%d", t.Mantissa)
+	case sqltypes.DecimalType:
+		return fmt.Sprintf("decimal(%d, %d)", t.Prec, t.Scale)
+	case sqltypes.StringType:
+		if t.Var {
+			return fmt.Sprintf("varchar(%d)", t.Length)
+		}
+		return fmt.Sprintf("char(%d)", t.Length)
+	case sqltypes.BytesType:
+		if t.Var {
+			return fmt.Sprintf("varbinary(%d)", t.Length)
+		}
+		return fmt.Sprintf("binary(%d)", t.Length)
+	case sqltypes.TimeType:
+		return fmt.Sprintf("time(%v)", t.Prec)
+	}
+	return fmt.Sprint(t)
+}
+
+func testColumnNames(cols []*sqlstream.Column) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.SQLName
+	}
+	return strings.Join(names, ", ")
+}
+
+// testRoundTrip writes the test model with w and checks that p parses it
+// back into the same model.
+func testRoundTrip(t *testing.T, w ModelContext, p ExtModelConfigParser) {
+	t.Helper()
+	mm := testMetaModel(t, testModelJSON)
+	src := testWrite(t, w, mm)
+	parsed, err := testParse(t, p, src)
+	if err != nil {
+		t.Fatalf("failed to parse:\n%v\nerror: %v", src, err)
+	}
+	if want, got := modelSummary(mm), modelSummary(parsed); got != want {
+		t.Fatalf("parsed:\n%v\nwant:\n%v\nfrom:\n%v", got, want, src)
+	}
+}
+
+// testParseCase is source code that a parser parses into a model with
+// the summary want or fails to parse with an error containing err.
+type testParseCase struct {
+	name   string
+	params map[string]string
+	src    string
+	want   string
+	err    string
+}
+
+func testParseCases(t *testing.T, mc ModelContext, cases []testParseCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := testWithParameters(t, mc, tc.params).(ExtModelConfigParser)
+			mm, err := testParse(t, p, tc.src)
+			switch {
+			case tc.err != "" && err == nil:
+				t.Fatalf("parsed without the error %q", tc.err)
+			case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+				t.Fatalf("got error %q, want %q", err, tc.err)
+			case tc.err != "":
+			case err != nil:
+				t.Fatalf("failed to parse: %v", err)
+			default:
+				if got := modelSummary(mm); got != tc.want {
+					t.Fatalf("parsed:\n%v\nwant:\n%v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		writer ModelContext
+		parser ModelContext
+		params map[string]string
+	}{
+		{"postgres", PostgresSQLDDLModelContext, SQLDDLParserModelContext, map[string]string{"dialect": "postgres"}},
+		{"mysql", MySQLSQLDDLModelContext, SQLDDLParserModelContext, map[string]string{"dialect": "mysql"}},
+		{"dbml", DBMLModelContext, DBMLConfigParserModelContext, nil},
+		{"prisma", PrismaModelContext, PrismaConfigParserModelContext, nil},
+		{"mermaid", MermaidModelContext, MermaidConfigParserModelContext, nil},
+		{"drawio", DrawIOWriterModelContext, DrawIOModelContext, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := testWithParameters(t, tc.parser, tc.params)
+			testRoundTrip(t, tc.writer, p.(ExtModelConfigParser))
+		})
+	}
+}
@@ -51,6 +51,16 @@ type NamespaceOrganizer interface {
 	OrganizeNamespaces(ns []string) []string
 }
 
+// ParameterizedModelContext is an optional interface that ModelContexts
+// can implement to receive the named parameters that were specified for
+// them on the command line or in a project file.
+type ParameterizedModelContext interface {
+	// WithParameters returns a copy of the ModelContext configured
+	// with the given parameters.  Parameters that the ModelContext does
+	// not recognize should be ignored.
+	WithParameters(ps map[string]string) (ModelContext, error)
+}
+
 // TemplateData combines a MetaModel and namespaces to be included at the
 // top of the template(s) being emitted.
 type TemplateData struct {
@@ -58,6 +68,11 @@ type TemplateData struct {
 	Namespaces []string
 	Parameters map[string]string
 	*sqlstream.MetaModel
+
+	// CreateFile creates files that a TemplateDataWriter writes in
+	// addition to its output (e.g. the puwvjson target's ID map).  If
+	// it's nil, the files are created with os.Create.
+	CreateFile func(filename string) (io.WriteCloser, error)
 }
 
 // TemplateDataFromMetaModel initializes TemplateData from a MetaModel and
@@ -127,3 +142,10 @@ type ModelConfigWriter interface {
 type ModelConfigParser interface {
 	ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error)
 }
+
+// ExtModelConfigParser is an optional interface that ModelConfigParsers
+// can implement to parse the sqlmodelgen-specific extensions (indexes,
+// default values, etc.) along with the model configuration.
+type ExtModelConfigParser interface {
+	ParseExtModelConfig(ctx context.Context, r io.Reader) (Config, error)
+}
@@ -0,0 +1,796 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// Config is a config.Config along with the sqlmodelgen-specific
+// extensions to it.  Both are stored in the same models.json document:
+// the extensions are additional members of the database, schema, table
+// and column objects that config.Config ignores.
+type Config struct {
+	config.Config
+
+	// Ext holds the extensions.  It mirrors the hierarchy of
+	// config.Config, matching elements by their raw names, but only
+	// needs entries for the elements that actually have extensions.
+	Ext ConfigExt
+}
+
+// ConfigExt is the root of the extensions to a config.Config.
+type ConfigExt struct {
+	Databases []DatabaseConfigExt `json:"databases,omitempty"`
+}
+
+// DatabaseConfigExt holds the extensions to a config.Database.
+type DatabaseConfigExt struct {
+	RawName string `json:"rawName"`
+
+	// Enums are the enumerations that the database's columns can use.
+	Enums []EnumConfig `json:"enums,omitempty"`
+
+	Schemas []SchemaConfigExt `json:"schemas,omitempty"`
+}
+
+// EnumConfig declares an enumeration:  A named set of values that a
+// column can hold.
+type EnumConfig struct {
+	config.CommonData
+
+	// Type is the underlying type of the enumeration in the same form
+	// as a column's type.  It must be an int or a string type.  If
+	// blank, it is "int(bits: 32)".
+	Type string `json:"type,omitempty"`
+
+	Members []EnumMemberConfig `json:"members"`
+
+	// LookupTable is the raw name of a lookup table that holds the
+	// enumeration's members.  If set, the table is added to the model
+	// with a "value" primary key column and a "name" column, the SQL
+	// DDL targets fill it with the members and columns of the enum
+	// type reference it.  Otherwise, the SQL DDL targets constrain the
+	// columns with checks.
+	LookupTable string `json:"lookupTable,omitempty"`
+
+	// Schema is the raw name of the lookup table's schema.  If blank,
+	// the lookup table is added to the database's first schema.
+	Schema string `json:"schema,omitempty"`
+}
+
+// EnumMemberConfig declares a member of an enumeration.
+type EnumMemberConfig struct {
+	config.CommonData
+
+	// Value is the member's value:  A JSON number for int enumerations
+	// or a JSON string for string enumerations.  If omitted, an int
+	// member's value is one more than the previous member's (starting
+	// at zero) and a string member's value is its raw name.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SchemaConfigExt holds the extensions to a config.Schema.
+type SchemaConfigExt struct {
+	RawName string           `json:"rawName"`
+	Tables  []TableConfigExt `json:"tables,omitempty"`
+}
+
+// TableConfigExt holds the extensions to a config.Table.
+type TableConfigExt struct {
+	RawName string `json:"rawName"`
+
+	// RenamedFrom is the previous raw name of the table.  Migrations
+	// use it to rename the table instead of dropping and recreating
+	// it.
+	RenamedFrom string `json:"renamedFrom,omitempty"`
+
+	// ForeignKeys declares the table's foreign keys that cannot be
+	// inferred from its columns' FKs.
+	ForeignKeys []ForeignKeyConfig `json:"foreignKeys,omitempty"`
+
+	// Indexes are the table's secondary indexes and unique
+	// constraints.
+	Indexes []IndexConfig `json:"indexes,omitempty"`
+
+	// Checks are the table's check constraints that involve more than
+	// one column.  Single-column checks can be declared on the column
+	// instead.
+	Checks []CheckConfig `json:"checks,omitempty"`
+
+	Columns []ColumnConfigExt `json:"columns,omitempty"`
+}
+
+// CheckConfig declares a check constraint on a table.
+type CheckConfig struct {
+	// Name is the name of the check constraint.  If blank, it is
+	// derived from the table name.
+	Name string `json:"name,omitempty"`
+
+	// Expression is the constraint's boolean SQL expression.  It is
+	// emitted as-is.
+	Expression string `json:"expression"`
+}
+
+// IndexConfig declares an index on a table.
+type IndexConfig struct {
+	// Name is the name of the index.  If blank, it is derived from the
+	// table and column names.
+	Name string `json:"name,omitempty"`
+
+	// Unique indexes also serve as the table's unique constraints.
+	Unique bool `json:"unique,omitempty"`
+
+	// Columns are the key columns of the index.
+	Columns []IndexColumnConfig `json:"columns"`
+
+	// Include are the raw names of non-key columns to include in the
+	// index.
+	Include []string `json:"include,omitempty"`
+
+	// Where is the predicate of a filtered index.  It is SQL and is
+	// emitted as-is.
+	Where string `json:"where,omitempty"`
+}
+
+// IndexColumnConfig is a key column of an index.  In JSON, it can be
+// either an object or just the column's raw name.
+type IndexColumnConfig struct {
+	RawName string `json:"rawName"`
+
+	// Order is either "asc" (the default) or "desc".
+	Order string `json:"order,omitempty"`
+}
+
+func (c *IndexColumnConfig) UnmarshalJSON(bs []byte) error {
+	if len(bs) > 0 && bs[0] == '"' {
+		*c = IndexColumnConfig{}
+		return json.Unmarshal(bs, &c.RawName)
+	}
+	type indexColumnConfig IndexColumnConfig
+	return json.Unmarshal(bs, (*indexColumnConfig)(c))
+}
+
+// ForeignKeyConfig declares a foreign key.  Foreign keys are inferred from
+// the columns' FKs:  A column referencing another table's primary key is a
+// single-column foreign key and columns that together reference every ID
+// of another table's composite key are one multi-column foreign key.  Only
+// foreign keys that cannot be inferred (e.g. two references to the same
+// composite key) have to be declared.
+type ForeignKeyConfig struct {
+	// Name is the name of the foreign key constraint.  If blank, it is
+	// derived from the table and column names.
+	Name string `json:"name,omitempty"`
+
+	// ModelName is the name of the field that holds the foreign key in
+	// the generated models.  If blank, it is derived from the
+	// referenced key's name.
+	ModelName string `json:"modelName,omitempty"`
+
+	// Columns are the raw names of the referencing columns.
+	Columns []string `json:"columns"`
+
+	// References is the path to the referenced table, relative to the
+	// table, in the same form as a column's FK (e.g. "table",
+	// "schema.table", etc.)
+	References string `json:"references"`
+
+	// ReferencedColumns are the raw names of the referenced columns.
+	// If empty, the referenced table's primary key or composite key
+	// columns are referenced.
+	ReferencedColumns []string `json:"referencedColumns,omitempty"`
+
+	// OneToOne is true if at most one row of the table references each
+	// row of the referenced table.  Otherwise, the relationship is
+	// one-to-many.
+	OneToOne bool `json:"oneToOne,omitempty"`
+}
+
+// ColumnConfigExt holds the extensions to a config.Column.
+type ColumnConfigExt struct {
+	RawName string `json:"rawName"`
+
+	// RenamedFrom is the previous raw name of the column.  Migrations
+	// use it to rename the column instead of dropping and recreating
+	// it.
+	RenamedFrom string `json:"renamedFrom,omitempty"`
+
+	// Generated is true if the database generates the column's values
+	// (e.g. an IDENTITY or AUTO_INCREMENT key).
+	Generated bool `json:"generated,omitempty"`
+
+	// Default is the column's default value:  Either a SQL literal (e.g.
+	// 0, 'abc', TRUE, etc.) or an expression.  The current date and time
+	// (e.g. GETDATE(), CURRENT_TIMESTAMP, NOW()) and new UUIDs (e.g.
+	// NEWID(), UUID()) are translated to each SQL dialect.  Other
+	// expressions are emitted as-is.
+	Default string `json:"default,omitempty"`
+
+	// Check is a check constraint on the column's values.  It is a
+	// boolean SQL expression and is emitted as-is.
+	Check string `json:"check,omitempty"`
+
+	// Enum is the raw name of the enumeration that the column's values
+	// belong to.  If the column has no type, it gets the enumeration's
+	// underlying type.
+	Enum string `json:"enum,omitempty"`
+
+	// OneToOne is true if the column's FK is a one-to-one relationship
+	// like ForeignKeyConfig.OneToOne.
+	OneToOne bool `json:"oneToOne,omitempty"`
+}
+
+// Table gets the extensions of a table, creating them if they do not
+// yet exist.  The result is only valid until the next call to Table or
+// Column.
+func (e *ConfigExt) Table(dbName, schName, tblName string) *TableConfigExt {
+	var db *DatabaseConfigExt
+	for i := range e.Databases {
+		if e.Databases[i].RawName == dbName {
+			db = &e.Databases[i]
+			break
+		}
+	}
+	if db == nil {
+		e.Databases = append(e.Databases, DatabaseConfigExt{RawName: dbName})
+		db = &e.Databases[len(e.Databases)-1]
+	}
+	var sch *SchemaConfigExt
+	for i := range db.Schemas {
+		if db.Schemas[i].RawName == schName {
+			sch = &db.Schemas[i]
+			break
+		}
+	}
+	if sch == nil {
+		db.Schemas = append(db.Schemas, SchemaConfigExt{RawName: schName})
+		sch = &db.Schemas[len(db.Schemas)-1]
+	}
+	for i := range sch.Tables {
+		if sch.Tables[i].RawName == tblName {
+			return &sch.Tables[i]
+		}
+	}
+	sch.Tables = append(sch.Tables, TableConfigExt{RawName: tblName})
+	return &sch.Tables[len(sch.Tables)-1]
+}
+
+// Column gets the extensions of a column, creating them if they do not
+// yet exist.  The result is only valid until the next call to Table or
+// Column.
+func (e *ConfigExt) Column(dbName, schName, tblName, colName string) *ColumnConfigExt {
+	tbl := e.Table(dbName, schName, tblName)
+	for i := range tbl.Columns {
+		if tbl.Columns[i].RawName == colName {
+			return &tbl.Columns[i]
+		}
+	}
+	tbl.Columns = append(tbl.Columns, ColumnConfigExt{RawName: colName})
+	return &tbl.Columns[len(tbl.Columns)-1]
+}
+
+// findDatabase gets the extensions of a database or nil if it has none.
+func (e *ConfigExt) findDatabase(dbName string) *DatabaseConfigExt {
+	for i := range e.Databases {
+		if e.Databases[i].RawName == dbName {
+			return &e.Databases[i]
+		}
+	}
+	return nil
+}
+
+// findTable gets the extensions of a table or nil if it has none.
+func (e *ConfigExt) findTable(dbName, schName, tblName string) *TableConfigExt {
+	for i := range e.Databases {
+		db := &e.Databases[i]
+		if db.RawName != dbName {
+			continue
+		}
+		for j := range db.Schemas {
+			sch := &db.Schemas[j]
+			if sch.RawName != schName {
+				continue
+			}
+			for k := range sch.Tables {
+				if sch.Tables[k].RawName == tblName {
+					return &sch.Tables[k]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findColumn gets the extensions of a column or nil if it has none.
+func (e *ConfigExt) findColumn(dbName, schName, tblName, colName string) *ColumnConfigExt {
+	tbl := e.findTable(dbName, schName, tblName)
+	if tbl == nil {
+		return nil
+	}
+	for i := range tbl.Columns {
+		if tbl.Columns[i].RawName == colName {
+			return &tbl.Columns[i]
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON unmarshals both the config.Config and its extensions
+// from the same JSON document.
+func (c *Config) UnmarshalJSON(bs []byte) error {
+	if err := json.Unmarshal(bs, &c.Config); err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, &c.Ext)
+}
+
+// MarshalJSON marshals the config.Config and merges its extensions into
+// the same JSON document.
+func (c Config) MarshalJSON() ([]byte, error) {
+	bs, err := json.Marshal(c.Config)
+	if err != nil || len(c.Ext.Databases) == 0 {
+		return bs, err
+	}
+	cfg, err := decodeOrderedJSON(bs)
+	if err != nil {
+		return nil, errors.Errorf0From(
+			err, "failed to decode configuration JSON",
+		)
+	}
+	bs, err = json.Marshal(c.Ext)
+	if err != nil {
+		return nil, err
+	}
+	ext, err := decodeOrderedJSON(bs)
+	if err != nil {
+		return nil, errors.Errorf0From(
+			err, "failed to decode configuration extension JSON",
+		)
+	}
+	return json.Marshal(mergeOrderedJSON(cfg, ext))
+}
+
+// orderedJSONObject is a JSON object whose members are kept in the order
+// that they were decoded so that merging extensions into a configuration
+// doesn't reorder it.
+type orderedJSONObject []orderedJSONMember
+
+type orderedJSONMember struct {
+	Key   string
+	Value interface{}
+}
+
+func (o orderedJSONObject) MarshalJSON() ([]byte, error) {
+	b := bytes.Buffer{}
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (o orderedJSONObject) get(key string) (interface{}, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// decodeOrderedJSON decodes JSON into orderedJSONObjects, []interface{}
+// and json.RawMessage scalars.
+func decodeOrderedJSON(bs []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	return decodeOrderedJSONValue(dec)
+}
+
+func decodeOrderedJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch d {
+	case '{':
+		o := make(orderedJSONObject, 0, 8)
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			k, ok := tok.(string)
+			if !ok {
+				return nil, errors.Errorf1(
+					"expected object key, not %v", tok,
+				)
+			}
+			v, err := decodeOrderedJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, orderedJSONMember{Key: k, Value: v})
+		}
+		if _, err = dec.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return o, nil
+	case '[':
+		a := make([]interface{}, 0, 8)
+		for dec.More() {
+			v, err := decodeOrderedJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		if _, err = dec.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return a, nil
+	}
+	return nil, errors.Errorf1("unexpected delimiter: %v", d)
+}
+
+// mergeOrderedJSON merges src into dst.  Objects are merged member by
+// member and arrays of objects are merged by matching their "rawName"
+// members.  Elements of src that have no match in dst are dropped
+// because an extension without its configuration is meaningless.
+func mergeOrderedJSON(dst, src interface{}) interface{} {
+	switch s := src.(type) {
+	case orderedJSONObject:
+		d, ok := dst.(orderedJSONObject)
+		if !ok {
+			return src
+		}
+		for _, m := range s {
+			found := false
+			for i := range d {
+				if d[i].Key == m.Key {
+					d[i].Value = mergeOrderedJSON(d[i].Value, m.Value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				d = append(d, m)
+			}
+		}
+		return d
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			return src
+		}
+		for _, sv := range s {
+			so, ok := sv.(orderedJSONObject)
+			if !ok {
+				continue
+			}
+			name, _ := so.get("rawName")
+			for i, dv := range d {
+				do, ok := dv.(orderedJSONObject)
+				if !ok {
+					continue
+				}
+				if dn, _ := do.get("rawName"); dn == name {
+					d[i] = mergeOrderedJSON(do, so)
+					break
+				}
+			}
+		}
+		return d
+	}
+	return src
+}
+
+// MetaModelExt holds the extensions of a sqlstream.MetaModel after they
+// have been linked to the model's tables and columns.
+type MetaModelExt struct {
+	Databases map[*sqlstream.Database]*DatabaseExt
+	Tables    map[*sqlstream.Table]*TableExt
+	Columns   map[*sqlstream.Column]*ColumnExt
+}
+
+// DatabaseExt holds the linked extensions of a sqlstream.Database.
+type DatabaseExt struct {
+	// Enums are the database's enumerations in the order they were
+	// declared.
+	Enums []*Enum
+}
+
+// Enum is an enumeration:  A named set of values that columns can hold.
+type Enum struct {
+	sqlstream.Names
+	Doc      string
+	Database *sqlstream.Database
+
+	// Type is the underlying sqltypes.IntType or sqltypes.StringType
+	// of the enumeration.
+	Type sqltypes.Type
+
+	Members []*EnumMember
+
+	// LookupTable is the table that holds the enumeration's members or
+	// nil if the enumeration has no lookup table.
+	LookupTable *sqlstream.Table
+}
+
+// IsString is true if the enumeration's underlying type is a string.
+func (e *Enum) IsString() bool {
+	_, ok := e.Type.(sqltypes.StringType)
+	return ok
+}
+
+// EnumMember is a member of an Enum.
+type EnumMember struct {
+	sqlstream.Names
+	Doc  string
+	Enum *Enum
+
+	// Value is the member's value:  The digits of an int or the
+	// unquoted string.
+	Value string
+}
+
+// SQLValue gets the member's value as a SQL literal.
+func (m *EnumMember) SQLValue() string {
+	if m.Enum.IsString() {
+		return "'" + strings.ReplaceAll(m.Value, "'", "''") + "'"
+	}
+	return m.Value
+}
+
+// SQLRawName gets the member's raw name as a SQL literal.
+func (m *EnumMember) SQLRawName() string {
+	return "'" + strings.ReplaceAll(m.RawName, "'", "''") + "'"
+}
+
+// QuotedValue gets the member's value as a Go or C# literal.
+func (m *EnumMember) QuotedValue() string {
+	if m.Enum.IsString() {
+		return strconv.Quote(m.Value)
+	}
+	return m.Value
+}
+
+// TableExt holds the linked extensions of a sqlstream.Table.
+type TableExt struct {
+	// RenamedFrom is the previous raw name of the table.
+	RenamedFrom string
+
+	// ForeignKeys are all of the table's foreign keys, both declared
+	// and inferred, in the order of their first columns.
+	ForeignKeys []*ForeignKey
+
+	// Indexes are the table's indexes in the order they were declared.
+	Indexes []*Index
+
+	// Enum is the enumeration that the table is the lookup table of or
+	// nil if it isn't a lookup table.
+	Enum *Enum
+
+	// Checks are the table's check constraints:  First the columns'
+	// checks in the order of the columns and then the table's checks in
+	// the order they were declared.
+	Checks []*Check
+}
+
+// Check is a check constraint of a table.
+type Check struct {
+	Name       string
+	Table      *sqlstream.Table
+	Expression string
+
+	// Column is the column that the check was declared on or nil if it
+	// was declared on the table.
+	Column *sqlstream.Column
+
+	// Enum is set instead of the Expression if the check constrains
+	// the Column to the members of an enumeration.  The expression
+	// depends on the SQL dialect.
+	Enum *Enum
+}
+
+// Index is a secondary index or unique constraint of a table.
+type Index struct {
+	Name    string
+	Unique  bool
+	Table   *sqlstream.Table
+	Columns []IndexColumn
+	Include []*sqlstream.Column
+	Where   string
+}
+
+// String describes the index for documentation, e.g.:
+//
+//	UX_Customer_Email: unique ("Email", "Name" DESC) include ("Phone")
+func (ix *Index) String() string {
+	b := strings.Builder{}
+	b.WriteString(ix.Name)
+	b.WriteString(": ")
+	if ix.Unique {
+		b.WriteString("unique ")
+	}
+	b.WriteByte('(')
+	for i, c := range ix.Columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(c.SQLName))
+		if c.Descending {
+			b.WriteString(" DESC")
+		}
+	}
+	b.WriteByte(')')
+	if len(ix.Include) > 0 {
+		b.WriteString(" include (")
+		for i, c := range ix.Include {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Quote(c.SQLName))
+		}
+		b.WriteByte(')')
+	}
+	if ix.Where != "" {
+		b.WriteString(" where ")
+		b.WriteString(ix.Where)
+	}
+	return b.String()
+}
+
+// IndexColumn is a key column of an Index.
+type IndexColumn struct {
+	*sqlstream.Column
+	Descending bool
+}
+
+// ForeignKey is a relationship from one or more columns of a table to the
+// key of another table.
+type ForeignKey struct {
+	// Name is the name of the foreign key constraint.
+	Name string
+
+	// ModelName is the name of the field that holds a multi-column
+	// foreign key in the generated models.
+	ModelName string
+
+	Table   *sqlstream.Table
+	Columns []*sqlstream.Column
+
+	// RefTable is the referenced table and RefColumns are its columns
+	// referenced by the Columns at the same indexes.
+	RefTable   *sqlstream.Table
+	RefColumns []*sqlstream.Column
+
+	// RefKey is the referenced table's composite key if the foreign
+	// key references all of its IDs.  It is nil otherwise.
+	RefKey *sqlstream.TableKey
+
+	// OneToOne is true if at most one row of Table references each row
+	// of RefTable.  Otherwise, the relationship is one-to-many.
+	OneToOne bool
+
+	// keyModelName is true if ModelName wasn't configured and is the
+	// model name of RefKey.
+	keyModelName bool
+}
+
+// ColumnExt holds the linked extensions of a sqlstream.Column.
+type ColumnExt struct {
+	// RenamedFrom is the previous raw name of the column.
+	RenamedFrom string
+
+	// Generated is true if the database generates the column's values.
+	Generated bool
+
+	// Default is the column's default value as it was declared.
+	Default string
+
+	// ForeignKey is the foreign key that the column is part of or nil
+	// if the column doesn't reference another table.
+	ForeignKey *ForeignKey
+
+	// Indexes are the indexes that the column is a key column of.
+	Indexes []*Index
+
+	// Enum is the enumeration that the column's values belong to or
+	// nil if the column isn't an enumeration.
+	Enum *Enum
+
+	// oneToOne is copied into the ForeignKey that the column is part
+	// of.
+	oneToOne bool
+}
+
+// metaModelExts associates MetaModels with their extensions so that the
+// extensions are available everywhere a MetaModel is, without changing
+// the MetaModelWriter, TemplateDataWriter, etc. interfaces.
+var metaModelExts = struct {
+	sync.Mutex
+	m map[*sqlstream.MetaModel]*MetaModelExt
+}{
+	m: make(map[*sqlstream.MetaModel]*MetaModelExt),
+}
+
+func newMetaModelExt() *MetaModelExt {
+	return &MetaModelExt{
+		Databases: make(map[*sqlstream.Database]*DatabaseExt),
+		Tables:    make(map[*sqlstream.Table]*TableExt),
+		Columns:   make(map[*sqlstream.Column]*ColumnExt),
+	}
+}
+
+// MetaModelExtOf gets the extensions of a MetaModel.  MetaModels that
+// were not created with MetaModelFromConfig (or that were released with
+// ReleaseMetaModel) have no extensions and get an empty MetaModelExt.
+func MetaModelExtOf(mm *sqlstream.MetaModel) *MetaModelExt {
+	metaModelExts.Lock()
+	defer metaModelExts.Unlock()
+	if ext, ok := metaModelExts.m[mm]; ok {
+		return ext
+	}
+	return newMetaModelExt()
+}
+
+// ReleaseMetaModel forgets the extensions of a MetaModel created with
+// MetaModelFromConfig so that they can be garbage collected along with
+// the MetaModel.  Programs that build many MetaModels should release each
+// one when they're done with it.
+func ReleaseMetaModel(mm *sqlstream.MetaModel) {
+	metaModelExts.Lock()
+	delete(metaModelExts.m, mm)
+	metaModelExts.Unlock()
+}
+
+// DatabaseExtOf gets the extensions of a database.  The result is never
+// nil.
+func DatabaseExtOf(db *sqlstream.Database) *DatabaseExt {
+	if ext, ok := MetaModelExtOf(db.MetaModel).Databases[db]; ok {
+		return ext
+	}
+	return &DatabaseExt{}
+}
+
+// TableExtOf gets the extensions of a table.  The result is never nil.
+func TableExtOf(t *sqlstream.Table) *TableExt {
+	if ext, ok := MetaModelExtOf(t.Schema.Database.MetaModel).Tables[t]; ok {
+		return ext
+	}
+	return &TableExt{}
+}
+
+// ColumnExtOf gets the extensions of a column.  The result is never nil.
+func ColumnExtOf(c *sqlstream.Column) *ColumnExt {
+	if ext, ok := MetaModelExtOf(c.Table.Schema.Database.MetaModel).Columns[c]; ok {
+		return ext
+	}
+	return &ColumnExt{}
+}
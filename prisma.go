@@ -0,0 +1,1528 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// prismaProviderParam is the name of the parameter that holds the
+	// datasource provider of the written schema (e.g. "postgresql",
+	// "mysql", "sqlserver" or "sqlite").
+	prismaProviderParam = "provider"
+
+	// prismaDatabaseParam is the name of the parameter that holds the
+	// raw name of the database to write (a Prisma schema only has one)
+	// or the raw name of the database that a parsed schema's models go
+	// into.
+	prismaDatabaseParam = "database"
+)
+
+var (
+	// PrismaModelContext writes models as a Prisma schema.
+	PrismaModelContext interface {
+		ModelContext
+		MetaModelWriter
+		ParameterizedModelContext
+	} = prismaModelContext{provider: "postgresql"}
+
+	// PrismaConfigParserModelContext reads a Prisma schema into a model
+	// configuration.
+	PrismaConfigParserModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = prismaConfigParser{database: "main"}
+
+	// prismaDialectNames are the SQL dialects of Prisma's datasource
+	// providers.
+	prismaDialectNames = map[string]string{
+		"postgresql":  "postgres",
+		"postgres":    "postgres",
+		"cockroachdb": "postgres",
+		"mysql":       "mysql",
+		"sqlserver":   "mssql",
+		"sqlite":      "sqlite3",
+	}
+
+	// prismaDefaultSchemas are the schemas that tables without a schema
+	// are written to when other tables have schemas.  MySQL's schemas
+	// are databases, so its default is the database's SQL name.
+	prismaDefaultSchemas = map[string]string{
+		"postgresql":  "public",
+		"postgres":    "public",
+		"cockroachdb": "public",
+		"sqlserver":   "dbo",
+	}
+)
+
+type prismaModelContext struct {
+	provider string
+	database string
+}
+
+func (mc prismaModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	typename, _, err = prismaType(mc.provider, t)
+	return
+}
+
+func (mc prismaModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[prismaProviderParam]; s != "" {
+		if _, ok := prismaDialectNames[s]; !ok {
+			return nil, errors.Errorf1(
+				"unsupported Prisma provider: %q", s,
+			)
+		}
+		mc.provider = s
+	}
+	if s := ps[prismaDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+// prismaFieldLine is a field of a written model.
+type prismaFieldLine struct {
+	name, typ, attrs, doc string
+}
+
+// prismaRelation is a foreign key's pair of relation fields.
+type prismaRelation struct {
+	fk *ForeignKey
+
+	// name is the relation's name.  Relations need names when there
+	// is more than one between the same models.
+	name string
+
+	// field is the name of the relation field of the foreign key's
+	// model and backField is the name of the field of the referenced
+	// model.
+	field, backField string
+}
+
+func (mc prismaModelContext) WriteMetaModel(w io.Writer, mm *sqlstream.MetaModel) error {
+	var db *sqlstream.Database
+	switch {
+	case mc.database != "":
+		for _, x := range mm.Databases {
+			if x.RawName == mc.database {
+				db = x
+			}
+		}
+		if db == nil {
+			return errors.Errorf1("database %q not found", mc.database)
+		}
+	case len(mm.Databases) == 1:
+		db = mm.Databases[0]
+	default:
+		return errors.Errorf1(
+			"a Prisma schema can only hold one database.  Please "+
+				"use the %q parameter to choose one",
+			prismaDatabaseParam,
+		)
+	}
+	names := prismaModelNames(db)
+	schemaNames, err := mc.schemaNames(db)
+	if err != nil {
+		return err
+	}
+	// Only string enumerations are Prisma enums.  Columns of int
+	// enumerations are just Ints.
+	var enums []*Enum
+	for _, e := range DatabaseExtOf(db).Enums {
+		if e.IsString() {
+			enums = append(enums, e)
+		}
+	}
+	var tables []*sqlstream.Table
+	var schemas []string
+	for _, sch := range db.Schemas {
+		if name := schemaNames[sch]; name != "" {
+			quoted := strconv.Quote(name)
+			if !prismaContains(schemas, quoted) {
+				schemas = append(schemas, quoted)
+			}
+		}
+		for _, t := range sch.Tables {
+			if e := TableExtOf(t).Enum; e != nil && e.IsString() {
+				// Lookup tables are replaced by their enums.
+				continue
+			}
+			tables = append(tables, t)
+		}
+	}
+	fields := make(map[*sqlstream.Table]map[string]bool, len(tables))
+	for _, t := range tables {
+		fields[t] = make(map[string]bool, len(t.Columns))
+		for _, c := range t.Columns {
+			fields[t][prismaFieldName(c.Names)] = true
+		}
+	}
+	relations := make(map[*sqlstream.Table][]*prismaRelation, len(tables))
+	pairs := make(map[[2]*sqlstream.Table]int)
+	for _, t := range tables {
+		for _, fk := range TableExtOf(t).ForeignKeys {
+			if fields[fk.RefTable] == nil {
+				continue
+			}
+			pair := [2]*sqlstream.Table{fk.Table, fk.RefTable}
+			if names[pair[1]] < names[pair[0]] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			pairs[pair]++
+			if pair[0] == pair[1] {
+				pairs[pair]++
+			}
+		}
+	}
+	for _, t := range tables {
+		for _, fk := range TableExtOf(t).ForeignKeys {
+			if fields[fk.RefTable] == nil {
+				continue
+			}
+			rel := &prismaRelation{fk: fk}
+			pair := [2]*sqlstream.Table{fk.Table, fk.RefTable}
+			if names[pair[1]] < names[pair[0]] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if pairs[pair] > 1 {
+				rel.name = fk.Name
+			}
+			refField := prismaFieldName(sqlstream.Names{ModelName: names[fk.RefTable]})
+			candidates := []string{refField}
+			if len(fk.Columns) == 1 {
+				name := prismaFieldName(fk.Columns[0].Names)
+				for _, suffix := range []string{"Id", "ID"} {
+					if s := strings.TrimSuffix(name, suffix); s != name && s != "" {
+						candidates = append([]string{s}, candidates...)
+					}
+				}
+			}
+			rel.field = prismaUniqueName(fields[t], candidates...)
+			backField := prismaFieldName(sqlstream.Names{ModelName: names[t]})
+			if !fk.OneToOne {
+				backField = pluralize(backField)
+			}
+			rel.backField = prismaUniqueName(
+				fields[fk.RefTable], backField,
+				backField+strings.ToUpper(rel.field[:1])+rel.field[1:],
+			)
+			relations[t] = append(relations[t], rel)
+			if fk.RefTable != t {
+				relations[fk.RefTable] = append(relations[fk.RefTable], rel)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString("generator client {\n")
+	buf.WriteString("  provider = \"prisma-client-js\"\n")
+	if len(schemas) > 0 {
+		buf.WriteString("  previewFeatures = [\"multiSchema\"]\n")
+	}
+	buf.WriteString("}\n\ndatasource db {\n")
+	buf.WriteString("  provider = ")
+	buf.WriteString(strconv.Quote(mc.provider))
+	buf.WriteString("\n  url      = env(\"DATABASE_URL\")\n")
+	if len(schemas) > 0 {
+		buf.WriteString("  schemas  = [")
+		buf.WriteString(strings.Join(schemas, ", "))
+		buf.WriteString("]\n")
+	}
+	buf.WriteString("}\n")
+	for _, t := range tables {
+		if err := mc.writeModel(&buf, t, names, relations[t], schemaNames[t.Schema]); err != nil {
+			return errors.Errorf1From(
+				err, "failed to write table %v", t.RawName,
+			)
+		}
+	}
+	for _, e := range enums {
+		prismaWriteEnum(&buf, db, e, schemaNames)
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write Prisma schema to %v", w,
+		)
+	}
+	return nil
+}
+
+func (mc prismaModelContext) writeModel(buf *bytes.Buffer, t *sqlstream.Table, names map[*sqlstream.Table]string, relations []*prismaRelation, schema string) error {
+	var pks []*sqlstream.Column
+	for _, c := range t.Columns {
+		if c.PK {
+			pks = append(pks, c)
+		}
+	}
+	buf.WriteByte('\n')
+	prismaWriteDoc(buf, "", t.Doc)
+	buf.WriteString("model ")
+	buf.WriteString(names[t])
+	buf.WriteString(" {\n")
+	fields := make([]prismaFieldLine, 0, len(t.Columns)+len(relations))
+	for _, c := range t.Columns {
+		x := ColumnExtOf(c)
+		typename, native, err := prismaType(mc.provider, c.Type)
+		if err != nil {
+			return errors.Errorf1From(
+				err, "failed to get type of column %v", c.RawName,
+			)
+		}
+		if x.Enum != nil && x.Enum.IsString() {
+			typename, native = prismaIdentifier(x.Enum.Names), ""
+		}
+		if _, ok := c.Type.(sqltypes.Nullable); ok {
+			typename += "?"
+		}
+		var attrs []string
+		if c.PK && len(pks) == 1 {
+			attrs = append(attrs, "@id")
+		}
+		if def := prismaDefault(c); def != "" {
+			attrs = append(attrs, "@default("+def+")")
+		}
+		for _, ix := range x.Indexes {
+			if ix.Unique && len(ix.Columns) == 1 && ix.Where == "" && len(ix.Include) == 0 {
+				if ix.Name == defaultIndexName(ix) {
+					attrs = append(attrs, "@unique")
+				} else {
+					attrs = append(attrs, "@unique(map: "+strconv.Quote(ix.Name)+")")
+				}
+				break
+			}
+		}
+		name := prismaFieldName(c.Names)
+		if sqlName := dataDictTitle(c.Names); sqlName != name {
+			attrs = append(attrs, "@map("+strconv.Quote(sqlName)+")")
+		}
+		if native != "" {
+			attrs = append(attrs, native)
+		}
+		fields = append(fields, prismaFieldLine{
+			name: name, typ: typename, doc: c.Doc,
+			attrs: strings.Join(attrs, " "),
+		})
+	}
+	for _, rel := range relations {
+		if rel.fk.Table == t {
+			fields = append(fields, prismaRelationField(rel, names))
+		}
+		if rel.fk.RefTable == t {
+			typename := names[rel.fk.Table] + "[]"
+			if rel.fk.OneToOne {
+				typename = names[rel.fk.Table] + "?"
+			}
+			f := prismaFieldLine{name: rel.backField, typ: typename}
+			if rel.name != "" {
+				f.attrs = "@relation(" + strconv.Quote(rel.name) + ")"
+			}
+			fields = append(fields, f)
+		}
+	}
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		if len(f.name) > nameWidth {
+			nameWidth = len(f.name)
+		}
+		if len(f.typ) > typeWidth {
+			typeWidth = len(f.typ)
+		}
+	}
+	for _, f := range fields {
+		prismaWriteDoc(buf, "  ", f.doc)
+		line := "  " + f.name + strings.Repeat(" ", nameWidth-len(f.name)+1) + f.typ
+		if f.attrs != "" {
+			line += strings.Repeat(" ", typeWidth-len(f.typ)+1) + f.attrs
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	var attrs []string
+	if len(pks) > 1 {
+		attrs = append(attrs, "@@id(["+prismaFieldNames(pks)+"])")
+	}
+	for _, ix := range TableExtOf(t).Indexes {
+		if ix.Unique && len(ix.Columns) == 1 && ix.Where == "" && len(ix.Include) == 0 {
+			// written as a field attribute
+			continue
+		}
+		if ix.Where != "" || len(ix.Include) > 0 {
+			logger.Verbose1(
+				"Prisma indexes cannot have filters or included "+
+					"columns.  Writing index %v without them",
+				ix.Name,
+			)
+		}
+		fields := make([]string, len(ix.Columns))
+		for i, c := range ix.Columns {
+			fields[i] = prismaFieldName(c.Names)
+			if c.Descending {
+				fields[i] += "(sort: Desc)"
+			}
+		}
+		attr := "@@index(["
+		if ix.Unique {
+			attr = "@@unique(["
+		}
+		attr += strings.Join(fields, ", ") + "]"
+		if ix.Name != defaultIndexName(ix) {
+			attr += ", map: " + strconv.Quote(ix.Name)
+		}
+		attrs = append(attrs, attr+")")
+	}
+	if sqlName := dataDictTitle(t.Names); sqlName != names[t] {
+		attrs = append(attrs, "@@map("+strconv.Quote(sqlName)+")")
+	}
+	if schema != "" {
+		attrs = append(attrs, "@@schema("+strconv.Quote(schema)+")")
+	}
+	if len(attrs) > 0 {
+		buf.WriteByte('\n')
+		for _, attr := range attrs {
+			buf.WriteString("  ")
+			buf.WriteString(attr)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString("}\n")
+	return nil
+}
+
+// prismaRelationField gets the relation field of a foreign key's model.  It's optional if any of the foreign key's columns are
+// nullable.
+func prismaRelationField(rel *prismaRelation, names map[*sqlstream.Table]string) prismaFieldLine {
+	typename := names[rel.fk.RefTable]
+	for _, c := range rel.fk.Columns {
+		if _, ok := c.Type.(sqltypes.Nullable); ok {
+			typename += "?"
+			break
+		}
+	}
+	args := make([]string, 0, 4)
+	if rel.name != "" {
+		args = append(args, strconv.Quote(rel.name))
+	}
+	args = append(
+		args,
+		"fields: ["+prismaFieldNames(rel.fk.Columns)+"]",
+		"references: ["+prismaFieldNames(rel.fk.RefColumns)+"]",
+	)
+	if rel.fk.Name != defaultForeignKeyName(rel.fk) {
+		args = append(args, "map: "+strconv.Quote(rel.fk.Name))
+	}
+	return prismaFieldLine{
+		name:  rel.field,
+		typ:   typename,
+		attrs: "@relation(" + strings.Join(args, ", ") + ")",
+	}
+}
+
+// prismaWriteEnum writes a string enumeration.  With multiple schemas, it's
+// in the schema of its lookup table or else the database's first schema
+// with a name.
+func prismaWriteEnum(buf *bytes.Buffer, db *sqlstream.Database, e *Enum, schemaNames map[*sqlstream.Schema]string) {
+	buf.WriteByte('\n')
+	prismaWriteDoc(buf, "", e.Doc)
+	buf.WriteString("enum ")
+	buf.WriteString(prismaIdentifier(e.Names))
+	buf.WriteString(" {\n")
+	for _, m := range e.Members {
+		prismaWriteDoc(buf, "  ", m.Doc)
+		name := prismaIdentifier(m.Names)
+		buf.WriteString("  ")
+		buf.WriteString(name)
+		if m.Value != name {
+			buf.WriteString(" @map(")
+			buf.WriteString(strconv.Quote(m.Value))
+			buf.WriteByte(')')
+		}
+		buf.WriteByte('\n')
+	}
+	if schemaNames != nil {
+		var schema string
+		if e.LookupTable != nil {
+			schema = schemaNames[e.LookupTable.Schema]
+		}
+		for _, x := range db.Schemas {
+			if schema == "" {
+				schema = schemaNames[x]
+			}
+		}
+		buf.WriteString("\n  @@schema(")
+		buf.WriteString(strconv.Quote(schema))
+		buf.WriteString(")\n")
+	}
+	buf.WriteString("}\n")
+}
+
+func prismaWriteDoc(buf *bytes.Buffer, indent, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		buf.WriteString(indent)
+		buf.WriteString("/// ")
+		buf.WriteString(strings.TrimSpace(line))
+		buf.WriteByte('\n')
+	}
+}
+
+// prismaDefault gets the argument of a column's @default attribute or a
+// blank string if it has no default value.
+func prismaDefault(c *sqlstream.Column) string {
+	x := ColumnExtOf(c)
+	if x.Default == "" {
+		if x.Generated {
+			t := c.Type
+			if n, ok := t.(sqltypes.Nullable); ok {
+				t = n[0]
+			}
+			if _, ok := t.(sqltypes.IntType); ok {
+				return "autoincrement()"
+			}
+			return "dbgenerated()"
+		}
+		return ""
+	}
+	d := parseSQLDefault(x.Default)
+	switch d.Kind {
+	case sqlDefaultNumber, sqlDefaultBool:
+		return d.Value
+	case sqlDefaultString:
+		if e := x.Enum; e != nil && e.IsString() {
+			for _, m := range e.Members {
+				if m.Value == d.Value {
+					return prismaIdentifier(m.Names)
+				}
+			}
+		}
+		return strconv.Quote(d.Value)
+	case sqlDefaultNow:
+		return "now()"
+	case sqlDefaultUUID:
+		return "uuid()"
+	}
+	return "dbgenerated(" + strconv.Quote(d.Value) + ")"
+}
+
+// prismaType gets the Prisma scalar type of a column's type and the native
+// type attribute that keeps its size or precision, if the provider has
+// one.
+func prismaType(provider string, t sqltypes.Type) (typename, native string, err error) {
+	if n, ok := t.(sqltypes.Nullable); ok {
+		t = n[0]
+	}
+	sqlite := provider == "sqlite"
+	switch t := t.(type) {
+	case sqltypes.BoolType:
+		return "Boolean", "", nil
+	case sqltypes.IntType:
+		switch {
+		case t.Bits <= 8 && (provider == "mysql" || provider == "sqlserver"):
+			return "Int", "@db.TinyInt", nil
+		case t.Bits <= 16 && !sqlite:
+			return "Int", "@db.SmallInt", nil
+		case t.Bits <= 32:
+			return "Int", "", nil
+		}
+		return "BigInt", "", nil
+	case sqltypes.FloatType:
+		switch {
+		case t.Mantissa > 24 || t.Mantissa == 0 || sqlite:
+			return "Float", "", nil
+		case provider == "mysql":
+			return "Float", "@db.Float", nil
+		}
+		return "Float", "@db.Real", nil
+	case sqltypes.DecimalType:
+		if t.Prec > 0 && !sqlite {
+			return "Decimal", "@db.Decimal(" + strconv.Itoa(t.Prec) + ", " + strconv.Itoa(t.Scale) + ")", nil
+		}
+		return "Decimal", "", nil
+	case sqltypes.StringType:
+		switch {
+		case sqlite:
+			return "String", "", nil
+		case t.Length <= 0:
+			switch provider {
+			case "mysql":
+				return "String", "@db.Text", nil
+			case "sqlserver":
+				return "String", "@db.NVarChar(Max)", nil
+			}
+			return "String", "", nil
+		case t.Var:
+			return "String", "@db.VarChar(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "String", "@db.Char(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.TimeType:
+		if t.Prec >= 24*time.Hour && !sqlite {
+			return "DateTime", "@db.Date", nil
+		}
+		return "DateTime", "", nil
+	case sqltypes.BytesType:
+		if t.Length > 0 && (provider == "mysql" || provider == "sqlserver") {
+			if t.Var {
+				return "Bytes", "@db.VarBinary(" + strconv.Itoa(t.Length) + ")", nil
+			}
+			return "Bytes", "@db.Binary(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "Bytes", "", nil
+	}
+	return "", "", errors.Errorf1(
+		"Unknown model type: %[1]v (type: %[1]T)",
+		t,
+	)
+}
+
+func prismaFieldNames(cs []*sqlstream.Column) string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = prismaFieldName(c.Names)
+	}
+	return strings.Join(names, ", ")
+}
+
+// schemaNames gets the names of the database's schemas in @@schema
+// attributes or nil if no schema has a SQL name.  Schemas without SQL
+// names are the provider's default schema.
+func (mc prismaModelContext) schemaNames(db *sqlstream.Database) (map[*sqlstream.Schema]string, error) {
+	var named, unnamed bool
+	for _, sch := range db.Schemas {
+		switch {
+		case sch.SQLName != "":
+			named = true
+		case len(sch.Tables) > 0:
+			unnamed = true
+		}
+	}
+	if !named {
+		return nil, nil
+	}
+	def, ok := prismaDefaultSchemas[mc.provider]
+	if mc.provider == "mysql" {
+		def, ok = db.SQLName, db.SQLName != ""
+	}
+	if unnamed && !ok {
+		return nil, errors.Errorf1(
+			"tables without a schema cannot be written with "+
+				"tables in schemas for the %q provider",
+			mc.provider,
+		)
+	}
+	names := make(map[*sqlstream.Schema]string, len(db.Schemas))
+	for _, sch := range db.Schemas {
+		names[sch] = sch.SQLName
+		if sch.SQLName == "" {
+			names[sch] = def
+		}
+	}
+	return names, nil
+}
+
+// prismaModelNames gets the names of the database's models:  Their
+// identifiers or, when tables in different schemas have the same one,
+// their identifiers after their schemas'.
+func prismaModelNames(db *sqlstream.Database) map[*sqlstream.Table]string {
+	counts := make(map[string]int)
+	names := make(map[*sqlstream.Table]string)
+	for _, sch := range db.Schemas {
+		for _, t := range sch.Tables {
+			names[t] = prismaIdentifier(t.Names)
+			counts[names[t]]++
+		}
+	}
+	for t, name := range names {
+		if counts[name] > 1 && t.Schema.RawName != "" {
+			names[t] = prismaIdentifier(t.Schema.Names) + "_" + name
+		}
+	}
+	return names
+}
+
+func prismaContains(names []string, name string) bool {
+	for _, x := range names {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+// prismaFieldName gets the camel case name of a field from its model name
+// (e.g. "CustomerID" becomes "customerID" and "URLPath" becomes
+// "urlPath").
+func prismaFieldName(ns sqlstream.Names) string {
+	rs := []rune(prismaIdentifier(ns))
+	for i := 0; i < len(rs) && unicode.IsUpper(rs[i]); i++ {
+		if i > 0 && i+1 < len(rs) && unicode.IsLower(rs[i+1]) {
+			break
+		}
+		rs[i] = unicode.ToLower(rs[i])
+	}
+	return string(rs)
+}
+
+// prismaIdentifier gets an identifier from a model name or, if there isn't
+// one, a raw name.
+func prismaIdentifier(ns sqlstream.Names) string {
+	name := ns.ModelName
+	if name == "" {
+		name = ns.RawName
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// prismaUniqueName gets the first of the candidate names that isn't taken
+// (or the first one with a number after it) and takes it.
+func prismaUniqueName(taken map[string]bool, candidates ...string) string {
+	for _, name := range candidates {
+		if !taken[name] {
+			taken[name] = true
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		name := candidates[0] + strconv.Itoa(i)
+		if !taken[name] {
+			taken[name] = true
+			return name
+		}
+	}
+}
+
+type prismaConfigParser struct {
+	database string
+}
+
+func (prismaConfigParser) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc prismaConfigParser) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[prismaDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+func (mc prismaConfigParser) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := mc.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+func (mc prismaConfigParser) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return cfg, errors.Errorf1From(
+			err, "failed to read all bytes from %v", r,
+		)
+	}
+	src := string(bs)
+	toks, err := tokenize(src, &prismaSyntax)
+	if err != nil {
+		return cfg, err
+	}
+	p := &prismaParser{
+		tokenScanner: tokenScanner{src: src, toks: toks},
+		provider:     "postgresql",
+	}
+	if err = p.parse(); err != nil {
+		return cfg, err
+	}
+	return p.config(mc.database)
+}
+
+// prismaSyntax is the lexical syntax of Prisma schemas.  Numbers are
+// words.
+var prismaSyntax = tokenSyntax{
+	lineComment: "//",
+	docComment:  "///",
+	puncts: []string{
+		"@@", "{", "}", "[", "]", "(", ")", ",", ":", "?", "=", ".", "@",
+	},
+	isWordRune: prismaIsWordRune,
+	scan:       prismaScan,
+}
+
+func prismaIsWordRune(r rune) bool {
+	return r == '_' || r >= 0x80 || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// prismaScan scans the string or number at the start of src.
+func prismaScan(src string) (kind tokenKind, text string, n int, err error) {
+	c := src[0]
+	switch {
+	case c == '"':
+		j := 1
+		for j < len(src) && src[j] != '"' && src[j] != '\n' {
+			if src[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(src) || src[j] != '"' {
+			return 0, "", 0, errors.Errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(src[:j+1])
+		if err != nil {
+			return 0, "", 0, errors.Errorf1From(err, "invalid string %v", src[:j+1])
+		}
+		return stringToken, s, j + 1, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		j := 0
+		if c == '-' {
+			j++
+		}
+		start := j
+		for j < len(src) {
+			r := rune(src[j])
+			if prismaIsWordRune(r) || (r == '.' && j > start && j+1 < len(src) && unicode.IsDigit(rune(src[j+1]))) {
+				j++
+				continue
+			}
+			break
+		}
+		if j == start {
+			return 0, "", 0, errors.Errorf1("unexpected character %q", c)
+		}
+		return wordToken, src[:j], j, nil
+	}
+	return 0, "", 0, nil
+}
+
+// prismaValue is an attribute or an argument of an attribute:  A word
+// (e.g. a name, number or enum member), a string, a function call (e.g.
+// "@db.VarChar(255)", "now()") or an array.
+type prismaValue struct {
+	Word   string
+	String *string
+	Array  []prismaValue
+
+	// Args are a function call's arguments.  They're nil if the value
+	// isn't a call.
+	Args []prismaArg
+}
+
+type prismaArg struct {
+	Name  string
+	Value prismaValue
+}
+
+// arg gets the named argument of a call or the positional argument at
+// index i if the call doesn't have the named argument.
+func (v prismaValue) arg(name string, i int) (prismaValue, bool) {
+	pos := 0
+	for _, a := range v.Args {
+		if a.Name == name {
+			return a.Value, true
+		}
+		if a.Name == "" {
+			if pos == i {
+				return a.Value, true
+			}
+			pos++
+		}
+	}
+	return prismaValue{}, false
+}
+
+// words gets the words of an array of fields, e.g. [a, b(sort: Desc)].
+func (v prismaValue) words() []string {
+	words := make([]string, len(v.Array))
+	for i, x := range v.Array {
+		words[i] = x.Word
+	}
+	return words
+}
+
+type prismaModel struct {
+	name, doc string
+	fields    []*prismaField
+	attrs     []prismaValue
+	line      int
+}
+
+type prismaField struct {
+	name, typ, doc string
+	optional, list bool
+	attrs          []prismaValue
+}
+
+type prismaEnum struct {
+	name, doc string
+	members   []config.CommonData
+	values    []string
+}
+
+type prismaParser struct {
+	tokenScanner
+	doc []string
+
+	provider string
+	models   []*prismaModel
+	enums    []*prismaEnum
+}
+
+func (p *prismaParser) word() (string, error) {
+	t := p.tok()
+	if t.Kind != wordToken {
+		return "", p.unexpected("a name")
+	}
+	p.next()
+	return t.Text, nil
+}
+
+// takeDoc gets the doc comments before the current token.
+func (p *prismaParser) takeDoc() string {
+	for p.tok().Kind == docToken {
+		p.doc = append(p.doc, p.tok().Text)
+		p.next()
+	}
+	doc := strings.Join(p.doc, "\n")
+	p.doc = nil
+	return doc
+}
+
+func (p *prismaParser) parse() error {
+	for {
+		doc := p.takeDoc()
+		if p.tok().Kind == eofToken {
+			return nil
+		}
+		keyword, err := p.word()
+		if err != nil {
+			return err
+		}
+		switch keyword {
+		case "datasource", "generator":
+			err = p.parseSettings(keyword)
+		case "model":
+			err = p.parseModel(doc)
+		case "enum":
+			err = p.parseEnum(doc)
+		case "view", "type":
+			logger.Verbose2("skipping %v on line %d", keyword, p.tok().Line)
+			err = p.skipBlock()
+		default:
+			p.pos--
+			err = p.unexpected("datasource, generator, model, enum, view or type")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseSettings parses a datasource or generator block's "key = value"
+// settings.  Only the datasource's provider is kept.
+func (p *prismaParser) parseSettings(keyword string) error {
+	if _, err := p.word(); err != nil {
+		return err
+	}
+	if err := p.expectPunct("{"); err != nil {
+		return err
+	}
+	for !p.acceptPunct("}") {
+		p.takeDoc()
+		key, err := p.word()
+		if err != nil {
+			return err
+		}
+		if err = p.expectPunct("="); err != nil {
+			return err
+		}
+		v, err := p.value()
+		if err != nil {
+			return err
+		}
+		if keyword == "datasource" && key == "provider" && v.String != nil {
+			p.provider = *v.String
+		}
+	}
+	return nil
+}
+
+// value parses an attribute argument or setting value.
+func (p *prismaParser) value() (v prismaValue, err error) {
+	switch t := p.tok(); {
+	case t.Kind == stringToken:
+		p.next()
+		s := t.Text
+		return prismaValue{String: &s}, nil
+	case p.acceptPunct("["):
+		v.Array = []prismaValue{}
+		for !p.acceptPunct("]") {
+			x, err := p.value()
+			if err != nil {
+				return v, err
+			}
+			v.Array = append(v.Array, x)
+			if !p.isPunct("]") {
+				if err = p.expectPunct(","); err != nil {
+					return v, err
+				}
+			}
+		}
+		return v, nil
+	}
+	if v.Word, err = p.word(); err != nil {
+		return v, err
+	}
+	for p.isPunct(".") {
+		p.next()
+		name, err := p.word()
+		if err != nil {
+			return v, err
+		}
+		v.Word += "." + name
+	}
+	if p.acceptPunct("(") {
+		v.Args = []prismaArg{}
+		for !p.acceptPunct(")") {
+			var a prismaArg
+			if p.tok().Kind == wordToken && p.peek(1).Text == ":" {
+				a.Name = p.tok().Text
+				p.next()
+				p.next()
+			}
+			if a.Value, err = p.value(); err != nil {
+				return v, err
+			}
+			v.Args = append(v.Args, a)
+			if !p.isPunct(")") {
+				if err = p.expectPunct(","); err != nil {
+					return v, err
+				}
+			}
+		}
+	}
+	return v, nil
+}
+
+func (p *prismaParser) parseModel(doc string) (err error) {
+	m := &prismaModel{doc: doc, line: p.tok().Line}
+	if m.name, err = p.word(); err != nil {
+		return err
+	}
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	for {
+		doc := p.takeDoc()
+		if p.acceptPunct("}") {
+			break
+		}
+		if p.acceptPunct("@@") {
+			attr, err := p.value()
+			if err != nil {
+				return err
+			}
+			m.attrs = append(m.attrs, attr)
+			continue
+		}
+		f := &prismaField{doc: doc}
+		if f.name, err = p.word(); err != nil {
+			return err
+		}
+		typ, err := p.value()
+		if err != nil {
+			return err
+		}
+		if typ.Args != nil {
+			return errors.Errorf3(
+				"field %v.%v has unsupported type %v",
+				m.name, f.name, typ.Word,
+			)
+		}
+		f.typ = typ.Word
+		if p.acceptPunct("[") {
+			if err = p.expectPunct("]"); err != nil {
+				return err
+			}
+			f.list = true
+		}
+		f.optional = p.acceptPunct("?")
+		for p.acceptPunct("@") {
+			attr, err := p.value()
+			if err != nil {
+				return err
+			}
+			f.attrs = append(f.attrs, attr)
+		}
+		m.fields = append(m.fields, f)
+	}
+	p.models = append(p.models, m)
+	return nil
+}
+
+func (p *prismaParser) parseEnum(doc string) (err error) {
+	e := &prismaEnum{doc: doc}
+	if e.name, err = p.word(); err != nil {
+		return err
+	}
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	for {
+		doc := p.takeDoc()
+		if p.acceptPunct("}") {
+			break
+		}
+		if p.acceptPunct("@@") {
+			// e.g. @@map and @@schema
+			if _, err = p.value(); err != nil {
+				return err
+			}
+			continue
+		}
+		name, err := p.word()
+		if err != nil {
+			return err
+		}
+		value := name
+		for p.acceptPunct("@") {
+			attr, err := p.value()
+			if err != nil {
+				return err
+			}
+			if s, ok := attr.arg("name", 0); attr.Word == "map" && ok && s.String != nil {
+				value = *s.String
+			}
+		}
+		e.members = append(e.members, config.CommonData{
+			Names: config.Names{RawName: identifierRawName(name)},
+			Doc:   doc,
+		})
+		e.values = append(e.values, value)
+	}
+	p.enums = append(p.enums, e)
+	return nil
+}
+
+func (m *prismaModel) attr(name string) (prismaValue, bool) {
+	for _, a := range m.attrs {
+		if a.Word == name {
+			return a, true
+		}
+	}
+	return prismaValue{}, false
+}
+
+func (f *prismaField) attr(name string) (prismaValue, bool) {
+	for _, a := range f.attrs {
+		if a.Word == name {
+			return a, true
+		}
+	}
+	return prismaValue{}, false
+}
+
+// stringArg gets the string of a call's named argument or of its
+// positional argument at index i.  Use -1 for an argument that can only be
+// named.
+func (v prismaValue) stringArg(name string, i int) string {
+	if s, ok := v.arg(name, i); ok && s.String != nil {
+		return *s.String
+	}
+	return ""
+}
+
+// stringAttr gets the string argument of an attribute like @@map("name").
+func (m *prismaModel) stringAttr(name string) string {
+	a, _ := m.attr(name)
+	return a.stringArg("name", 0)
+}
+
+// stringAttr gets the string argument of an attribute like @map("name").
+func (f *prismaField) stringAttr(name string) string {
+	a, _ := f.attr(name)
+	return a.stringArg("name", 0)
+}
+
+func (p *prismaParser) model(name string) *prismaModel {
+	for _, m := range p.models {
+		if m.name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (p *prismaParser) enum(name string) *prismaEnum {
+	for _, e := range p.enums {
+		if e.name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// prismaScalarTypes are the types of Prisma's scalar fields without
+// native type attributes.
+var prismaScalarTypes = map[string]sqltypes.Type{
+	"String":   sqltypes.StringType{Var: true},
+	"Boolean":  sqltypes.BoolType{},
+	"Int":      sqltypes.IntType{Bits: 32},
+	"BigInt":   sqltypes.IntType{Bits: 64},
+	"Float":    sqltypes.FloatType{Mantissa: 53},
+	"Decimal":  sqltypes.DecimalType{},
+	"DateTime": sqltypes.TimeType{},
+	"Json":     sqltypes.StringType{Var: true},
+	"Bytes":    sqltypes.BytesType{Var: true},
+}
+
+// fieldType gets the type of a scalar field from its native type
+// attribute (e.g. @db.VarChar(255)) or else its Prisma type.
+func (p *prismaParser) fieldType(f *prismaField) (sqltypes.Type, error) {
+	t, ok := prismaScalarTypes[f.typ]
+	if !ok {
+		return nil, errors.Errorf1("unsupported type: %q", f.typ)
+	}
+	dialectName, ok := prismaDialectNames[p.provider]
+	if !ok {
+		dialectName = "postgres"
+	}
+	for _, a := range f.attrs {
+		if !strings.HasPrefix(a.Word, "db.") {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(a.Word, "db."))
+		switch name {
+		case "doubleprecision":
+			name = "double precision"
+		case "unsignedtinyint", "unsignedsmallint", "unsignedmediumint", "unsignedint", "unsignedbigint":
+			name = strings.TrimPrefix(name, "unsigned")
+		}
+		args := make([]string, len(a.Args))
+		for i, arg := range a.Args {
+			args[i] = strings.ToLower(arg.Value.Word)
+		}
+		nt, _, err := sqlDDLType(dialectName, name, args)
+		if err != nil {
+			logger.Verbose2("using %v for native type %v", f.typ, a.Word)
+			break
+		}
+		t = nt
+	}
+	return t, nil
+}
+
+// config creates the model configuration from the parsed schema.  Models
+// without a @@schema are in a schema without a name.
+func (p *prismaParser) config(database string) (cfg Config, err error) {
+	dbCfg := config.Database{CommonData: config.CommonData{Names: config.Names{RawName: database}}}
+	for _, e := range p.enums {
+		enumCfg := EnumConfig{
+			CommonData: config.CommonData{
+				Names: config.Names{RawName: identifierRawName(e.name)},
+				Doc:   e.doc,
+			},
+			Type: sqltypes.StringType{Var: true}.String(),
+		}
+		for i, m := range e.members {
+			value, _ := json.Marshal(e.values[i])
+			enumCfg.Members = append(enumCfg.Members, EnumMemberConfig{CommonData: m, Value: value})
+		}
+		x := cfg.Ext.findDatabase(database)
+		if x == nil {
+			cfg.Ext.Databases = append(cfg.Ext.Databases, DatabaseConfigExt{RawName: database})
+			x = &cfg.Ext.Databases[len(cfg.Ext.Databases)-1]
+		}
+		x.Enums = append(x.Enums, enumCfg)
+	}
+	schemas := make(map[string]int)
+	for _, m := range p.models {
+		schName := m.stringAttr("schema")
+		i, ok := schemas[schName]
+		if !ok {
+			i = len(dbCfg.Schemas)
+			schemas[schName] = i
+			dbCfg.Schemas = append(dbCfg.Schemas, config.Schema{
+				CommonData: sqlDDLCommonData(schName),
+			})
+		}
+		tblCfg, err := p.tableConfig(&cfg.Ext, database, dbCfg.Schemas[i].RawName, m)
+		if err != nil {
+			return cfg, errors.Errorf1From(
+				err, "failed to configure model %v", m.name,
+			)
+		}
+		dbCfg.Schemas[i].Tables = append(dbCfg.Schemas[i].Tables, tblCfg)
+	}
+	cfg.Databases = []config.Database{dbCfg}
+	for _, m := range p.models {
+		for _, f := range m.fields {
+			if err = p.addRelation(&cfg, m, f); err != nil {
+				return cfg, errors.Errorf2From(
+					err, "invalid relation %v.%v", m.name, f.name,
+				)
+			}
+		}
+	}
+	return
+}
+
+// columnRawName gets the raw name of a field's column.
+func (m *prismaModel) columnRawName(name string) (string, error) {
+	for _, f := range m.fields {
+		if f.name == name {
+			return identifierRawName(name), nil
+		}
+	}
+	return "", errors.Errorf2("model %v has no field %q", m.name, name)
+}
+
+func (p *prismaParser) tableConfig(ext *ConfigExt, dbRawName, schRawName string, m *prismaModel) (tblCfg config.Table, err error) {
+	tblCfg.CommonData = config.CommonData{
+		Names: config.Names{
+			RawName: identifierRawName(m.name),
+			SQLName: m.name,
+		},
+		Doc: m.doc,
+	}
+	if sqlName := m.stringAttr("map"); sqlName != "" {
+		tblCfg.SQLName = sqlName
+	}
+	var pk []string
+	if id, ok := m.attr("id"); ok {
+		if fields, ok := id.arg("fields", 0); ok {
+			pk = fields.words()
+		}
+	}
+	for _, f := range m.fields {
+		if p.model(f.typ) != nil {
+			// relation fields are added by addRelation
+			continue
+		}
+		if f.list {
+			return tblCfg, errors.Errorf1(
+				"list field %v is not supported", f.name,
+			)
+		}
+		colCfg := config.Column{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: identifierRawName(f.name),
+					SQLName: f.name,
+				},
+				Doc: f.doc,
+			},
+		}
+		if sqlName := f.stringAttr("map"); sqlName != "" {
+			colCfg.SQLName = sqlName
+		}
+		_, colCfg.PK = f.attr("id")
+		for _, name := range pk {
+			colCfg.PK = colCfg.PK || name == f.name
+		}
+		var typ sqltypes.Type
+		e := p.enum(f.typ)
+		if e != nil {
+			typ = sqltypes.StringType{Var: true}
+			ext.Column(dbRawName, schRawName, tblCfg.RawName, colCfg.RawName).Enum = identifierRawName(e.name)
+		} else if typ, err = p.fieldType(f); err != nil {
+			return tblCfg, errors.Errorf1From(
+				err, "invalid type of field %v", f.name,
+			)
+		}
+		if f.optional {
+			typ = sqltypes.Nullable{typ}
+		}
+		colCfg.Type = typ.String()
+		if def, ok := f.attr("default"); ok {
+			generated, value := prismaSQLDefault(def, e)
+			if generated || value != "" {
+				x := ext.Column(dbRawName, schRawName, tblCfg.RawName, colCfg.RawName)
+				x.Generated = generated
+				x.Default = value
+			}
+		}
+		if unique, ok := f.attr("unique"); ok {
+			x := ext.Table(dbRawName, schRawName, tblCfg.RawName)
+			x.Indexes = append(x.Indexes, IndexConfig{
+				Name:    unique.stringArg("map", 0),
+				Unique:  true,
+				Columns: []IndexColumnConfig{{RawName: colCfg.RawName}},
+			})
+		}
+		tblCfg.Columns = append(tblCfg.Columns, colCfg)
+	}
+	for _, a := range m.attrs {
+		if a.Word != "index" && a.Word != "unique" {
+			continue
+		}
+		ixCfg := IndexConfig{Unique: a.Word == "unique"}
+		if name, ok := a.arg("map", -1); ok && name.String != nil {
+			ixCfg.Name = *name.String
+		}
+		fields, _ := a.arg("fields", 0)
+		for _, f := range fields.Array {
+			ic := IndexColumnConfig{}
+			if ic.RawName, err = m.columnRawName(f.Word); err != nil {
+				return tblCfg, err
+			}
+			if sort, ok := f.arg("sort", -1); ok && sort.Word == "Desc" {
+				ic.Order = "desc"
+			}
+			ixCfg.Columns = append(ixCfg.Columns, ic)
+		}
+		x := ext.Table(dbRawName, schRawName, tblCfg.RawName)
+		x.Indexes = append(x.Indexes, ixCfg)
+	}
+	return tblCfg, nil
+}
+
+// prismaSQLDefault gets the SQL default value of a field's @default
+// attribute or if its value is generated by the database.
+func prismaSQLDefault(def prismaValue, e *prismaEnum) (generated bool, value string) {
+	v, ok := def.arg("value", 0)
+	if !ok {
+		return false, ""
+	}
+	switch {
+	case v.String != nil:
+		return false, "'" + strings.ReplaceAll(*v.String, "'", "''") + "'"
+	case v.Args == nil && v.Array == nil:
+		if e != nil {
+			for i, m := range e.members {
+				if identifierRawName(v.Word) == m.RawName {
+					return false, "'" + strings.ReplaceAll(e.values[i], "'", "''") + "'"
+				}
+			}
+		}
+		return false, v.Word
+	}
+	switch v.Word {
+	case "autoincrement", "sequence":
+		return true, ""
+	case "now":
+		return false, "CURRENT_TIMESTAMP"
+	case "dbgenerated":
+		if expr, ok := v.arg("", 0); ok && expr.String != nil {
+			return false, *expr.String
+		}
+		return true, ""
+	}
+	// e.g. cuid() and uuid() are generated by Prisma Client, not the
+	// database.
+	logger.Verbose1("skipping default value %v()", v.Word)
+	return false, ""
+}
+
+// addRelation adds the foreign key of a relation field with fields and
+// references.  It's one-to-one if the other side of the relation isn't a
+// list.
+func (p *prismaParser) addRelation(cfg *Config, m *prismaModel, f *prismaField) error {
+	ref := p.model(f.typ)
+	if ref == nil {
+		return nil
+	}
+	rel, ok := f.attr("relation")
+	if !ok {
+		return nil
+	}
+	fields, ok := rel.arg("fields", -1)
+	if !ok {
+		// the other side of the relation
+		return nil
+	}
+	references, _ := rel.arg("references", -1)
+	if len(fields.Array) != len(references.Array) || len(fields.Array) == 0 {
+		return errors.Errorf2(
+			"%d fields reference %d fields",
+			len(fields.Array), len(references.Array),
+		)
+	}
+	relName := rel.stringArg("name", 0)
+	oneToOne := false
+	for _, bf := range ref.fields {
+		if bf.typ != m.name || bf == f {
+			continue
+		}
+		brel, _ := bf.attr("relation")
+		if _, ok := brel.arg("fields", -1); ok || brel.stringArg("name", 0) != relName {
+			continue
+		}
+		oneToOne = !bf.list
+	}
+	var pk []string
+	if id, ok := ref.attr("id"); ok {
+		if fields, ok := id.arg("fields", 0); ok {
+			pk = fields.words()
+		}
+	}
+	for _, rf := range ref.fields {
+		if _, ok := rf.attr("id"); ok {
+			pk = append(pk, rf.name)
+		}
+	}
+	cols, refCols := fields.words(), references.words()
+	refsPK := len(pk) == len(refCols)
+	for i, name := range refCols {
+		if _, err := ref.columnRawName(name); err != nil {
+			return err
+		}
+		refsPK = refsPK && pk[i] == name
+	}
+	dbRawName := cfg.Databases[0].RawName
+	schRawName := identifierRawName(m.stringAttr("schema"))
+	refSchRawName := identifierRawName(ref.stringAttr("schema"))
+	tblRawName := identifierRawName(m.name)
+	refPath := identifierRawName(ref.name)
+	if refSchRawName != schRawName {
+		refPath = refSchRawName + "." + refPath
+	}
+	constraintName := rel.stringArg("map", -1)
+	if constraintName == "" && len(cols) == 1 && refsPK {
+		colRawName, err := m.columnRawName(cols[0])
+		if err != nil {
+			return err
+		}
+		col := drawIOColumn(&cfg.Config, drawIOColumnPath{dbRawName, schRawName, tblRawName, colRawName})
+		col.FK = refPath + "." + identifierRawName(refCols[0])
+		if oneToOne {
+			cfg.Ext.Column(dbRawName, schRawName, tblRawName, colRawName).OneToOne = true
+		}
+		return nil
+	}
+	fkCfg := ForeignKeyConfig{
+		Name:       constraintName,
+		References: refPath,
+		OneToOne:   oneToOne,
+	}
+	for _, name := range cols {
+		colRawName, err := m.columnRawName(name)
+		if err != nil {
+			return err
+		}
+		fkCfg.Columns = append(fkCfg.Columns, colRawName)
+	}
+	if !refsPK {
+		for _, name := range refCols {
+			fkCfg.ReferencedColumns = append(fkCfg.ReferencedColumns, identifierRawName(name))
+		}
+	}
+	x := cfg.Ext.Table(dbRawName, schRawName, tblRawName)
+	x.ForeignKeys = append(x.ForeignKeys, fkCfg)
+	return nil
+}
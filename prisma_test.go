@@ -0,0 +1,145 @@
+package sqlmodelgen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPrismaParse(t *testing.T) {
+	testParseCases(t, PrismaConfigParserModelContext, []testParseCase{{
+		name: "models and relations",
+		src: `generator client {
+  provider = "prisma-client-js"
+}
+
+datasource db {
+  provider = "postgresql"
+  url      = env("DATABASE_URL")
+}
+
+/// is someone who buys things.
+model Customer {
+  id     Int     @id @default(autoincrement())
+  email  String? @unique @db.VarChar(128)
+  orders Order[]
+}
+
+model Order {
+  id         BigInt      @id
+  customerId Int         @map("customer_id")
+  customer   Customer    @relation(fields: [customerId], references: [id])
+  total      Decimal     @db.Decimal(10, 2)
+  status     Status      @default(pending)
+  lines      OrderLine[]
+
+  @@index([total(sort: Desc)], map: "IX_total")
+  @@map("orders")
+}
+
+model OrderLine {
+  orderId    BigInt
+  lineNumber Int    @db.SmallInt
+  order      Order  @relation(fields: [orderId], references: [id])
+
+  @@id([orderId, lineNumber])
+}
+
+// Enums become string enumerations.
+enum Status {
+  pending
+  shipped @map("SHIPPED")
+}
+`,
+		want: `Customer
+	id int32 pk
+	email nullable(varchar(128))
+	unique UX_Customer_email (email)
+orders
+	id int64 pk
+	customer_id int32
+	total decimal(10, 2)
+	status varchar(0)
+	fk (customer_id) Customer (id)
+	index IX_total (total)
+OrderLine
+	orderId int64 pk
+	lineNumber int16 pk
+	fk (orderId) orders (id)
+`,
+	}, {
+		name: "composite relation",
+		src: `model A {
+  x  Int
+  y  Int
+  bs B[]
+
+  @@id([x, y])
+}
+
+model B {
+  id Int @id
+  ax Int
+  ay Int
+  a  A   @relation(fields: [ax, ay], references: [x, y], map: "FK_B_A")
+}
+`,
+		want: `A
+	x int32 pk
+	y int32 pk
+B
+	id int32 pk
+	ax int32
+	ay int32
+	fk (ax, ay) A (x, y)
+`,
+	}, {
+		name: "unterminated string",
+		src:  "model A {\n  id Int @id @map(\"id)\n}\n",
+		err:  "line 2: unterminated string",
+	}, {
+		name: "unexpected character",
+		src:  "model A {\n  id Int @id\n  # comment\n}\n",
+		err:  `line 3: unexpected character '#'`,
+	}, {
+		name: "unknown block",
+		src:  "modle A {\n}\n",
+		err:  `line 1: expected datasource, generator, model, enum, view or type but got "modle"`,
+	}, {
+		name: "list of scalars",
+		src:  "model A {\n  id Int @id\n  tags String[]\n}\n",
+		err:  "list field tags is not supported",
+	}})
+}
+
+func TestPrismaDefaults(t *testing.T) {
+	mm, err := testParse(t, PrismaConfigParserModelContext, `model A {
+  id      Int      @id @default(autoincrement())
+  uuid    String   @default(uuid())
+  cuid    String   @default(cuid())
+  dbuuid  String   @default(dbgenerated("gen_random_uuid()"))
+  created DateTime @default(now())
+  count   Int      @default(5)
+  name    String   @default("it's")
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, c := range mm.Databases[0].Schemas[0].Tables[0].Columns {
+		x := ColumnExtOf(c)
+		fmt.Fprintf(&sb, "%v %v %q\n", c.SQLName, x.Generated, x.Default)
+	}
+	want := `id true ""
+uuid false ""
+cuid false ""
+dbuuid false "gen_random_uuid()"
+created false "CURRENT_TIMESTAMP"
+count false "5"
+name false "'it''s'"
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
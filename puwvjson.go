@@ -3,15 +3,22 @@ package sqlmodelgen
 import (
 	"bytes"
 	"encoding/json"
+	"hash/fnv"
 	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 	"time"
-	"unsafe"
 
 	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
 )
 
+// puWVJSONIDMapParam is the name of the parameter that holds the file name
+// of the ID map.
+const puWVJSONIDMapParam = "idmap"
+
 var (
 	// PUWVJSONModelContext produces a Paperless.Unity WorkView
 	// JSON model from a source model.
@@ -60,6 +67,14 @@ type puWVJSONAttribute struct {
 }
 
 func (puWVJSONContext) WriteTemplateData(w io.Writer, td TemplateData) (err error) {
+	ids := newPUWVJSONIDs()
+	idMapName := td.Parameters[puWVJSONIDMapParam]
+	if idMapName != "" {
+		if ids, err = loadPUWVJSONIDs(idMapName); err != nil {
+			return err
+		}
+	}
+	ids.rename(td.MetaModel)
 	r := puWVJSONRoot{Namespace: td.Namespace}
 	for _, db := range td.MetaModel.Databases {
 		r.Applications = append(r.Applications, puWVJSONApplication{
@@ -71,19 +86,19 @@ func (puWVJSONContext) WriteTemplateData(w io.Writer, td TemplateData) (err erro
 			for _, tbl := range sch.Tables {
 				wvApp.Classes = append(wvApp.Classes, puWVJSONClass{
 					Name: tbl.ModelName,
-					ID:   int64(uintptr(unsafe.Pointer(tbl))),
+					ID:   ids.table(tbl),
 				})
 				wvCls := &wvApp.Classes[len(wvApp.Classes)-1]
 				for _, col := range tbl.Columns {
 					wvCls.Attributes = append(wvCls.Attributes, puWVJSONAttribute{
 						Name:    col.ModelName,
-						ID:      int64(uintptr(unsafe.Pointer(col))),
+						ID:      ids.column(col),
 						ClassID: wvCls.ID,
 					})
 					wvAttr := &wvCls.Attributes[len(wvCls.Attributes)-1]
 					if col.FK != nil {
 						wvAttr.Type = "Relationship"
-						wvAttr.RelatedClassID = int64(uintptr(unsafe.Pointer(col.FK.Column.Table)))
+						wvAttr.RelatedClassID = ids.table(col.FK.Column.Table)
 						continue
 					}
 					t := col.Type
@@ -117,6 +132,11 @@ func (puWVJSONContext) WriteTemplateData(w io.Writer, td TemplateData) (err erro
 			}
 		}
 	}
+	if idMapName != "" {
+		if err = ids.save(idMapName, td.CreateFile); err != nil {
+			return err
+		}
+	}
 	bs, err := json.MarshalIndent(r, "", "\t")
 	if err != nil {
 		return errors.Errorf0From(
@@ -134,3 +154,176 @@ func (puWVJSONContext) WriteTemplateData(w io.Writer, td TemplateData) (err erro
 	}
 	return nil
 }
+
+// puWVJSONIDs maps the fully-qualified raw names of tables and columns
+// (e.g. "database.schema.table.column") to their class and attribute IDs.
+//
+// IDs are derived from the names so that they are the same every time the
+// model is generated.  The map can also be persisted in an ID map file so
+// that IDs survive renames and so that the rare hash collision is resolved
+// the same way on every run.
+type puWVJSONIDs struct {
+	byName map[string]int64
+
+	// used holds the IDs in byName so that collisions can be found
+	// without searching all of the IDs.
+	used map[int64]struct{}
+}
+
+func newPUWVJSONIDs() puWVJSONIDs {
+	return puWVJSONIDs{
+		byName: make(map[string]int64),
+		used:   make(map[int64]struct{}),
+	}
+}
+
+func loadPUWVJSONIDs(filename string) (puWVJSONIDs, error) {
+	ids := newPUWVJSONIDs()
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ids, nil
+		}
+		return ids, errors.Errorf1From(
+			err, "failed to read ID map %q", filename,
+		)
+	}
+	if err = json.Unmarshal(bs, &ids.byName); err != nil {
+		return ids, errors.Errorf1From(
+			err, "failed to parse ID map %q", filename,
+		)
+	}
+	for _, id := range ids.byName {
+		ids.used[id] = struct{}{}
+	}
+	return ids, nil
+}
+
+// save writes the ID map to a file created with createFile or, if
+// createFile is nil, os.Create.
+func (ids puWVJSONIDs) save(filename string, createFile func(string) (io.WriteCloser, error)) (err error) {
+	// encoding/json sorts the keys, so the file only changes when
+	// IDs are added.
+	bs, err := json.MarshalIndent(ids.byName, "", "\t")
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to marshal ID map %q", filename,
+		)
+	}
+	if createFile == nil {
+		createFile = func(name string) (io.WriteCloser, error) {
+			return os.Create(name)
+		}
+	}
+	f, err := createFile(filename)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to create ID map %q", filename,
+		)
+	}
+	defer errors.Catch(&err, f.Close)
+	if _, err = f.Write(append(bs, '\n')); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write ID map %q", filename,
+		)
+	}
+	return nil
+}
+
+func (ids puWVJSONIDs) table(t *sqlstream.Table) int64 {
+	name, _ := puWVJSONTableNames(t)
+	return ids.get(name)
+}
+
+func (ids puWVJSONIDs) column(c *sqlstream.Column) int64 {
+	name, _ := puWVJSONColumnNames(c)
+	return ids.get(name)
+}
+
+// puWVJSONTableNames gets the ID map name of a table and the name that it
+// had before it was renamed.
+func puWVJSONTableNames(t *sqlstream.Table) (name, prev string) {
+	prefix := t.Schema.Database.RawName + "." + t.Schema.RawName + "."
+	prev = t.RawName
+	if from := TableExtOf(t).RenamedFrom; from != "" {
+		prev = from
+	}
+	return prefix + t.RawName, prefix + prev
+}
+
+// puWVJSONColumnNames gets the ID map name of a column and the name that
+// it had before it or its table was renamed.
+func puWVJSONColumnNames(c *sqlstream.Column) (name, prev string) {
+	t, prevTable := puWVJSONTableNames(c.Table)
+	prev = c.RawName
+	if from := ColumnExtOf(c).RenamedFrom; from != "" {
+		prev = from
+	}
+	return t + "." + c.RawName, prevTable + "." + prev
+}
+
+// rename moves the IDs of renamed tables and columns to their new names.
+// It's done before any IDs are handed out so that an object that takes
+// over a renamed object's old name gets a new ID whichever of the two is
+// written first.
+func (ids puWVJSONIDs) rename(mm *sqlstream.MetaModel) {
+	type move struct{ name, prev string }
+	var moves []move
+	for _, db := range mm.Databases {
+		for _, sch := range db.Schemas {
+			for _, t := range sch.Tables {
+				name, prev := puWVJSONTableNames(t)
+				moves = append(moves, move{name, prev})
+				for _, c := range t.Columns {
+					name, prev := puWVJSONColumnNames(c)
+					moves = append(moves, move{name, prev})
+				}
+			}
+		}
+	}
+	// A name that already has an ID keeps it (e.g. the rename was
+	// already saved in the ID map), but that can change when the name
+	// is itself renamed (e.g. b was renamed to c and a to b), so keep
+	// going until nothing else moves.  Each ID moves at most once so
+	// that swapped names can't move IDs back and forth forever.
+	for moved := true; moved; {
+		moved = false
+		for i, m := range moves {
+			if m.name == m.prev {
+				continue
+			}
+			if _, ok := ids.byName[m.name]; ok {
+				continue
+			}
+			if id, ok := ids.byName[m.prev]; ok {
+				ids.byName[m.name] = id
+				delete(ids.byName, m.prev)
+				moves[i].prev = m.name
+				moved = true
+			}
+		}
+	}
+}
+
+// get gets the ID of name or a new ID hashed from name.
+func (ids puWVJSONIDs) get(name string) int64 {
+	if id, ok := ids.byName[name]; ok {
+		return id
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	// Keep IDs positive and within the integers that JSON numbers
+	// can exactly represent in any language.
+	id := int64(h.Sum64() & (1<<53 - 1))
+	for id == 0 || ids.has(id) {
+		id = (id + 1) & (1<<53 - 1)
+	}
+	ids.byName[name] = id
+	ids.used[id] = struct{}{}
+	return id
+}
+
+func (ids puWVJSONIDs) has(id int64) bool {
+	_, ok := ids.used[id]
+	return ok
+}
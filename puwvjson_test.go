@@ -0,0 +1,97 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestPUWVJSONRenamedIDs(t *testing.T) {
+	const client = `{
+		"rawName": "client",
+		"renamedFrom": "customer",
+		"columns": [
+			{"rawName": "id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "full name", "type": "string(var: true, length: 64)", "renamedFrom": "name"}
+		]
+	}`
+	const customer = `{
+		"rawName": "customer",
+		"columns": [
+			{"rawName": "id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "name", "type": "string(var: true, length: 64)"}
+		]
+	}`
+	for _, tc := range []struct {
+		name   string
+		tables string
+	}{
+		{"renamed first", client + ", " + customer},
+		{"reused first", customer + ", " + client},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			idMap := filepath.Join(t.TempDir(), "ids.json")
+			err := ioutil.WriteFile(idMap, []byte(`{
+				"shop.sales.customer": 100,
+				"shop.sales.customer.id": 101,
+				"shop.sales.customer.name": 102
+			}`), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mm := testMetaModel(t, `{"databases": [{"rawName": "shop", "schemas": [{
+				"rawName": "sales", "tables": [`+tc.tables+`]
+			}]}]}`)
+			td, err := TemplateDataFromMetaModel(mm, PUWVJSONModelContext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			td.Parameters[puWVJSONIDMapParam] = idMap
+			var buf bytes.Buffer
+			if err = PUWVJSONModelContext.WriteTemplateData(&buf, td); err != nil {
+				t.Fatal(err)
+			}
+			var r puWVJSONRoot
+			if err = json.Unmarshal(buf.Bytes(), &r); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int64)
+			seen := make(map[int64]string)
+			for _, cls := range r.Applications[0].Classes {
+				ids := map[string]int64{cls.Name: cls.ID}
+				for _, a := range cls.Attributes {
+					ids[cls.Name+"."+a.Name] = a.ID
+				}
+				for name, id := range ids {
+					if other, ok := seen[id]; ok {
+						t.Fatalf("%v and %v both have ID %d", other, name, id)
+					}
+					seen[id] = name
+					got[name] = id
+				}
+			}
+			for name, want := range map[string]int64{
+				"Client":          100,
+				"Client.Id":       101,
+				"Client.FullName": 102,
+			} {
+				if got[name] != want {
+					t.Errorf("%v has ID %d, want %d", name, got[name], want)
+				}
+			}
+			bs, err := ioutil.ReadFile(idMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var saved map[string]int64
+			if err = json.Unmarshal(bs, &saved); err != nil {
+				t.Fatal(err)
+			}
+			if n := saved["shop.sales.customer"]; n == 100 || n != got["Customer"] {
+				t.Errorf("saved customer ID %d, wrote %d", n, got["Customer"])
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package sqlmodelgen
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skillian/expr/errors"
+)
+
+// tokenKind is the kind of a token that the SQL DDL, DBML and Prisma
+// parsers parse.  Not every language has every kind.
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+
+	// wordToken is a keyword, an unquoted identifier or, in Prisma, a
+	// number.
+	wordToken
+
+	// quotedToken is a quoted identifier (e.g. "name", [name] or
+	// `name` in SQL or "name" in DBML).
+	quotedToken
+
+	stringToken
+	numberToken
+
+	// exprToken is a DBML `expression`.
+	exprToken
+
+	// docToken is a Prisma /// doc comment.
+	docToken
+
+	// punctToken is punctuation or an operator.
+	punctToken
+)
+
+type scanToken struct {
+	Kind tokenKind
+
+	// Text is the text of the token.  Quoted identifiers and strings
+	// are unquoted and doc comments don't have their slashes.
+	Text string
+
+	// Start and End are the offsets of the token in the source and
+	// Line is the line that it starts on.
+	Start, End int
+	Line       int
+}
+
+// tokenSyntax is what tokenize needs to know about a language.
+type tokenSyntax struct {
+	// lineComment starts a comment that ends at the end of the line
+	// (e.g. "--").  Block comments are always "/* ... */".
+	lineComment string
+
+	// docComment, if set, starts a line comment that's kept as a
+	// docToken.
+	docComment string
+
+	// puncts are the language's punctuation, longest first.
+	puncts []string
+
+	isWordRune func(r rune) bool
+
+	// scan scans the language's strings, quoted identifiers, etc. at
+	// the start of src.  It returns n == 0 if there isn't one.  Its
+	// errors don't need the line number; tokenize adds it.
+	scan func(src string) (kind tokenKind, text string, n int, err error)
+
+	// otherPunct makes any other character a punctToken instead of an
+	// error.
+	otherPunct bool
+}
+
+// tokenize splits source code into tokens, skipping whitespace and
+// comments.  The last token is always an eofToken.
+func tokenize(src string, syn *tokenSyntax) ([]scanToken, error) {
+	toks := make([]scanToken, 0, len(src)/4)
+	line := 1
+	emit := func(kind tokenKind, text string, start, end int) {
+		toks = append(toks, scanToken{kind, text, start, end, line})
+		line += strings.Count(src[start:end], "\n")
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case syn.docComment != "" && strings.HasPrefix(src[i:], syn.docComment):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				end = len(src) - i
+			}
+			text := strings.TrimSpace(src[i+len(syn.docComment) : i+end])
+			emit(docToken, text, i, i+end)
+			i += end
+			continue
+		case syn.lineComment != "" && strings.HasPrefix(src[i:], syn.lineComment):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, errors.Errorf1(
+					"line %d: unterminated comment", line,
+				)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += 2 + end + 2
+			continue
+		}
+		if syn.scan != nil {
+			kind, text, n, err := syn.scan(src[i:])
+			if err != nil {
+				return nil, errors.Errorf2("line %d: %v", line, err)
+			}
+			if n > 0 {
+				emit(kind, text, i, i+n)
+				i += n
+				continue
+			}
+		}
+		if p := tokenPunct(src[i:], syn.puncts); p != "" {
+			emit(punctToken, p, i, i+len(p))
+			i += len(p)
+			continue
+		}
+		j := i
+		for j < len(src) {
+			r, n := utf8.DecodeRuneInString(src[j:])
+			if !syn.isWordRune(r) {
+				break
+			}
+			j += n
+		}
+		switch {
+		case j > i:
+			emit(wordToken, src[i:j], i, j)
+		case syn.otherPunct:
+			_, n := utf8.DecodeRuneInString(src[i:])
+			j = i + n
+			emit(punctToken, src[i:j], i, j)
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, errors.Errorf2(
+				"line %d: unexpected character %q", line, r,
+			)
+		}
+		i = j
+	}
+	toks = append(toks, scanToken{Kind: eofToken, Start: len(src), End: len(src), Line: line})
+	return toks, nil
+}
+
+// tokenPunct gets the first of puncts that src starts with.
+func tokenPunct(src string, puncts []string) string {
+	for _, p := range puncts {
+		if strings.HasPrefix(src, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// tokenScanner is the cursor that parsers move through their tokens with.
+type tokenScanner struct {
+	src  string
+	toks []scanToken
+	pos  int
+}
+
+func (s *tokenScanner) tok() scanToken { return s.toks[s.pos] }
+
+// peek gets the token offset from the current token or the eofToken.
+func (s *tokenScanner) peek(offset int) scanToken {
+	if s.pos+offset >= len(s.toks) {
+		return s.toks[len(s.toks)-1]
+	}
+	return s.toks[s.pos+offset]
+}
+
+func (s *tokenScanner) next() {
+	if s.pos < len(s.toks)-1 {
+		s.pos++
+	}
+}
+
+// isWord checks if the current token is the given case-insensitive word.
+func (s *tokenScanner) isWord(word string) bool {
+	return s.isWordAt(0, word)
+}
+
+// isWordAt checks if the token offset from the current token is the given
+// case-insensitive word.
+func (s *tokenScanner) isWordAt(offset int, word string) bool {
+	t := s.peek(offset)
+	return t.Kind == wordToken && strings.EqualFold(t.Text, word)
+}
+
+func (s *tokenScanner) isPunct(p string) bool {
+	t := s.tok()
+	return t.Kind == punctToken && t.Text == p
+}
+
+func (s *tokenScanner) acceptPunct(p string) bool {
+	if s.isPunct(p) {
+		s.next()
+		return true
+	}
+	return false
+}
+
+func (s *tokenScanner) expectPunct(p string) error {
+	if s.acceptPunct(p) {
+		return nil
+	}
+	return s.unexpected(strconv.Quote(p))
+}
+
+func (s *tokenScanner) unexpected(expected string) error {
+	t := s.tok()
+	if t.Kind == eofToken {
+		return errors.Errorf2(
+			"line %d: expected %v but reached the end",
+			t.Line, expected,
+		)
+	}
+	return errors.Errorf3(
+		"line %d: expected %v but got %q",
+		t.Line, expected, s.src[t.Start:t.End],
+	)
+}
+
+// skipBlock skips everything up to and including the next block's closing
+// brace.
+func (s *tokenScanner) skipBlock() error {
+	for !s.acceptPunct("{") {
+		if s.tok().Kind == eofToken {
+			return s.unexpected(`"{"`)
+		}
+		s.next()
+	}
+	for depth := 1; depth > 0; s.next() {
+		switch {
+		case s.tok().Kind == eofToken:
+			return s.unexpected(`"}"`)
+		case s.isPunct("{"):
+			depth++
+		case s.isPunct("}"):
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sqlmodelgen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		syn  *tokenSyntax
+		src  string
+		want string
+		err  string
+	}{{
+		name: "sql",
+		syn:  &sqlDDLSyntax,
+		src:  "CREATE TABLE [dbo].\"t\" (\n\t-- comment\n\tc INT /* x */ DEFAULT N'it''s' >= 1.5\n);",
+		want: "1 word CREATE|1 word TABLE|1 quoted dbo|1 punct .|1 quoted t|1 punct (|" +
+			"3 word c|3 word INT|3 word DEFAULT|3 string it's|3 punct >=|3 number 1.5|" +
+			"4 punct )|4 punct ;|4 eof ",
+	}, {
+		name: "prisma",
+		syn:  &prismaSyntax,
+		src:  "/// doc\nmodel A {\n  id Int @id @@map(\"a\") // comment\n}",
+		want: "1 doc doc|2 word model|2 word A|2 punct {|3 word id|3 word Int|3 punct @|3 word id|" +
+			"3 punct @@|3 word map|3 punct (|3 string a|3 punct )|4 punct }|4 eof ",
+	}, {
+		name: "dbml",
+		syn:  &dbmlSyntax,
+		src:  "Ref: a.b <> c.d [note: '''x''']",
+		want: "1 word Ref|1 punct :|1 word a|1 punct .|1 word b|1 punct <>|1 word c|1 punct .|" +
+			"1 word d|1 punct [|1 word note|1 punct :|1 string x|1 punct ]|1 eof ",
+	}, {
+		name: "unterminated comment",
+		syn:  &sqlDDLSyntax,
+		src:  "\n/* no end",
+		err:  "line 2: unterminated comment",
+	}, {
+		name: "unexpected character",
+		syn:  &prismaSyntax,
+		src:  "model A {\n  #\n}",
+		err:  "line 2: unexpected character '#'",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			toks, err := tokenize(tc.src, tc.syn)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			descs := make([]string, len(toks))
+			for i, tok := range toks {
+				descs[i] = fmt.Sprintf("%d %v %v", tok.Line, testTokenKindName(tok.Kind), tok.Text)
+			}
+			if got := strings.Join(descs, "|"); got != tc.want {
+				t.Fatalf("got:\n%v\nwant:\n%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func testTokenKindName(k tokenKind) string {
+	return [...]string{"eof", "word", "quoted", "string", "number", "expr", "doc", "punct"}[k]
+}
+
+func TestTokenScannerSkipBlock(t *testing.T) {
+	src := "BEGIN {\n  a = { b = 1 }\n}\nEND"
+	toks, err := tokenize(src, &sqlDDLSyntax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tokenScanner{src: src, toks: toks}
+	if err = s.skipBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.isWord("end") {
+		t.Fatalf("skipped to %q, want END", s.tok().Text)
+	}
+	s.next()
+	if err = s.expectPunct("{"); err == nil || err.Error() != `line 4: expected "{" but reached the end` {
+		t.Fatalf("got error %v", err)
+	}
+}
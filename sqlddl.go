@@ -3,6 +3,7 @@ package sqlmodelgen
 import (
 	"embed"
 	"io/fs"
+	"text/template"
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream"
@@ -10,8 +11,10 @@ import (
 )
 
 var (
-	MSSQLDDLModelContext     = mustNewSQLDDLModelContext("mssql")
-	SQLiteSQLDDLModelContext = mustNewSQLDDLModelContext("sqlite3")
+	MSSQLDDLModelContext       = mustNewSQLDDLModelContext("mssql")
+	SQLiteSQLDDLModelContext   = mustNewSQLDDLModelContext("sqlite3")
+	PostgresSQLDDLModelContext = newSQLDDLModelContext("postgres", postgresDialect{})
+	MySQLSQLDDLModelContext    = newSQLDDLModelContext("mysql", mysqlDialect{})
 
 	_ interface {
 		ModelContext
@@ -33,7 +36,14 @@ var (
 // sqlDDLModelContext is the implementation of the Go language model generator.
 type sqlDDLModelContext struct {
 	dialectName string
-	dialect     sqlstream.Dialect
+	dialect     sqlDDLDialect
+}
+
+// sqlDDLDialect is the part of a sqlstream.Dialect that sqlDDLModelContext
+// needs.  It lets DDL be generated for dialects that sqlstream doesn't
+// (yet) support.
+type sqlDDLDialect interface {
+	DataTypeName(t sqltypes.Type) (string, error)
 }
 
 func mustNewSQLDDLModelContext(dialectName string) *sqlDDLModelContext {
@@ -44,6 +54,10 @@ func mustNewSQLDDLModelContext(dialectName string) *sqlDDLModelContext {
 			dialectName,
 		))
 	}
+	return newSQLDDLModelContext(dialectName, d)
+}
+
+func newSQLDDLModelContext(dialectName string, d sqlDDLDialect) *sqlDDLModelContext {
 	return &sqlDDLModelContext{
 		dialectName: dialectName,
 		dialect:     d,
@@ -66,3 +80,12 @@ func (mc sqlDDLModelContext) ModelType(t sqltypes.Type) (namespace, typename str
 	typename, err = mc.dialect.DataTypeName(t)
 	return
 }
+
+func (mc sqlDDLModelContext) AddFuncs(m template.FuncMap) {
+	m["sqldefault"] = func(c *sqlstream.Column) string {
+		return sqlDefaultExpression(mc.dialectName, c)
+	}
+	m["checkexpr"] = func(ck *Check) string {
+		return sqlCheckExpression(mc.dialectName, ck)
+	}
+}
@@ -0,0 +1,36 @@
+package sqlmodelgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// testNullDialect names types like postgresDialect but, like a dialect
+// that reflects columns' nullability, names nullable types with "NULL".
+type testNullDialect struct{}
+
+func (testNullDialect) DataTypeName(t sqltypes.Type) (string, error) {
+	if sqltypes.IsNullable(t) {
+		name, err := postgresDialect{}.DataTypeName(t.(sqltypes.Nullable)[0])
+		return name + " NULL", err
+	}
+	return postgresDialect{}.DataTypeName(t)
+}
+
+func TestMSSQLTableNullability(t *testing.T) {
+	mm := testMetaModel(t, testModelJSON)
+	ddl := testWrite(t, newSQLDDLModelContext("mssql", testNullDialect{}), mm)
+	for _, want := range []string{
+		`"CustomerId" integer NOT NULL`,
+		`"Email" varchar(128) NULL,`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Fatalf("missing %q in:\n%v", want, ddl)
+		}
+	}
+	if strings.Contains(ddl, "NULL NULL") {
+		t.Fatalf("nullability written twice in:\n%v", ddl)
+	}
+}
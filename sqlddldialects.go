@@ -0,0 +1,142 @@
+package sqlmodelgen
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// postgresDialect maps sqltypes to PostgreSQL data types.  Nullability is
+// not part of the data type; the templates emit NOT NULL separately.
+type postgresDialect struct{}
+
+func (d postgresDialect) DataTypeName(t sqltypes.Type) (string, error) {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		return d.DataTypeName(t[0])
+	case sqltypes.BoolType:
+		return "boolean", nil
+	case sqltypes.IntType:
+		switch {
+		case t.Bits <= 16:
+			return "smallint", nil
+		case t.Bits <= 32:
+			return "integer", nil
+		case t.Bits <= 64:
+			return "bigint", nil
+		}
+		return "", errors.Errorf1(
+			"int with %d bits not supported",
+			t.Bits)
+	case sqltypes.FloatType:
+		switch {
+		case t.Mantissa <= 24:
+			return "real", nil
+		case t.Mantissa <= 53:
+			return "double precision", nil
+		}
+		return "", errors.Errorf1(
+			"float with %d mantissa bits not "+
+				"supported", t.Mantissa)
+	case sqltypes.DecimalType:
+		switch {
+		case t.Prec > 0 && t.Scale > 0:
+			return "numeric(" + strconv.Itoa(t.Prec) + ", " + strconv.Itoa(t.Scale) + ")", nil
+		case t.Prec > 0:
+			return "numeric(" + strconv.Itoa(t.Prec) + ")", nil
+		}
+		return "numeric", nil
+	case sqltypes.StringType:
+		switch {
+		case t.Length <= 0:
+			return "text", nil
+		case t.Var:
+			return "varchar(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "char(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.TimeType:
+		if t.Prec >= 24*time.Hour {
+			return "date", nil
+		}
+		return "timestamp", nil
+	case sqltypes.BytesType:
+		return "bytea", nil
+	}
+	return "", errors.Errorf1(
+		"Unknown model type: %[1]v (type: %[1]T)",
+		t,
+	)
+}
+
+// mysqlDialect maps sqltypes to MySQL and MariaDB data types.
+// Nullability is not part of the data type; the templates emit NOT NULL
+// separately.
+type mysqlDialect struct{}
+
+func (d mysqlDialect) DataTypeName(t sqltypes.Type) (string, error) {
+	switch t := t.(type) {
+	case sqltypes.Nullable:
+		return d.DataTypeName(t[0])
+	case sqltypes.BoolType:
+		return "BOOLEAN", nil
+	case sqltypes.IntType:
+		switch {
+		case t.Bits <= 8:
+			return "TINYINT", nil
+		case t.Bits <= 16:
+			return "SMALLINT", nil
+		case t.Bits <= 32:
+			return "INT", nil
+		case t.Bits <= 64:
+			return "BIGINT", nil
+		}
+		return "", errors.Errorf1(
+			"int with %d bits not supported",
+			t.Bits)
+	case sqltypes.FloatType:
+		switch {
+		case t.Mantissa <= 24:
+			return "FLOAT", nil
+		case t.Mantissa <= 53:
+			return "DOUBLE", nil
+		}
+		return "", errors.Errorf1(
+			"float with %d mantissa bits not "+
+				"supported", t.Mantissa)
+	case sqltypes.DecimalType:
+		switch {
+		case t.Prec > 0 && t.Scale > 0:
+			return "DECIMAL(" + strconv.Itoa(t.Prec) + ", " + strconv.Itoa(t.Scale) + ")", nil
+		case t.Prec > 0:
+			return "DECIMAL(" + strconv.Itoa(t.Prec) + ")", nil
+		}
+		return "DECIMAL", nil
+	case sqltypes.StringType:
+		switch {
+		case t.Length <= 0:
+			return "LONGTEXT", nil
+		case t.Var:
+			return "VARCHAR(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "CHAR(" + strconv.Itoa(t.Length) + ")", nil
+	case sqltypes.TimeType:
+		if t.Prec >= 24*time.Hour {
+			return "DATE", nil
+		}
+		return "DATETIME", nil
+	case sqltypes.BytesType:
+		switch {
+		case t.Length <= 0:
+			return "LONGBLOB", nil
+		case t.Var:
+			return "VARBINARY(" + strconv.Itoa(t.Length) + ")", nil
+		}
+		return "BINARY(" + strconv.Itoa(t.Length) + ")", nil
+	}
+	return "", errors.Errorf1(
+		"Unknown model type: %[1]v (type: %[1]T)",
+		t,
+	)
+}
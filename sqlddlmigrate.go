@@ -0,0 +1,728 @@
+package sqlmodelgen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+// migratePreviousParam is the name of the parameter that holds the file
+// name of the previous models.json file to migrate from.
+const migratePreviousParam = "previous"
+
+var (
+	// MSSQLDDLMigrateModelContext generates a Microsoft SQL Server
+	// script that migrates a database from a previous model (specified
+	// with the "previous" parameter) to the current model.
+	MSSQLDDLMigrateModelContext interface {
+		ModelContext
+		TemplateDataWriter
+	} = sqlDDLMigrateModelContext{ddl: MSSQLDDLModelContext}
+)
+
+// sqlDDLMigrateModelContext writes migration scripts.  It uses the
+// templates of its SQL DDL model context to create new databases and
+// tables so that they are created exactly as they would be from scratch.
+type sqlDDLMigrateModelContext struct {
+	ddl *sqlDDLModelContext
+}
+
+func (mc sqlDDLMigrateModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return mc.ddl.ModelType(t)
+}
+
+func (mc sqlDDLMigrateModelContext) WriteTemplateData(w io.Writer, td TemplateData) (err error) {
+	filename, ok := td.Parameters[migratePreviousParam]
+	if !ok {
+		return errors.Errorf1(
+			"the previous model is required.  Please use the "+
+				"%q parameter",
+			migratePreviousParam,
+		)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to open previous model %q", filename,
+		)
+	}
+	defer errors.Catch(&err, f.Close)
+	prev, err := MetaModelFromJSON(f)
+	if err != nil {
+		return errors.Errorf1From(
+			err, "failed to load previous model from %q", filename,
+		)
+	}
+	defer ReleaseMetaModel(prev)
+	fm := make(template.FuncMap, 8)
+	t := AddFuncs(template.New("<sqlddl-migrate>"), fm, mc.ddl).Funcs(fm)
+	if t, err = t.ParseFS(mc.ddl.FS(), "*.txt"); err != nil {
+		return errors.Errorf1From(
+			err, "failed to parse %v templates", mc.ddl.dialectName,
+		)
+	}
+	m := &mssqlMigration{
+		w:   bufio.NewWriter(w),
+		ddl: mc.ddl,
+		t:   t,
+		td:  td,
+	}
+	m.migrate(prev, td.MetaModel)
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+// mssqlMigration holds the state of a migration script being written.
+// The first error encountered is kept in err and all subsequent writes
+// are skipped.
+type mssqlMigration struct {
+	w   *bufio.Writer
+	ddl *sqlDDLModelContext
+	t   *template.Template
+	td  TemplateData
+	err error
+}
+
+func (m *mssqlMigration) printf(format string, args ...interface{}) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, format, args...)
+}
+
+func (m *mssqlMigration) template(name string, data interface{}) {
+	if m.err != nil {
+		return
+	}
+	if err := m.t.ExecuteTemplate(m.w, name, data); err != nil {
+		m.err = errors.Errorf1From(
+			err, "error executing template %q", name,
+		)
+	}
+}
+
+func (m *mssqlMigration) migrate(prev, cur *sqlstream.MetaModel) {
+	for _, cdb := range cur.Databases {
+		pdb, ok := prev.DatabasesByName[cdb.RawName]
+		if !ok {
+			m.createDatabase(cdb)
+			continue
+		}
+		m.migrateDatabase(pdb, cdb)
+	}
+	for _, pdb := range prev.Databases {
+		if _, ok := cur.DatabasesByName[pdb.RawName]; ok {
+			continue
+		}
+		if pdb.SQLName == "" {
+			continue
+		}
+		m.printf(
+			"IF EXISTS (\n\tSELECT 1 FROM sys.databases WHERE \"name\" = '%[1]s'\n)\n"+
+				"BEGIN\n\tDROP DATABASE \"%[1]s\";\nEND;\n\nGO\n\n",
+			pdb.SQLName,
+		)
+	}
+}
+
+func (m *mssqlMigration) createDatabase(db *sqlstream.Database) {
+	if db.SQLName != "" {
+		m.printf(
+			"IF NOT EXISTS (\n\tSELECT 1 FROM sys.databases WHERE \"name\" = '%[1]s'\n)\n"+
+				"BEGIN\n\tCREATE DATABASE \"%[1]s\";\nEND;\n\nGO\n\n"+
+				"USE \"%[1]s\";\n\n",
+			db.SQLName,
+		)
+	}
+	m.template("database.txt", db)
+}
+
+func (m *mssqlMigration) migrateDatabase(pdb, cdb *sqlstream.Database) {
+	if pdb.SQLName != cdb.SQLName && pdb.SQLName != "" && cdb.SQLName != "" {
+		m.printf(
+			"ALTER DATABASE \"%s\" MODIFY NAME = \"%s\";\n\nGO\n\n",
+			pdb.SQLName, cdb.SQLName,
+		)
+	}
+	if cdb.SQLName != "" {
+		m.printf("USE \"%s\";\n\n", cdb.SQLName)
+	}
+	prevFKs := m.foreignKeys(pdb)
+	curFKs := m.foreignKeys(cdb)
+	curFKSet := make(map[mssqlForeignKey]struct{}, len(curFKs))
+	for _, fk := range curFKs {
+		curFKSet[fk] = struct{}{}
+	}
+	prevFKSet := make(map[mssqlForeignKey]struct{}, len(prevFKs))
+	for _, fk := range prevFKs {
+		prevFKSet[fk] = struct{}{}
+		if _, ok := curFKSet[fk]; ok {
+			continue
+		}
+		m.printf(
+			"IF EXISTS (\n\tSELECT 1 FROM sys.foreign_keys WHERE \"name\" = '%[1]s'\n)\n"+
+				"BEGIN\n\tALTER TABLE %[2]s DROP CONSTRAINT \"%[1]s\";\nEND;\n\nGO\n\n",
+			fk.Name, fk.Table,
+		)
+	}
+	for _, cs := range cdb.Schemas {
+		ps, ok := pdb.SchemasByName[cs.RawName]
+		if !ok || ps.SQLName != cs.SQLName {
+			m.createSchema(cs)
+		}
+		m.migrateTables(ps, cs)
+		if ok && ps.SQLName != cs.SQLName {
+			m.dropSchema(ps)
+		}
+	}
+	for _, ps := range pdb.Schemas {
+		if _, ok := cdb.SchemasByName[ps.RawName]; ok {
+			continue
+		}
+		m.migrateTables(ps, nil)
+		m.dropSchema(ps)
+	}
+	for _, fk := range curFKs {
+		if _, ok := prevFKSet[fk]; ok {
+			continue
+		}
+		m.printf(
+			"IF NOT EXISTS (\n\tSELECT 1 FROM sys.foreign_keys WHERE \"name\" = '%[1]s'\n)\n"+
+				"BEGIN\n\t%[2]s\nEND;\n\nGO\n\n",
+			fk.Name, fk.Statement,
+		)
+	}
+}
+
+func (m *mssqlMigration) createSchema(s *sqlstream.Schema) {
+	if s.SQLName == "" {
+		return
+	}
+	m.printf(
+		"IF NOT EXISTS (\n\tSELECT 1 FROM sys.schemas WHERE \"name\" = '%[1]s'\n)\n"+
+			"BEGIN\n\tEXEC('CREATE SCHEMA \"%[1]s\"');\nEND;\n\nGO\n\n",
+		s.SQLName,
+	)
+}
+
+func (m *mssqlMigration) dropSchema(s *sqlstream.Schema) {
+	if s.SQLName == "" {
+		return
+	}
+	m.printf(
+		"IF EXISTS (\n\tSELECT 1 FROM sys.schemas WHERE \"name\" = '%[1]s'\n)\n"+
+			"BEGIN\n\tDROP SCHEMA \"%[1]s\";\nEND;\n\nGO\n\n",
+		s.SQLName,
+	)
+}
+
+// migrateTables migrates the tables of the previous schema, ps, to the
+// tables of the current schema, cs.  Either schema can be nil if the
+// schema was added or removed.
+func (m *mssqlMigration) migrateTables(ps, cs *sqlstream.Schema) {
+	matched := make(map[*sqlstream.Table]*sqlstream.Table)
+	if ps != nil && cs != nil {
+		// Renames take precedence so that a new table can reuse a
+		// renamed table's old name.
+		for _, ct := range cs.Tables {
+			from := TableExtOf(ct).RenamedFrom
+			if from == "" {
+				continue
+			}
+			if pt, ok := ps.TablesByName[from]; ok {
+				matched[pt] = ct
+			}
+		}
+	}
+	prevOf := make(map[*sqlstream.Table]*sqlstream.Table, len(matched))
+	for pt, ct := range matched {
+		prevOf[ct] = pt
+	}
+	if cs != nil {
+		for _, ct := range cs.Tables {
+			if _, ok := prevOf[ct]; ok || ps == nil {
+				continue
+			}
+			pt, ok := ps.TablesByName[ct.RawName]
+			if !ok {
+				continue
+			}
+			if _, ok = matched[pt]; ok {
+				continue
+			}
+			matched[pt] = ct
+			prevOf[ct] = pt
+		}
+	}
+	if ps != nil {
+		for _, pt := range ps.Tables {
+			if _, ok := matched[pt]; ok {
+				continue
+			}
+			// sys.tables' names aren't qualified, so a table
+			// with the same name in another schema would match.
+			q := mssqlQualifiedName(pt)
+			m.printf(
+				"IF OBJECT_ID(N'%[1]s', N'U') IS NOT NULL\n"+
+					"BEGIN\n\tDROP TABLE %[2]s;\nEND;\n\nGO\n\n",
+				strings.ReplaceAll(q, "'", "''"), q,
+			)
+		}
+	}
+	if cs == nil {
+		return
+	}
+	for _, ct := range cs.Tables {
+		pt, ok := prevOf[ct]
+		if !ok {
+			m.template("table.txt", ct)
+			m.template("index.txt", ct)
+			m.template("seed.txt", ct)
+			m.printf("\nGO\n\n")
+			continue
+		}
+		m.migrateTable(pt, ct)
+		if TableExtOf(ct).Enum != nil {
+			// Lookup tables are seeded every time so that new
+			// enum members are added.
+			m.template("seed.txt", ct)
+			m.printf("\nGO\n\n")
+		}
+	}
+}
+
+func (m *mssqlMigration) migrateTable(pt, ct *sqlstream.Table) {
+	wrote := false
+	q := mssqlQualifiedName(pt)
+	// Indexes are dropped first and recreated last because they
+	// prevent their columns from being altered or dropped.
+	prevIxs, curIxs := m.indexes(pt), m.indexes(ct)
+	curIxSet := make(map[mssqlIndex]struct{}, len(curIxs))
+	for _, ix := range curIxs {
+		curIxSet[ix] = struct{}{}
+	}
+	prevIxSet := make(map[mssqlIndex]struct{}, len(prevIxs))
+	for _, ix := range prevIxs {
+		prevIxSet[ix] = struct{}{}
+		if _, ok := curIxSet[ix]; ok {
+			continue
+		}
+		m.printf(
+			"IF EXISTS (\n\tSELECT 1 FROM sys.indexes WHERE \"name\" = '%[1]s' "+
+				"AND object_id = OBJECT_ID(N'%[3]s')\n)\n"+
+				"\tDROP INDEX \"%[1]s\" ON %[2]s;\n",
+			ix.Name, q, strings.ReplaceAll(q, "'", "''"),
+		)
+		wrote = true
+	}
+	// Default and check constraints are handled like indexes
+	// because they also prevent their columns from being altered or
+	// dropped.
+	prevCks, curCks := m.constraints(pt), m.constraints(ct)
+	curCkSet := make(map[mssqlConstraint]struct{}, len(curCks))
+	for _, ck := range curCks {
+		curCkSet[ck] = struct{}{}
+	}
+	prevCkSet := make(map[mssqlConstraint]struct{}, len(prevCks))
+	for _, ck := range prevCks {
+		prevCkSet[ck] = struct{}{}
+		if _, ok := curCkSet[ck]; ok {
+			continue
+		}
+		m.printf(
+			"IF EXISTS (\n\tSELECT 1 FROM sys.objects WHERE \"name\" = '%[1]s' "+
+				"AND parent_object_id = OBJECT_ID(N'%[3]s')\n)\n"+
+				"\tALTER TABLE %[2]s DROP CONSTRAINT \"%[1]s\";\n",
+			ck.Name, q, strings.ReplaceAll(q, "'", "''"),
+		)
+		wrote = true
+	}
+	if pt.Schema.SQLName != ct.Schema.SQLName && ct.Schema.SQLName != "" {
+		m.printf("ALTER SCHEMA \"%s\" TRANSFER %s;\n", ct.Schema.SQLName, q)
+		wrote = true
+	}
+	if pt.SQLName != ct.SQLName {
+		m.printf(
+			"EXEC sp_rename N'%s', N'%s';\n",
+			mssqlObjectName(ct.Schema.SQLName, pt.SQLName), ct.SQLName,
+		)
+		wrote = true
+	}
+	q = mssqlQualifiedName(ct)
+	prevPK, curPK := m.pkSignature(pt), m.pkSignature(ct)
+	pkChanged := prevPK != curPK
+	if pkChanged && prevPK != "" {
+		// Every table's changes are in their own batch, so @pk
+		// can be redeclared for each table.
+		m.printf(
+			"DECLARE @pk sysname = (\n"+
+				"\tSELECT \"name\" FROM sys.key_constraints\n"+
+				"\tWHERE \"type\" = 'PK' AND parent_object_id = OBJECT_ID(N'%[1]s')\n"+
+				");\n"+
+				"IF @pk IS NOT NULL\n"+
+				"\tEXEC('ALTER TABLE %[1]s DROP CONSTRAINT \"' + @pk + '\"');\n",
+			strings.ReplaceAll(q, "'", "''"),
+		)
+		wrote = true
+	}
+	matched := make(map[*sqlstream.Column]*sqlstream.Column, len(ct.Columns))
+	prevOf := make(map[*sqlstream.Column]*sqlstream.Column, len(ct.Columns))
+	for _, cc := range ct.Columns {
+		from := ColumnExtOf(cc).RenamedFrom
+		if from == "" {
+			continue
+		}
+		if pc, ok := pt.ColumnsByName[from]; ok {
+			matched[pc] = cc
+			prevOf[cc] = pc
+		}
+	}
+	for _, cc := range ct.Columns {
+		if _, ok := prevOf[cc]; ok {
+			continue
+		}
+		pc, ok := pt.ColumnsByName[cc.RawName]
+		if !ok {
+			continue
+		}
+		if _, ok = matched[pc]; ok {
+			continue
+		}
+		matched[pc] = cc
+		prevOf[cc] = pc
+	}
+	for _, pc := range pt.Columns {
+		if _, ok := matched[pc]; ok {
+			continue
+		}
+		m.printf("ALTER TABLE %s DROP COLUMN \"%s\";\n", q, pc.SQLName)
+		wrote = true
+	}
+	for _, cc := range ct.Columns {
+		pc, ok := prevOf[cc]
+		if !ok {
+			def := m.columnDefinition(cc)
+			if ColumnExtOf(cc).Generated {
+				def += " IDENTITY(1, 1)"
+			}
+			// New NOT NULL columns need their defaults to be
+			// added to tables that already have rows.
+			v := sqlDefaultExpression(m.ddl.dialectName, cc)
+			if v == "" && !ColumnExtOf(cc).Generated && !sqltypes.IsNullable(cc.Type) && m.err == nil {
+				m.err = errors.Errorf2(
+					"new column %v.%v is NOT NULL but has no "+
+						"default value to add to existing "+
+						"rows.  Please give it a default or "+
+						"make it nullable",
+					ct.SQLName, cc.SQLName,
+				)
+				return
+			}
+			if v != "" {
+				name := mssqlDefaultName(cc)
+				def += " CONSTRAINT \"" + name + "\" DEFAULT " + v
+				prevCkSet[mssqlConstraint{
+					Name:      name,
+					Statement: m.defaultStatement(cc),
+					signature: m.columnType(cc),
+				}] = struct{}{}
+			}
+			m.printf("ALTER TABLE %s ADD %s;\n", q, def)
+			wrote = true
+			continue
+		}
+		if ColumnExtOf(pc).Generated != ColumnExtOf(cc).Generated && m.err == nil {
+			m.err = errors.Errorf2(
+				"column %v.%v was generated and now isn't or "+
+					"wasn't generated and now is.  SQL Server "+
+					"can't add or remove a column's IDENTITY, "+
+					"so please migrate it by hand",
+				ct.SQLName, cc.SQLName,
+			)
+			return
+		}
+		if pc.SQLName != cc.SQLName {
+			m.printf(
+				"EXEC sp_rename N'%s', N'%s', N'COLUMN';\n",
+				mssqlObjectName(ct.Schema.SQLName, ct.SQLName, pc.SQLName),
+				cc.SQLName,
+			)
+			wrote = true
+		}
+		if m.columnType(pc) != m.columnType(cc) {
+			m.printf("ALTER TABLE %s ALTER COLUMN %s;\n", q, m.columnDefinition(cc))
+			wrote = true
+		}
+	}
+	if pkChanged && curPK != "" {
+		pkCols := mssqlPKColumns(ct)
+		names := make([]string, len(pkCols))
+		for i, c := range pkCols {
+			names[i] = "\"" + c.SQLName + "\""
+		}
+		m.printf(
+			"ALTER TABLE %s ADD CONSTRAINT \"PK_%s\" PRIMARY KEY (%s);\n",
+			q, ct.SQLName, strings.Join(names, ", "),
+		)
+		wrote = true
+	}
+	for _, ix := range curIxs {
+		if _, ok := prevIxSet[ix]; ok {
+			continue
+		}
+		m.printf("%s\n", ix.Statement)
+		wrote = true
+	}
+	for _, ck := range curCks {
+		if _, ok := prevCkSet[ck]; ok {
+			continue
+		}
+		m.printf("%s\n", ck.Statement)
+		wrote = true
+	}
+	if wrote {
+		m.printf("\nGO\n\n")
+	}
+}
+
+// columnType gets the SQL data type of a column along with its
+// nullability.
+func (m *mssqlMigration) columnType(c *sqlstream.Column) string {
+	t := c.Type
+	null := "NOT NULL"
+	if sqltypes.IsNullable(t) {
+		t = t.(sqltypes.Nullable)[0]
+		null = "NULL"
+	}
+	name, err := m.ddl.dialect.DataTypeName(t)
+	if err != nil && m.err == nil {
+		m.err = errors.Errorf2From(
+			err, "failed to get data type of column %v.%v",
+			c.Table.SQLName, c.SQLName,
+		)
+	}
+	return name + " " + null
+}
+
+func (m *mssqlMigration) columnDefinition(c *sqlstream.Column) string {
+	return "\"" + c.SQLName + "\" " + m.columnType(c)
+}
+
+// pkSignature describes a table's primary key so that changes to it can
+// be detected.  A table without a primary key has an empty signature.
+func (m *mssqlMigration) pkSignature(t *sqlstream.Table) string {
+	cols := mssqlPKColumns(t)
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = m.columnDefinition(c)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mssqlForeignKey is a foreign key constraint.  Two mssqlForeignKeys are
+// equal if the constraint does not need to be recreated.
+type mssqlForeignKey struct {
+	Name      string
+	Table     string
+	Statement string
+
+	// signature includes the data types of the columns and the
+	// referenced primary key because changing those requires the
+	// constraint to be dropped and recreated.
+	signature string
+}
+
+func (m *mssqlMigration) foreignKeys(db *sqlstream.Database) (fks []mssqlForeignKey) {
+	for _, sch := range db.Schemas {
+		for _, tbl := range sch.Tables {
+			for _, x := range TableExtOf(tbl).ForeignKeys {
+				fk := mssqlForeignKey{
+					Name:  x.Name,
+					Table: mssqlQualifiedName(tbl),
+				}
+				cols := make([]string, len(x.Columns))
+				refCols := make([]string, len(x.RefColumns))
+				sig := make([]string, 0, len(x.Columns)+len(x.RefColumns)+1)
+				for i, c := range x.Columns {
+					cols[i] = "\"" + c.SQLName + "\""
+					sig = append(sig, m.columnType(c))
+				}
+				for i, c := range x.RefColumns {
+					refCols[i] = "\"" + c.SQLName + "\""
+					sig = append(sig, m.columnType(c))
+				}
+				fk.Statement = fmt.Sprintf(
+					"ALTER TABLE %s ADD CONSTRAINT \"%s\" "+
+						"FOREIGN KEY (%s) REFERENCES %s(%s);",
+					fk.Table, fk.Name, strings.Join(cols, ", "),
+					mssqlQualifiedName(x.RefTable),
+					strings.Join(refCols, ", "),
+				)
+				fk.signature = strings.Join(
+					append(sig, m.pkSignature(x.RefTable)), "; ",
+				)
+				fks = append(fks, fk)
+			}
+		}
+	}
+	return
+}
+
+// mssqlIndex is an index.  Two mssqlIndexes are equal if the index does
+// not need to be recreated.
+type mssqlIndex struct {
+	Name      string
+	Statement string
+
+	// signature includes the data types of the columns because
+	// changing them requires the index to be dropped and recreated.
+	signature string
+}
+
+func (m *mssqlMigration) indexes(t *sqlstream.Table) (ixs []mssqlIndex) {
+	q := mssqlQualifiedName(t)
+	for _, x := range TableExtOf(t).Indexes {
+		ix := mssqlIndex{Name: x.Name}
+		cols := make([]string, len(x.Columns))
+		sig := make([]string, 0, len(x.Columns)+len(x.Include))
+		for i, c := range x.Columns {
+			cols[i] = "\"" + c.SQLName + "\""
+			if c.Descending {
+				cols[i] += " DESC"
+			}
+			sig = append(sig, m.columnType(c.Column))
+		}
+		b := strings.Builder{}
+		b.WriteString("CREATE ")
+		if x.Unique {
+			b.WriteString("UNIQUE ")
+		}
+		fmt.Fprintf(&b, "INDEX \"%s\" ON %s (%s)", x.Name, q, strings.Join(cols, ", "))
+		if len(x.Include) > 0 {
+			inc := make([]string, len(x.Include))
+			for i, c := range x.Include {
+				inc[i] = "\"" + c.SQLName + "\""
+				sig = append(sig, m.columnType(c))
+			}
+			fmt.Fprintf(&b, " INCLUDE (%s)", strings.Join(inc, ", "))
+		}
+		if x.Where != "" {
+			b.WriteString(" WHERE ")
+			b.WriteString(x.Where)
+		}
+		b.WriteByte(';')
+		ix.Statement = b.String()
+		ix.signature = strings.Join(sig, "; ")
+		ixs = append(ixs, ix)
+	}
+	return
+}
+
+// mssqlConstraint is a default or check constraint.  Two
+// mssqlConstraints are equal if the constraint does not need to be
+// recreated.
+type mssqlConstraint struct {
+	Name      string
+	Statement string
+
+	// signature includes the data types of the constrained columns
+	// because changing them requires the constraint to be dropped and
+	// recreated.
+	signature string
+}
+
+func (m *mssqlMigration) constraints(t *sqlstream.Table) (cks []mssqlConstraint) {
+	q := mssqlQualifiedName(t)
+	for _, c := range t.Columns {
+		if ColumnExtOf(c).Default == "" {
+			continue
+		}
+		cks = append(cks, mssqlConstraint{
+			Name:      mssqlDefaultName(c),
+			Statement: m.defaultStatement(c),
+			signature: m.columnType(c),
+		})
+	}
+	for _, x := range TableExtOf(t).Checks {
+		ck := mssqlConstraint{
+			Name: x.Name,
+			Statement: fmt.Sprintf(
+				"ALTER TABLE %s ADD CONSTRAINT \"%s\" CHECK (%s);",
+				q, x.Name, sqlCheckExpression(m.ddl.dialectName, x),
+			),
+		}
+		if x.Column != nil {
+			ck.signature = m.columnType(x.Column)
+		} else {
+			// The columns that a table's check refers to are
+			// unknown, so it depends on all of them.
+			sig := make([]string, len(t.Columns))
+			for i, c := range t.Columns {
+				sig[i] = m.columnDefinition(c)
+			}
+			ck.signature = strings.Join(sig, "; ")
+		}
+		cks = append(cks, ck)
+	}
+	return
+}
+
+func (m *mssqlMigration) defaultStatement(c *sqlstream.Column) string {
+	return fmt.Sprintf(
+		"ALTER TABLE %s ADD CONSTRAINT \"%s\" DEFAULT %s FOR \"%s\";",
+		mssqlQualifiedName(c.Table), mssqlDefaultName(c),
+		sqlDefaultExpression(m.ddl.dialectName, c), c.SQLName,
+	)
+}
+
+// mssqlDefaultName gets the name of a column's default constraint.  It
+// must match the name in the mssql table.txt template.
+func mssqlDefaultName(c *sqlstream.Column) string {
+	return "DF_" + c.Table.SQLName + "_" + c.SQLName
+}
+
+func mssqlPKColumns(t *sqlstream.Table) []*sqlstream.Column {
+	if t.PK != nil {
+		return []*sqlstream.Column{t.PK.Column}
+	}
+	if t.Key != nil {
+		cols := make([]*sqlstream.Column, len(t.Key.IDs))
+		for i, id := range t.Key.IDs {
+			cols[i] = id.Column
+		}
+		return cols
+	}
+	return nil
+}
+
+func mssqlQualifiedName(t *sqlstream.Table) string {
+	return mssqlQualifiedName2(t.Schema.SQLName, t.SQLName)
+}
+
+func mssqlQualifiedName2(schemaName, tableName string) string {
+	if schemaName == "" {
+		return "\"" + tableName + "\""
+	}
+	return "\"" + schemaName + "\".\"" + tableName + "\""
+}
+
+// mssqlObjectName creates the dotted object names that sp_rename expects.
+func mssqlObjectName(names ...string) string {
+	parts := make([]string, 0, len(names))
+	for _, n := range names {
+		if n == "" {
+			continue
+		}
+		parts = append(parts, strings.ReplaceAll(n, "'", "''"))
+	}
+	return strings.Join(parts, ".")
+}
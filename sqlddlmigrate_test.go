@@ -0,0 +1,151 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testMSSQLMigrate migrates with PostgreSQL's data type names so that the
+// scripts don't depend on sqlstream's SQL Server dialect.
+var testMSSQLMigrate = sqlDDLMigrateModelContext{
+	ddl: newSQLDDLModelContext("mssql", postgresDialect{}),
+}
+
+// testMigrationModel creates a model with a single database and a
+// "sales" schema with the given tables.
+func testMigrationModel(tables string) string {
+	return `{"databases": [{"rawName": "shop", "schemas": [{"rawName": "sales", "tables": [` +
+		tables + `]}]}]}`
+}
+
+// testMigrate writes the script that migrates the prev model to the cur
+// model.
+func testMigrate(t *testing.T, prev, cur string) (string, error) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "models.previous.json")
+	if err := os.WriteFile(filename, []byte(prev), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mm := testMetaModel(t, cur)
+	td, err := TemplateDataFromMetaModel(mm, testMSSQLMigrate)
+	if err != nil {
+		t.Fatalf("failed to create template data: %v", err)
+	}
+	td.Parameters[migratePreviousParam] = filename
+	var buf bytes.Buffer
+	err = testMSSQLMigrate.WriteTemplateData(&buf, td)
+	return buf.String(), err
+}
+
+func TestMSSQLMigrate(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		prev, cur string
+		want      []string
+		notWant   []string
+		err       string
+	}{{
+		name: "rename",
+		prev: testMigrationModel(`{"rawName": "client", "columns": [
+			{"rawName": "client id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "first name", "type": "string(var: true, length: 64)"}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "customer", "renamedFrom": "client", "columns": [
+			{"rawName": "client id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "given name", "renamedFrom": "first name", "type": "string(var: true, length: 64)"}
+		]}`),
+		want: []string{
+			"EXEC sp_rename N'Sales.Client', N'Customer';",
+			"EXEC sp_rename N'Sales.Customer.FirstName', N'GivenName', N'COLUMN';",
+		},
+		notWant: []string{"DROP TABLE", "CREATE TABLE", "DROP COLUMN", "ADD \""},
+	}, {
+		name: "primary key",
+		prev: testMigrationModel(`{"rawName": "order line", "columns": [
+			{"rawName": "order id", "type": "int(bits: 64)", "pk": true},
+			{"rawName": "line number", "type": "int(bits: 16)"}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "order line", "columns": [
+			{"rawName": "order id", "type": "int(bits: 64)", "pk": true},
+			{"rawName": "line number", "type": "int(bits: 16)", "pk": true}
+		]}`),
+		want: []string{
+			"WHERE \"type\" = 'PK' AND parent_object_id = OBJECT_ID(N'\"Sales\".\"OrderLine\"')",
+			"EXEC('ALTER TABLE \"Sales\".\"OrderLine\" DROP CONSTRAINT \"' + @pk + '\"');",
+			"ALTER TABLE \"Sales\".\"OrderLine\" ADD CONSTRAINT \"PK_OrderLine\" PRIMARY KEY (\"OrderId\", \"LineNumber\");",
+		},
+	}, {
+		name: "drop table",
+		prev: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true}
+		]}, {"rawName": "obrien", "sqlName": "O'Brien", "columns": [
+			{"rawName": "id", "type": "int(bits: 32)", "pk": true}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true}
+		]}`),
+		want: []string{
+			"IF OBJECT_ID(N'\"Sales\".\"O''Brien\"', N'U') IS NOT NULL\nBEGIN\n\tDROP TABLE \"Sales\".\"O'Brien\";\nEND;",
+		},
+		notWant: []string{"sys.tables"},
+	}, {
+		name: "new NOT NULL column without a default",
+		prev: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "name", "type": "string(var: true, length: 64)"}
+		]}`),
+		err: "new column Customer.Name is NOT NULL but has no default value",
+	}, {
+		name: "new NOT NULL column with a default",
+		prev: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "active", "type": "bool", "default": "true"}
+		]}`),
+		want: []string{
+			"ALTER TABLE \"Sales\".\"Customer\" ADD \"Active\" boolean NOT NULL CONSTRAINT \"DF_Customer_Active\" DEFAULT",
+		},
+	}, {
+		name: "generated",
+		prev: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "number", "type": "int(bits: 32)"}
+		]}`),
+		cur: testMigrationModel(`{"rawName": "customer", "columns": [
+			{"rawName": "customer id", "type": "int(bits: 32)", "pk": true},
+			{"rawName": "number", "type": "int(bits: 32)", "generated": true}
+		]}`),
+		err: "column Customer.Number was generated and now isn't or wasn't generated and now is",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			script, err := testMigrate(t, tc.prev, tc.cur)
+			switch {
+			case tc.err != "":
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v, want %q", err, tc.err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("failed to migrate: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(script, want) {
+					t.Fatalf("missing %q in:\n%v", want, script)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(script, notWant) {
+					t.Fatalf("unexpected %q in:\n%v", notWant, script)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,1298 @@
+package sqlmodelgen
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/skillian/expr/errors"
+	"github.com/skillian/expr/stream/sqlstream/config"
+	"github.com/skillian/expr/stream/sqlstream/sqltypes"
+)
+
+const (
+	// sqlDDLDialectParam is the name of the parameter that selects the
+	// SQL dialect of the script.
+	sqlDDLDialectParam = "dialect"
+
+	// sqlDDLDatabaseParam is the name of the parameter that holds the
+	// name of the database that tables belong to until the script
+	// creates or uses another one.
+	sqlDDLDatabaseParam = "database"
+)
+
+var (
+	// SQLDDLParserModelContext parses SQL DDL scripts (e.g. the ones
+	// written by the sqlddl targets) into a model configuration.
+	SQLDDLParserModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = sqlDDLParserModelContext{dialectName: "mssql", database: "main"}
+)
+
+type sqlDDLParserModelContext struct {
+	dialectName string
+	database    string
+}
+
+func (sqlDDLParserModelContext) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (mc sqlDDLParserModelContext) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s, ok := ps[sqlDDLDialectParam]; ok {
+		switch s {
+		case "mssql", "sqlite3", "postgres", "mysql":
+			mc.dialectName = s
+		default:
+			return nil, errors.Errorf1(
+				"unsupported SQL dialect: %q", s,
+			)
+		}
+	}
+	if s := ps[sqlDDLDatabaseParam]; s != "" {
+		mc.database = s
+	}
+	return mc, nil
+}
+
+func (mc sqlDDLParserModelContext) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := mc.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+func (mc sqlDDLParserModelContext) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return cfg, errors.Errorf1From(
+			err, "failed to read all bytes from %v", r,
+		)
+	}
+	p := sqlDDLParser{
+		tokenScanner: tokenScanner{src: string(bs)},
+		dialectName:  mc.dialectName,
+		dbName:       mc.database,
+	}
+	if p.toks, err = tokenize(p.src, &sqlDDLSyntax); err != nil {
+		return cfg, err
+	}
+	if err = p.parse(); err != nil {
+		return cfg, err
+	}
+	return p.config()
+}
+
+// sqlDDLSyntax is the lexical syntax of SQL DDL scripts.  Characters that
+// aren't anything else are punctuation.
+var sqlDDLSyntax = tokenSyntax{
+	lineComment: "--",
+	puncts:      []string{"(", ")", ",", ".", ";"},
+	isWordRune:  sqlDDLIsWordRune,
+	scan:        sqlDDLScan,
+	otherPunct:  true,
+}
+
+// sqlDDLScan scans the string, quoted identifier, number or operator at
+// the start of src.
+func sqlDDLScan(src string) (kind tokenKind, text string, n int, err error) {
+	c := src[0]
+	switch {
+	case c == '\'' || ((c == 'N' || c == 'n') && strings.HasPrefix(src[1:], "'")):
+		i := 0
+		if c != '\'' {
+			i++
+		}
+		text, n, ok := sqlDDLUnquote(src[i:], '\'')
+		if !ok {
+			return 0, "", 0, errors.Errorf("unterminated string")
+		}
+		return stringToken, text, i + n, nil
+	case c == '"' || c == '`' || c == '[':
+		closer := c
+		if c == '[' {
+			closer = ']'
+		}
+		text, n, ok := sqlDDLUnquote(src, closer)
+		if !ok {
+			return 0, "", 0, errors.Errorf("unterminated identifier")
+		}
+		return quotedToken, text, n, nil
+	case (c >= '0' && c <= '9') || (c == '.' && len(src) > 1 && src[1] >= '0' && src[1] <= '9'):
+		i := 0
+		for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+			i++
+		}
+		if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+			i++
+			if i < len(src) && (src[i] == '+' || src[i] == '-') {
+				i++
+			}
+			for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+		}
+		return numberToken, src[:i], i, nil
+	case strings.IndexByte("<>=!|:+-*/%&^~", c) != -1:
+		i := 1
+		for i < len(src) && strings.IndexByte("<>=!|:+*%&^~", src[i]) != -1 {
+			i++
+		}
+		return punctToken, src[:i], i, nil
+	}
+	return 0, "", 0, nil
+}
+
+func sqlDDLIsWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_@#$", r)
+}
+
+// sqlDDLUnquote unquotes the quoted string or identifier at the start of s.
+// The closer is escaped by doubling it.  It returns the number of bytes
+// of s that were unquoted.
+func sqlDDLUnquote(s string, closer byte) (text string, n int, ok bool) {
+	sb := strings.Builder{}
+	for i := 1; i < len(s); i++ {
+		if s[i] != closer {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == closer {
+			sb.WriteByte(closer)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, true
+	}
+	return "", 0, false
+}
+
+// sqlDDLParser builds up a model from the statements of a SQL DDL script.
+// Statements that don't define the model (e.g. INSERTs, IF NOT EXISTS
+// guards, etc.) are skipped.
+type sqlDDLParser struct {
+	tokenScanner
+	dialectName string
+
+	// dbName is the name of the current database.
+	dbName    string
+	databases []*sqlDDLDatabase
+}
+
+type sqlDDLDatabase struct {
+	Name    string
+	Schemas []*sqlDDLSchema
+}
+
+type sqlDDLSchema struct {
+	Name   string
+	Tables []*sqlDDLTable
+}
+
+type sqlDDLTable struct {
+	Name        string
+	Columns     []*sqlDDLColumn
+	ForeignKeys []*sqlDDLForeignKey
+	Indexes     []*sqlDDLIndex
+	Checks      []CheckConfig
+}
+
+type sqlDDLColumn struct {
+	Name    string
+	Type    sqltypes.Type
+	NotNull bool
+	PK      bool
+
+	// Ext holds the Generated, Default and Check extensions.
+	Ext ColumnConfigExt
+}
+
+type sqlDDLForeignKey struct {
+	Name       string
+	Columns    []string
+	RefDBName  string
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+}
+
+type sqlDDLIndex struct {
+	Name    string
+	Unique  bool
+	Columns []IndexColumnConfig
+	Include []string
+	Where   string
+}
+
+// sqlDDLStatementWords end an element of a statement even if the script
+// doesn't end its statements with semicolons.
+var sqlDDLStatementWords = map[string]bool{
+	"ALTER":   true,
+	"BEGIN":   true,
+	"CREATE":  true,
+	"END":     true,
+	"EXEC":    true,
+	"EXECUTE": true,
+	"GO":      true,
+	"IF":      true,
+	"INSERT":  true,
+	"USE":     true,
+}
+
+// sqlDDLColumnStopWords end a column's default value expression.
+var sqlDDLColumnStopWords = map[string]bool{
+	"AUTOINCREMENT":  true,
+	"AUTO_INCREMENT": true,
+	"CHECK":          true,
+	"COLLATE":        true,
+	"COMMENT":        true,
+	"CONSTRAINT":     true,
+	"DEFAULT":        true,
+	"FOR":            true,
+	"GENERATED":      true,
+	"IDENTITY":       true,
+	"NOT":            true,
+	"NULL":           true,
+	"ON":             true,
+	"PRIMARY":        true,
+	"REFERENCES":     true,
+	"UNIQUE":         true,
+	"WITH":           true,
+}
+
+// sqlDDLIndexStopWords end a filtered index's predicate.
+var sqlDDLIndexStopWords = map[string]bool{
+	"ON":   true,
+	"WITH": true,
+}
+
+func (p *sqlDDLParser) parse() error {
+	for p.tok().Kind != eofToken {
+		var err error
+		switch {
+		case p.accept("CREATE"):
+			err = p.parseCreate()
+		case p.accept("ALTER", "TABLE"):
+			err = p.parseAlterTable()
+		case p.accept("USE"):
+			p.dbName, err = p.name()
+		case p.isPunct("\\") && (p.isWordAt(1, "connect") || p.isWordAt(1, "c")):
+			// psql's \connect
+			p.pos += 2
+			p.dbName, err = p.name()
+		case p.tok().Kind == stringToken && sqlDDLIsDynamicSQL(p.tok().Text):
+			err = p.parseDynamicSQL(p.tok().Text)
+			p.next()
+		default:
+			p.next()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlDDLIsDynamicSQL checks if a string holds a statement that should be
+// parsed.  Scripts use dynamic SQL to conditionally execute statements
+// (e.g. the MySQL target's indexes and foreign keys).
+func sqlDDLIsDynamicSQL(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "CREATE ") || strings.HasPrefix(s, "ALTER ")
+}
+
+func (p *sqlDDLParser) parseDynamicSQL(src string) (err error) {
+	q := *p
+	q.src, q.pos = src, 0
+	if q.toks, err = tokenize(src, &sqlDDLSyntax); err != nil {
+		return errors.Errorf1From(
+			err, "line %d: failed to parse dynamic SQL", p.tok().Line,
+		)
+	}
+	if err = q.parse(); err != nil {
+		return errors.Errorf1From(
+			err, "line %d: failed to parse dynamic SQL", p.tok().Line,
+		)
+	}
+	p.dbName, p.databases = q.dbName, q.databases
+	return nil
+}
+
+func (p *sqlDDLParser) parseCreate() (err error) {
+	p.accept("OR", "REPLACE")
+	switch {
+	case p.accept("DATABASE"):
+		p.accept("IF", "NOT", "EXISTS")
+		p.dbName, err = p.name()
+		return
+	case p.accept("SCHEMA"):
+		p.accept("IF", "NOT", "EXISTS")
+		if p.isWord("AUTHORIZATION") {
+			return nil
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		p.database(p.dbName).schema(name)
+		return nil
+	case p.accept("TABLE"):
+		return p.parseCreateTable()
+	}
+	unique := p.accept("UNIQUE")
+	p.acceptAny("CLUSTERED", "NONCLUSTERED")
+	if p.accept("INDEX") {
+		return p.parseCreateIndex(unique)
+	}
+	// Views, procedures, temporary tables, etc. aren't part of the
+	// model.
+	return nil
+}
+
+func (p *sqlDDLParser) parseCreateTable() error {
+	p.accept("IF", "NOT", "EXISTS")
+	line := p.tok().Line
+	dbName, schName, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(name, "#") {
+		// T-SQL temporary table
+		return nil
+	}
+	if !p.isPunct("(") {
+		logger.Warn2(
+			"line %d: ignoring table %q without column definitions",
+			line, name,
+		)
+		return nil
+	}
+	if schName == "" {
+		schName = p.defaultSchema()
+	}
+	sch := p.database(dbName).schema(schName)
+	if sch.table(name) != nil {
+		return errors.Errorf2(
+			"line %d: table %q is created more than once",
+			line, name,
+		)
+	}
+	t := &sqlDDLTable{Name: name}
+	sch.Tables = append(sch.Tables, t)
+	p.next()
+	for {
+		if err = p.parseTableElement(t); err != nil {
+			return errors.Errorf1From(
+				err, "failed to parse table %q", name,
+			)
+		}
+		if p.acceptPunct(",") {
+			continue
+		}
+		return p.expectPunct(")")
+	}
+}
+
+func (p *sqlDDLParser) parseTableElement(t *sqlDDLTable) error {
+	if p.isTableConstraint() {
+		return p.parseTableConstraint(t)
+	}
+	return p.parseColumn(t)
+}
+
+func (p *sqlDDLParser) isTableConstraint() bool {
+	return p.isWord("CONSTRAINT") || p.isWord("PRIMARY") || p.isWord("FOREIGN") ||
+		p.isWord("UNIQUE") || p.isWord("CHECK") || p.isWord("KEY") || p.isWord("INDEX")
+}
+
+func (p *sqlDDLParser) parseTableConstraint(t *sqlDDLTable) (err error) {
+	name := ""
+	if p.accept("CONSTRAINT") {
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		p.acceptAny("CLUSTERED", "NONCLUSTERED")
+		ics, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		for _, ic := range ics {
+			c := t.column(ic.RawName)
+			if c == nil {
+				return errors.Errorf1(
+					"primary key column %q is not defined",
+					ic.RawName,
+				)
+			}
+			c.PK = true
+		}
+	case p.accept("FOREIGN", "KEY"):
+		fk := &sqlDDLForeignKey{Name: name}
+		ics, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		for _, ic := range ics {
+			fk.Columns = append(fk.Columns, ic.RawName)
+		}
+		if err = p.expect("REFERENCES"); err != nil {
+			return err
+		}
+		if err = p.parseReferences(fk); err != nil {
+			return err
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	case p.isWord("UNIQUE") || p.isWord("KEY") || p.isWord("INDEX"):
+		ix := &sqlDDLIndex{Name: name, Unique: p.accept("UNIQUE")}
+		p.acceptAny("KEY", "INDEX")
+		if !p.isPunct("(") && !p.isWord("CLUSTERED") && !p.isWord("NONCLUSTERED") {
+			// MySQL's [UNIQUE] KEY name (...)
+			if ix.Name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		p.acceptAny("CLUSTERED", "NONCLUSTERED")
+		if ix.Columns, err = p.indexColumns(); err != nil {
+			return err
+		}
+		t.Indexes = append(t.Indexes, ix)
+	case p.accept("CHECK"):
+		expr, err := p.parenthesized()
+		if err != nil {
+			return err
+		}
+		t.Checks = append(t.Checks, CheckConfig{Name: name, Expression: expr})
+	default:
+		return p.unexpected("a table constraint")
+	}
+	return p.skipElement()
+}
+
+func (p *sqlDDLParser) parseReferences(fk *sqlDDLForeignKey) (err error) {
+	if fk.RefDBName, fk.RefSchema, fk.RefTable, err = p.tableName(); err != nil {
+		return err
+	}
+	if !p.isPunct("(") {
+		return nil
+	}
+	ics, err := p.indexColumns()
+	if err != nil {
+		return err
+	}
+	for _, ic := range ics {
+		fk.RefColumns = append(fk.RefColumns, ic.RawName)
+	}
+	return nil
+}
+
+func (p *sqlDDLParser) parseColumn(t *sqlDDLTable) (err error) {
+	line := p.tok().Line
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.isWord("AS") {
+		logger.Warn2(
+			"line %d: ignoring computed column %q",
+			line, name,
+		)
+		return p.skipElement()
+	}
+	c := &sqlDDLColumn{Name: name}
+	if c.Type, c.Ext.Generated, err = p.dataType(); err != nil {
+		return errors.Errorf1From(
+			err, "failed to parse type of column %q", name,
+		)
+	}
+	t.Columns = append(t.Columns, c)
+	if err = p.parseColumnConstraints(t, c); err != nil {
+		return errors.Errorf1From(
+			err, "failed to parse column %q", name,
+		)
+	}
+	return nil
+}
+
+func (p *sqlDDLParser) parseColumnConstraints(t *sqlDDLTable, c *sqlDDLColumn) (err error) {
+	name := ""
+	for !p.atElementEnd() {
+		switch {
+		case p.accept("CONSTRAINT"):
+			if name, err = p.name(); err != nil {
+				return err
+			}
+			continue
+		case p.accept("NOT", "NULL"):
+			c.NotNull = true
+		case p.accept("NULL"):
+		case p.accept("PRIMARY", "KEY"):
+			c.PK = true
+		case p.accept("IDENTITY"):
+			c.Ext.Generated = true
+			if p.isPunct("(") {
+				_, err = p.parenthesized()
+			}
+		case p.acceptAny("AUTOINCREMENT", "AUTO_INCREMENT"):
+			c.Ext.Generated = true
+		case p.accept("GENERATED"):
+			// GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY
+			if !p.accept("ALWAYS") {
+				p.accept("BY", "DEFAULT")
+			}
+			if p.accept("AS", "IDENTITY") {
+				c.Ext.Generated = true
+				if p.isPunct("(") {
+					_, err = p.parenthesized()
+				}
+			}
+		case p.accept("DEFAULT"):
+			c.Ext.Default, err = p.expression(sqlDDLColumnStopWords)
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			t.Indexes = append(t.Indexes, &sqlDDLIndex{
+				Name:    name,
+				Unique:  true,
+				Columns: []IndexColumnConfig{{RawName: c.Name}},
+			})
+		case p.accept("CHECK"):
+			var expr string
+			if expr, err = p.parenthesized(); err != nil {
+				return err
+			}
+			if c.Ext.Check != "" {
+				expr = "(" + c.Ext.Check + ") AND (" + expr + ")"
+			}
+			c.Ext.Check = expr
+		case p.accept("REFERENCES"):
+			fk := &sqlDDLForeignKey{Name: name, Columns: []string{c.Name}}
+			if err = p.parseReferences(fk); err != nil {
+				return err
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		default:
+			// COLLATE, ON DELETE, ON CONFLICT, etc.
+			err = p.skipToken()
+		}
+		if err != nil {
+			return err
+		}
+		name = ""
+	}
+	return nil
+}
+
+// dataType parses a column's data type.  generated is true for types that
+// imply that the database generates the column's values (e.g. PostgreSQL's
+// serial types).
+func (p *sqlDDLParser) dataType() (t sqltypes.Type, generated bool, err error) {
+	tok := p.tok()
+	if tok.Kind != wordToken && tok.Kind != quotedToken {
+		return nil, false, p.unexpected("a data type")
+	}
+	p.next()
+	name := strings.ToLower(tok.Text)
+	switch name {
+	case "double":
+		if p.accept("PRECISION") {
+			name = "double precision"
+		}
+	case "national":
+		if !p.acceptAny("CHARACTER", "CHAR") {
+			return nil, false, p.unexpected("CHARACTER")
+		}
+		name = "nchar"
+		if p.accept("VARYING") {
+			name = "nvarchar"
+		}
+	case "character", "char":
+		if p.accept("VARYING") {
+			name = "varchar"
+		}
+	case "nchar":
+		if p.accept("VARYING") {
+			name = "nvarchar"
+		}
+	}
+	var args []string
+	if p.acceptPunct("(") {
+		for !p.acceptPunct(")") {
+			if p.tok().Kind == eofToken {
+				return nil, false, p.unexpected(")")
+			}
+			if !p.isPunct(",") {
+				args = append(args, strings.ToLower(p.tok().Text))
+			}
+			p.next()
+		}
+	}
+	p.acceptAny("UNSIGNED", "SIGNED")
+	p.accept("ZEROFILL")
+	if !p.accept("WITH", "TIME", "ZONE") {
+		p.accept("WITHOUT", "TIME", "ZONE")
+	}
+	return sqlDDLType(p.dialectName, name, args)
+}
+
+// sqlDDLType maps the lower case name and arguments of a data type to a
+// sqltypes.Type.
+func sqlDDLType(dialectName, name string, args []string) (t sqltypes.Type, generated bool, err error) {
+	arg := func(i, def int) int {
+		if i < len(args) {
+			if n, err := strconv.Atoi(args[i]); err == nil {
+				return n
+			}
+		}
+		return def
+	}
+	switch name {
+	case "bit", "bool", "boolean":
+		return sqltypes.BoolType{}, false, nil
+	case "tinyint", "int1":
+		return sqltypes.IntType{Bits: 8}, false, nil
+	case "smallint", "int2":
+		return sqltypes.IntType{Bits: 16}, false, nil
+	case "mediumint", "int", "int4":
+		return sqltypes.IntType{Bits: 32}, false, nil
+	case "integer":
+		if dialectName == "sqlite3" {
+			// SQLite integers are always 64-bit.
+			return sqltypes.IntType{Bits: 64}, false, nil
+		}
+		return sqltypes.IntType{Bits: 32}, false, nil
+	case "bigint", "int8":
+		return sqltypes.IntType{Bits: 64}, false, nil
+	case "smallserial", "serial2":
+		return sqltypes.IntType{Bits: 16}, true, nil
+	case "serial", "serial4":
+		return sqltypes.IntType{Bits: 32}, true, nil
+	case "bigserial", "serial8":
+		return sqltypes.IntType{Bits: 64}, true, nil
+	case "real", "float4":
+		return sqltypes.FloatType{Mantissa: 24}, false, nil
+	case "float":
+		// MySQL's FLOAT is single precision.  Elsewhere it's double
+		// precision unless it has 24 or fewer mantissa bits.
+		if n := arg(0, 53); n <= 24 || (dialectName == "mysql" && len(args) == 0) {
+			return sqltypes.FloatType{Mantissa: 24}, false, nil
+		}
+		return sqltypes.FloatType{Mantissa: 53}, false, nil
+	case "double", "double precision", "float8":
+		return sqltypes.FloatType{Mantissa: 53}, false, nil
+	case "decimal", "dec", "numeric":
+		return sqltypes.DecimalType{Prec: arg(0, 0), Scale: arg(1, 0)}, false, nil
+	case "money":
+		return sqltypes.DecimalType{Prec: 19, Scale: 4}, false, nil
+	case "smallmoney":
+		return sqltypes.DecimalType{Prec: 10, Scale: 4}, false, nil
+	case "char", "nchar", "character":
+		return sqltypes.StringType{Length: arg(0, 1)}, false, nil
+	case "varchar", "nvarchar", "varchar2", "nvarchar2":
+		// VARCHAR(MAX) has no length.
+		return sqltypes.StringType{Var: true, Length: arg(0, 0)}, false, nil
+	case "text", "ntext", "tinytext", "mediumtext", "longtext",
+		"clob", "nclob", "citext", "json", "jsonb", "xml":
+		return sqltypes.StringType{Var: true}, false, nil
+	case "uniqueidentifier", "uuid":
+		return sqltypes.StringType{Length: 36}, false, nil
+	case "date":
+		return sqltypes.TimeType{Prec: 24 * time.Hour}, false, nil
+	case "timestamp":
+		if dialectName == "mssql" {
+			// T-SQL's timestamp is a synonym for rowversion.
+			return sqltypes.BytesType{Length: 8}, true, nil
+		}
+		return sqltypes.TimeType{}, false, nil
+	case "datetime", "datetime2", "smalldatetime", "datetimeoffset",
+		"timestamptz":
+		return sqltypes.TimeType{}, false, nil
+	case "rowversion":
+		return sqltypes.BytesType{Length: 8}, true, nil
+	case "binary":
+		return sqltypes.BytesType{Length: arg(0, 1)}, false, nil
+	case "varbinary":
+		return sqltypes.BytesType{Var: true, Length: arg(0, 0)}, false, nil
+	case "image", "blob", "tinyblob", "mediumblob", "longblob", "bytea":
+		return sqltypes.BytesType{Var: true}, false, nil
+	}
+	return nil, false, errors.Errorf1(
+		"unsupported data type: %q", name,
+	)
+}
+
+func (p *sqlDDLParser) parseCreateIndex(unique bool) (err error) {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	line := p.tok().Line
+	ix := &sqlDDLIndex{Unique: unique}
+	if !p.isWord("ON") {
+		// PostgreSQL index names can be qualified but indexes are
+		// always in their table's schema anyway.
+		names, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		ix.Name = names[len(names)-1]
+	}
+	if err = p.expect("ON"); err != nil {
+		return err
+	}
+	dbName, schName, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	t := p.table(dbName, schName, name)
+	if t == nil {
+		return errors.Errorf3(
+			"line %d: index %q is on undefined table %q",
+			line, ix.Name, name,
+		)
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	if ix.Columns, err = p.indexColumns(); err != nil {
+		return err
+	}
+	if p.accept("INCLUDE") {
+		ics, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		for _, ic := range ics {
+			ix.Include = append(ix.Include, ic.RawName)
+		}
+	}
+	if p.accept("WHERE") {
+		if ix.Where, err = p.expression(sqlDDLIndexStopWords); err != nil {
+			return err
+		}
+	}
+	t.Indexes = append(t.Indexes, ix)
+	return nil
+}
+
+func (p *sqlDDLParser) parseAlterTable() (err error) {
+	p.accept("ONLY")
+	p.accept("IF", "EXISTS")
+	line := p.tok().Line
+	dbName, schName, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	t := p.table(dbName, schName, name)
+	if t == nil {
+		if strings.HasPrefix(name, "#") {
+			return nil
+		}
+		return errors.Errorf2(
+			"line %d: undefined table %q is altered",
+			line, name,
+		)
+	}
+	if !p.accept("WITH", "CHECK") {
+		p.accept("WITH", "NOCHECK")
+	}
+	if !p.accept("ADD") {
+		// ALTER COLUMN, DROP, CHECK CONSTRAINT, etc. aren't
+		// supported.
+		return nil
+	}
+	for {
+		p.accept("COLUMN")
+		switch {
+		case p.isWord("DEFAULT") || (p.isWord("CONSTRAINT") && p.isWordAt(2, "DEFAULT")):
+			err = p.parseDefaultFor(t)
+		case p.isTableConstraint():
+			err = p.parseTableConstraint(t)
+		default:
+			err = p.parseColumn(t)
+		}
+		if err != nil {
+			return errors.Errorf1From(
+				err, "failed to alter table %q", name,
+			)
+		}
+		if !p.acceptPunct(",") {
+			return nil
+		}
+		p.accept("ADD")
+	}
+}
+
+// parseDefaultFor parses T-SQL's ADD [CONSTRAINT name] DEFAULT expr FOR
+// column.
+func (p *sqlDDLParser) parseDefaultFor(t *sqlDDLTable) (err error) {
+	if p.accept("CONSTRAINT") {
+		if _, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if err = p.expect("DEFAULT"); err != nil {
+		return err
+	}
+	expr, err := p.expression(sqlDDLColumnStopWords)
+	if err != nil {
+		return err
+	}
+	if err = p.expect("FOR"); err != nil {
+		return err
+	}
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	c := t.column(name)
+	if c == nil {
+		return errors.Errorf1(
+			"default value of undefined column %q", name,
+		)
+	}
+	c.Ext.Default = expr
+	return p.skipElement()
+}
+
+// accept consumes the sequence of keywords if they're next.
+func (p *sqlDDLParser) accept(words ...string) bool {
+	for i, w := range words {
+		if !p.isWordAt(i, w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptAny consumes the next keyword if it's any of words.
+func (p *sqlDDLParser) acceptAny(words ...string) bool {
+	for _, w := range words {
+		if p.accept(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *sqlDDLParser) expect(word string) error {
+	if p.accept(word) {
+		return nil
+	}
+	return p.unexpected(word)
+}
+
+// name parses an identifier.  T-SQL allows some names (e.g. in CREATE
+// SCHEMA) to be strings.
+func (p *sqlDDLParser) name() (string, error) {
+	tok := p.tok()
+	switch tok.Kind {
+	case wordToken, quotedToken, stringToken:
+		p.next()
+		return tok.Text, nil
+	}
+	return "", p.unexpected("a name")
+}
+
+// qualifiedName parses a name with optional qualifiers separated by
+// periods.  Qualifiers can be empty (e.g. T-SQL's "database..table").
+func (p *sqlDDLParser) qualifiedName() (names []string, err error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	names = append(names, name)
+	for p.acceptPunct(".") {
+		if p.isPunct(".") {
+			names = append(names, "")
+			continue
+		}
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+// tableName parses a table name that may be qualified by a schema and
+// database.
+func (p *sqlDDLParser) tableName() (dbName, schName, name string, err error) {
+	names, err := p.qualifiedName()
+	if err != nil {
+		return "", "", "", err
+	}
+	switch len(names) {
+	case 1:
+	case 2:
+		schName = names[0]
+	case 3:
+		dbName, schName = names[0], names[1]
+	default:
+		return "", "", "", errors.Errorf1(
+			"%q is not a table name",
+			strings.Join(names, "."),
+		)
+	}
+	return dbName, schName, names[len(names)-1], nil
+}
+
+// indexColumns parses a parenthesized list of column names with
+// optional sort orders.  The columns' SQL names are put into the
+// IndexColumnConfigs' RawNames until the configuration is created.
+func (p *sqlDDLParser) indexColumns() (ics []IndexColumnConfig, err error) {
+	if err = p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if p.isPunct("(") {
+			// MySQL key prefix length
+			if _, err = p.parenthesized(); err != nil {
+				return nil, err
+			}
+		}
+		ic := IndexColumnConfig{RawName: name}
+		switch {
+		case p.accept("ASC"):
+		case p.accept("DESC"):
+			ic.Order = "desc"
+		}
+		ics = append(ics, ic)
+		if p.acceptPunct(",") {
+			continue
+		}
+		return ics, p.expectPunct(")")
+	}
+}
+
+// parenthesized skips a parenthesized group of tokens and returns the
+// source text inside of the parentheses.
+func (p *sqlDDLParser) parenthesized() (string, error) {
+	open := p.tok()
+	if err := p.expectPunct("("); err != nil {
+		return "", err
+	}
+	for depth := 1; ; p.next() {
+		tok := p.tok()
+		switch {
+		case tok.Kind == eofToken:
+			return "", p.unexpected(")")
+		case p.isPunct("("):
+			depth++
+		case p.isPunct(")"):
+			depth--
+			if depth == 0 {
+				p.next()
+				return strings.TrimSpace(p.src[open.End:tok.Start]), nil
+			}
+		}
+	}
+}
+
+// expression parses a SQL expression until the end of the element or
+// one of the stop words and returns its source text.
+func (p *sqlDDLParser) expression(stopWords map[string]bool) (string, error) {
+	start, end := p.tok(), p.tok()
+	n := 0
+	for ; !p.atElementEnd(); n++ {
+		tok := p.tok()
+		if n > 0 && tok.Kind == wordToken && stopWords[strings.ToUpper(tok.Text)] {
+			break
+		}
+		if err := p.skipToken(); err != nil {
+			return "", err
+		}
+		end = p.toks[p.pos-1]
+	}
+	if n == 0 {
+		return "", p.unexpected("an expression")
+	}
+	return p.src[start.Start:end.End], nil
+}
+
+// atElementEnd checks if the current token ends a column definition,
+// table constraint, etc.
+func (p *sqlDDLParser) atElementEnd() bool {
+	tok := p.tok()
+	switch tok.Kind {
+	case eofToken:
+		return true
+	case punctToken:
+		return tok.Text == "," || tok.Text == ")" || tok.Text == ";"
+	case wordToken:
+		return sqlDDLStatementWords[strings.ToUpper(tok.Text)]
+	}
+	return false
+}
+
+// skipToken skips the current token or, if it's an open parenthesis,
+// the whole parenthesized group.
+func (p *sqlDDLParser) skipToken() error {
+	if p.isPunct("(") {
+		_, err := p.parenthesized()
+		return err
+	}
+	p.next()
+	return nil
+}
+
+// skipElement skips the rest of the current element.
+func (p *sqlDDLParser) skipElement() error {
+	for !p.atElementEnd() {
+		if err := p.skipToken(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultSchema is the name of the schema of unqualified table names.
+func (p *sqlDDLParser) defaultSchema() string {
+	switch p.dialectName {
+	case "mssql":
+		return "dbo"
+	case "postgres":
+		return "public"
+	}
+	return ""
+}
+
+// database gets or creates a database.  A blank name is the current
+// database.
+func (p *sqlDDLParser) database(name string) *sqlDDLDatabase {
+	if name == "" {
+		name = p.dbName
+	}
+	for _, db := range p.databases {
+		if strings.EqualFold(db.Name, name) {
+			return db
+		}
+	}
+	db := &sqlDDLDatabase{Name: name}
+	p.databases = append(p.databases, db)
+	return db
+}
+
+// table gets a table or nil if it isn't defined.  Blank database and schema
+// names are the current database and the default schema.
+func (p *sqlDDLParser) table(dbName, schName, name string) *sqlDDLTable {
+	if schName == "" {
+		schName = p.defaultSchema()
+	}
+	for _, sch := range p.database(dbName).Schemas {
+		if strings.EqualFold(sch.Name, schName) {
+			return sch.table(name)
+		}
+	}
+	return nil
+}
+
+// schema gets or creates a schema.  A blank name is the default schema.
+func (db *sqlDDLDatabase) schema(name string) *sqlDDLSchema {
+	for _, sch := range db.Schemas {
+		if strings.EqualFold(sch.Name, name) {
+			return sch
+		}
+	}
+	sch := &sqlDDLSchema{Name: name}
+	db.Schemas = append(db.Schemas, sch)
+	return sch
+}
+
+func (sch *sqlDDLSchema) table(name string) *sqlDDLTable {
+	for _, t := range sch.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (t *sqlDDLTable) column(name string) *sqlDDLColumn {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// pk gets the table's primary key columns.
+func (t *sqlDDLTable) pk() (cs []*sqlDDLColumn) {
+	for _, c := range t.Columns {
+		if c.PK {
+			cs = append(cs, c)
+		}
+	}
+	return
+}
+
+// sqlDDLCommonData derives the names of a database, schema, table or
+// column from its SQL name.
+func sqlDDLCommonData(sqlName string) config.CommonData {
+	return config.CommonData{
+		Names: config.Names{
+			RawName: identifierRawName(sqlName),
+			SQLName: sqlName,
+		},
+	}
+}
+
+// config creates the model configuration from the parsed databases.
+func (p *sqlDDLParser) config() (cfg Config, err error) {
+	cfg.Databases = make([]config.Database, 0, len(p.databases))
+	for _, db := range p.databases {
+		dbCfg := config.Database{
+			CommonData: sqlDDLCommonData(db.Name),
+			Schemas:    make([]config.Schema, 0, len(db.Schemas)),
+		}
+		for _, sch := range db.Schemas {
+			schCfg := config.Schema{
+				CommonData: sqlDDLCommonData(sch.Name),
+				Tables:     make([]config.Table, 0, len(sch.Tables)),
+			}
+			for _, t := range sch.Tables {
+				tblCfg, err := p.tableConfig(&cfg.Ext, db, sch, t)
+				if err != nil {
+					return cfg, errors.ErrorfFrom(
+						err, "failed to configure table "+
+							"%v.%v.%v",
+						db.Name, sch.Name, t.Name,
+					)
+				}
+				schCfg.Tables = append(schCfg.Tables, tblCfg)
+			}
+			dbCfg.Schemas = append(dbCfg.Schemas, schCfg)
+		}
+		if len(dbCfg.Schemas) > 0 {
+			cfg.Databases = append(cfg.Databases, dbCfg)
+		}
+	}
+	return
+}
+
+func (p *sqlDDLParser) tableConfig(ext *ConfigExt, db *sqlDDLDatabase, sch *sqlDDLSchema, t *sqlDDLTable) (tblCfg config.Table, err error) {
+	dbRawName := identifierRawName(db.Name)
+	schRawName := identifierRawName(sch.Name)
+	tblCfg = config.Table{
+		CommonData: sqlDDLCommonData(t.Name),
+		Columns:    make([]config.Column, len(t.Columns)),
+	}
+	rawNameOf := func(name string) (string, error) {
+		c := t.column(name)
+		if c == nil {
+			return "", errors.Errorf1("undefined column %q", name)
+		}
+		return identifierRawName(c.Name), nil
+	}
+	for i, c := range t.Columns {
+		colCfg := &tblCfg.Columns[i]
+		colCfg.CommonData = sqlDDLCommonData(c.Name)
+		colCfg.PK = c.PK
+		typ := c.Type
+		if !c.NotNull && !c.PK {
+			typ = sqltypes.Nullable{typ}
+		}
+		colCfg.Type = typ.String()
+		if c.Ext.Generated || c.Ext.Default != "" || c.Ext.Check != "" {
+			colExt := ext.Column(dbRawName, schRawName, tblCfg.RawName, colCfg.RawName)
+			colExt.Generated = c.Ext.Generated
+			colExt.Default = c.Ext.Default
+			colExt.Check = c.Ext.Check
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		refSchName := fk.RefSchema
+		if refSchName == "" {
+			refSchName = p.defaultSchema()
+		}
+		refTbl := p.table(fk.RefDBName, refSchName, fk.RefTable)
+		if refTbl == nil {
+			return tblCfg, errors.Errorf1(
+				"foreign key references undefined table %q",
+				fk.RefTable,
+			)
+		}
+		refPath := identifierRawName(refTbl.Name)
+		if !strings.EqualFold(refSchName, sch.Name) {
+			refPath = identifierRawName(refSchName) + "." + refPath
+		}
+		refCols := make([]*sqlDDLColumn, 0, len(fk.RefColumns))
+		for _, name := range fk.RefColumns {
+			c := refTbl.column(name)
+			if c == nil {
+				return tblCfg, errors.Errorf2(
+					"foreign key references undefined "+
+						"column %q of table %q",
+					name, refTbl.Name,
+				)
+			}
+			refCols = append(refCols, c)
+		}
+		pk := refTbl.pk()
+		refsPK := len(refCols) == 0
+		if len(refCols) == len(pk) {
+			refsPK = true
+			for i, c := range refCols {
+				refsPK = refsPK && c == pk[i]
+			}
+		}
+		if fk.Name == "" && len(fk.Columns) == 1 && len(pk) == 1 && refsPK {
+			// Unnamed single-column foreign keys to primary keys
+			// are inferred from the column's FK.
+			for i, c := range t.Columns {
+				if strings.EqualFold(c.Name, fk.Columns[0]) {
+					tblCfg.Columns[i].FK = refPath + "." + identifierRawName(pk[0].Name)
+				}
+			}
+			continue
+		}
+		fkCfg := ForeignKeyConfig{Name: fk.Name, References: refPath}
+		for _, name := range fk.Columns {
+			rawName, err := rawNameOf(name)
+			if err != nil {
+				return tblCfg, err
+			}
+			fkCfg.Columns = append(fkCfg.Columns, rawName)
+		}
+		if !refsPK {
+			for _, c := range refCols {
+				fkCfg.ReferencedColumns = append(fkCfg.ReferencedColumns, identifierRawName(c.Name))
+			}
+		}
+		tblExt := ext.Table(dbRawName, schRawName, tblCfg.RawName)
+		tblExt.ForeignKeys = append(tblExt.ForeignKeys, fkCfg)
+	}
+	for _, ix := range t.Indexes {
+		ixCfg := IndexConfig{Name: ix.Name, Unique: ix.Unique, Where: ix.Where}
+		for _, ic := range ix.Columns {
+			if ic.RawName, err = rawNameOf(ic.RawName); err != nil {
+				return tblCfg, err
+			}
+			ixCfg.Columns = append(ixCfg.Columns, ic)
+		}
+		for _, name := range ix.Include {
+			rawName, err := rawNameOf(name)
+			if err != nil {
+				return tblCfg, err
+			}
+			ixCfg.Include = append(ixCfg.Include, rawName)
+		}
+		tblExt := ext.Table(dbRawName, schRawName, tblCfg.RawName)
+		tblExt.Indexes = append(tblExt.Indexes, ixCfg)
+	}
+	if len(t.Checks) > 0 {
+		tblExt := ext.Table(dbRawName, schRawName, tblCfg.RawName)
+		tblExt.Checks = append(tblExt.Checks, t.Checks...)
+	}
+	return
+}
@@ -0,0 +1,131 @@
+package sqlmodelgen
+
+import "testing"
+
+func TestSQLDDLParse(t *testing.T) {
+	testParseCases(t, SQLDDLParserModelContext, []testParseCase{{
+		name:   "mssql",
+		params: map[string]string{"dialect": "mssql"},
+		src: `-- Customers and their orders.
+USE [Shop];
+GO
+CREATE TABLE [dbo].[Customer] (
+	[CustomerId] INT IDENTITY(1, 1) NOT NULL,
+	[Name] NVARCHAR(50) NOT NULL,
+	CONSTRAINT [PK_Customer] PRIMARY KEY ([CustomerId])
+);
+GO
+/* Orders
+   reference customers. */
+CREATE TABLE [dbo].[Order] (
+	[OrderId] BIGINT NOT NULL PRIMARY KEY,
+	[CustomerId] INT NOT NULL,
+	[Note] NVARCHAR(MAX) NULL
+);
+GO
+ALTER TABLE [dbo].[Order] ADD CONSTRAINT [FK_Order_Customer]
+	FOREIGN KEY ([CustomerId]) REFERENCES [dbo].[Customer] ([CustomerId]);
+CREATE UNIQUE NONCLUSTERED INDEX [UX_Customer_Name] ON [dbo].[Customer] ([Name]);
+`,
+		want: `Customer
+	CustomerId int32 pk
+	Name varchar(50)
+	unique UX_Customer_Name (Name)
+Order
+	OrderId int64 pk
+	CustomerId int32
+	Note nullable(varchar(0))
+	fk (CustomerId) Customer (CustomerId)
+`,
+	}, {
+		name:   "postgres",
+		params: map[string]string{"dialect": "postgres"},
+		src: `\connect shop
+CREATE SCHEMA IF NOT EXISTS "Sales";
+CREATE TABLE "Sales"."Order" (
+	"OrderId" bigserial PRIMARY KEY,
+	"Placed" timestamp NOT NULL DEFAULT now()
+);
+CREATE TABLE "Sales"."OrderLine" (
+	"OrderId" bigint NOT NULL REFERENCES "Sales"."Order" ("OrderId"),
+	"LineNumber" smallint NOT NULL,
+	"Price" numeric(10, 2) NOT NULL CHECK ("Price" >= 0),
+	PRIMARY KEY ("OrderId", "LineNumber")
+);
+CREATE INDEX "IX_OrderLine_Price" ON "Sales"."OrderLine" ("Price" DESC);
+`,
+		want: `Order
+	OrderId int64 pk
+	Placed time(0s)
+OrderLine
+	OrderId int64 pk
+	LineNumber int16 pk
+	Price decimal(10, 2)
+	fk (OrderId) Order (OrderId)
+	index IX_OrderLine_Price (Price)
+`,
+	}, {
+		name:   "mysql",
+		params: map[string]string{"dialect": "mysql"},
+		src: "CREATE DATABASE IF NOT EXISTS `shop`;\n" +
+			"USE `shop`;\n" +
+			"CREATE TABLE `customer` (\n" +
+			"\t`id` INT NOT NULL AUTO_INCREMENT,\n" +
+			"\t`email` VARCHAR(128) NULL,\n" +
+			"\tPRIMARY KEY (`id`)\n" +
+			") ENGINE = InnoDB;\n" +
+			"CREATE TABLE `order` (\n" +
+			"\t`id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,\n" +
+			"\t`customer_id` INT NOT NULL\n" +
+			");\n" +
+			"SET @sql = 'ALTER TABLE `order` ADD CONSTRAINT `FK_order_customer` " +
+			"FOREIGN KEY (`customer_id`) REFERENCES `customer` (`id`)';\n" +
+			"PREPARE stmt FROM @sql;\n" +
+			"EXECUTE stmt;\n",
+		want: `customer
+	id int32 pk
+	email nullable(varchar(128))
+order
+	id int64 pk
+	customer_id int32
+	fk (customer_id) customer (id)
+`,
+	}, {
+		name:   "sqlite",
+		params: map[string]string{"dialect": "sqlite3"},
+		src: `CREATE TABLE "Customer" (
+	"CustomerId" INTEGER PRIMARY KEY AUTOINCREMENT,
+	"Name" TEXT NOT NULL UNIQUE
+);
+CREATE TABLE "Order" (
+	"OrderId" INTEGER NOT NULL PRIMARY KEY,
+	"CustomerId" INTEGER NOT NULL REFERENCES "Customer" ("CustomerId")
+);
+`,
+		want: `Customer
+	CustomerId int64 pk
+	Name varchar(0)
+	unique UX_Customer_Name (Name)
+Order
+	OrderId int64 pk
+	CustomerId int64
+	fk (CustomerId) Customer (CustomerId)
+`,
+	}, {
+		name: "unterminated string",
+		src:  "CREATE TABLE t (\n\tc VARCHAR(10) DEFAULT 'abc\n);\n",
+		err:  "line 2: unterminated string",
+	}, {
+		name: "unterminated comment",
+		src:  "CREATE TABLE t (c INT); /* no end",
+		err:  "line 1: unterminated comment",
+	}, {
+		name: "missing parenthesis",
+		src:  "CREATE TABLE t (\n\tc INT,\n",
+		err:  "line 3: expected",
+	}, {
+		name: "unsupported type",
+		src:  "CREATE TABLE t (c GEOGRAPHY)",
+		err:  `unsupported data type: "geography"`,
+	}})
+}
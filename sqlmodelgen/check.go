@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+)
+
+// checkWriteCloser collects the output of a generator or target in memory
+// and, when closed, compares it against the existing file instead of
+// overwriting that file.
+type checkWriteCloser struct {
+	bytes.Buffer
+
+	// filename of the existing file to compare against.
+	filename string
+
+	// report receives the diff when the file is stale.
+	report io.Writer
+
+	// stale is set to true by Close if the existing file does
+	// not match what was written.
+	stale *bool
+}
+
+func newCheckWriteCloser(filename string, report io.Writer, stale *bool) *checkWriteCloser {
+	return &checkWriteCloser{
+		filename: filename,
+		report:   report,
+		stale:    stale,
+	}
+}
+
+func (c *checkWriteCloser) Close() error {
+	existing, err := ioutil.ReadFile(c.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Errorf1From(
+			err, "failed to read %q to check it", c.filename,
+		)
+	}
+	if bytes.Equal(existing, c.Bytes()) {
+		return nil
+	}
+	*c.stale = true
+	if err = writeUnifiedDiff(
+		c.report,
+		c.filename, c.filename+" (generated)",
+		splitDiffLines(string(existing)),
+		splitDiffLines(c.String()),
+	); err != nil {
+		return errors.Errorf1From(
+			err, "failed to write differences of %q", c.filename,
+		)
+	}
+	return nil
+}
+
+// splitDiffLines splits s into lines, keeping the line terminators so
+// that differences in line endings are reported.
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is a single line operation in an edit script.
+type diffOp struct {
+	// Kind is one of ' ' (keep), '-' (delete) or '+' (insert).
+	Kind byte
+
+	// A and B are the indexes of the line in the "a" and "b" inputs.
+	A, B int
+}
+
+// diffLines computes the shortest edit script to transform a into b with
+// the linear space variant of the Myers diff algorithm so that files whose
+// lines all differ (e.g. because of their line endings) don't need memory
+// proportional to the number of lines times the number of differences.
+func diffLines(a, b []string) []diffOp {
+	ld := lineDiff{a: a, b: b, ops: make([]diffOp, 0, len(a)+len(b))}
+	ld.diff(0, len(a), 0, len(b))
+	return ld.ops
+}
+
+// lineDiff collects the edit script of diffLines.
+type lineDiff struct {
+	a, b []string
+	ops  []diffOp
+}
+
+// diff appends the edit script that transforms a[aLo:aHi] into
+// b[bLo:bHi].
+func (ld *lineDiff) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && ld.a[aLo] == ld.b[bLo] {
+		ld.ops = append(ld.ops, diffOp{' ', aLo, bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && ld.a[aHi-suffix-1] == ld.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+	switch {
+	case aLo == aHi:
+		for ; bLo < bHi; bLo++ {
+			ld.ops = append(ld.ops, diffOp{'+', aLo, bLo})
+		}
+	case bLo == bHi:
+		for ; aLo < aHi; aLo++ {
+			ld.ops = append(ld.ops, diffOp{'-', aLo, bLo})
+		}
+	default:
+		// Both ranges are non-empty and their first and last lines
+		// differ, so there are at least two differences and both
+		// halves around the middle snake have fewer.
+		x, y, u, v := ld.middleSnake(aLo, aHi, bLo, bHi)
+		ld.diff(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			ld.ops = append(ld.ops, diffOp{' ', x, y})
+		}
+		ld.diff(u, aHi, v, bHi)
+	}
+	for i := 0; i < suffix; i++ {
+		ld.ops = append(ld.ops, diffOp{' ', aHi + i, bHi + i})
+	}
+}
+
+// middleSnake finds the snake from (x, y) to (u, v) in the middle of the
+// shortest edit script of a[aLo:aHi] and b[bLo:bHi] by searching forward
+// from the start and backward from the end at the same time.
+func (ld *lineDiff) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta&1 != 0
+	max := (n + m + 1) / 2
+	offset := max + 1
+	// vf holds the furthest x of the forward paths on each diagonal
+	// k = x - y and vb holds the furthest distance from the end of
+	// the backward paths on each diagonal k = delta - (x - y).
+	vf := make([]int, 2*max+3)
+	vb := make([]int, 2*max+3)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x0 int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x0 = vf[offset+k+1]
+			} else {
+				x0 = vf[offset+k-1] + 1
+			}
+			x, y := x0, x0-k
+			for x < n && y < m && ld.a[aLo+x] == ld.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && x+vb[offset+kb] >= n {
+				return aLo + x0, bLo + x0 - k, aLo + x, bLo + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x0 int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x0 = vb[offset+k+1]
+			} else {
+				x0 = vb[offset+k-1] + 1
+			}
+			x, y := x0, x0-k
+			for x < n && y < m && ld.a[aHi-x-1] == ld.b[bHi-y-1] {
+				x++
+				y++
+			}
+			vb[offset+k] = x
+			if kf := delta - k; !odd && kf >= -d && kf <= d && x+vf[offset+kf] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - x0 + k
+			}
+		}
+	}
+	panic("the forward and backward paths never met")
+}
+
+// writeUnifiedDiff writes the differences between a and b to w in the
+// unified diff format with three lines of context.
+func writeUnifiedDiff(w io.Writer, aName, bName string, a, b []string) (err error) {
+	const context = 3
+	ops := diffLines(a, b)
+	if _, err = fmt.Fprintf(w, "--- %s\n+++ %s\n", aName, bName); err != nil {
+		return
+	}
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+		// Found a change; extend the hunk until there are more than
+		// 2*context unchanged lines in a row.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += context
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+		hunk := ops[start:end]
+		aStart, bStart := hunk[0].A, hunk[0].B
+		aLen, bLen := 0, 0
+		for _, op := range hunk {
+			switch op.Kind {
+			case ' ':
+				aLen++
+				bLen++
+			case '-':
+				aLen++
+			case '+':
+				bLen++
+			}
+		}
+		if _, err = fmt.Fprintf(
+			w, "@@ -%s +%s @@\n",
+			unifiedRange(aStart, aLen), unifiedRange(bStart, bLen),
+		); err != nil {
+			return
+		}
+		for _, op := range hunk {
+			line := ""
+			switch op.Kind {
+			case ' ', '-':
+				line = a[op.A]
+			case '+':
+				line = b[op.B]
+			}
+			if _, err = fmt.Fprintf(w, "%c%s", op.Kind, line); err != nil {
+				return
+			}
+			if !strings.HasSuffix(line, "\n") {
+				if _, err = io.WriteString(w, "\n\\ No newline at end of file\n"); err != nil {
+					return
+				}
+			}
+		}
+		i = end
+	}
+	return nil
+}
+
+// unifiedRange formats a hunk range.  start is 0-based.
+func unifiedRange(start, length int) string {
+	switch length {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// testEditDistance is the number of inserted and deleted lines of the
+// shortest edit script from a to b.
+func testEditDistance(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] > lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+// testCheckOps checks that ops transforms a into b with the fewest
+// insertions and deletions.
+func testCheckOps(t *testing.T, a, b []string, ops []diffOp) {
+	t.Helper()
+	var got []string
+	x, y, edits := 0, 0, 0
+	for _, op := range ops {
+		if op.A != x || op.B != y {
+			t.Fatalf("op %c at (%d, %d), want (%d, %d)", op.Kind, op.A, op.B, x, y)
+		}
+		switch op.Kind {
+		case ' ':
+			if a[x] != b[y] {
+				t.Fatalf("kept %q as %q", a[x], b[y])
+			}
+			got = append(got, a[x])
+			x, y = x+1, y+1
+		case '-':
+			x, edits = x+1, edits+1
+		case '+':
+			got = append(got, b[y])
+			y, edits = y+1, edits+1
+		}
+	}
+	if x != len(a) || strings.Join(got, "") != strings.Join(b, "") {
+		t.Fatalf("ops %v transform %q into %q, want %q", ops, a, got, b)
+	}
+	if want := testEditDistance(a, b); edits != want {
+		t.Fatalf("%d edits from %q to %q, want %d", edits, a, b, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	for _, tc := range []struct{ a, b string }{
+		{"", ""},
+		{"", "a\n"},
+		{"a\n", ""},
+		{"a\nb\nc\n", "a\nb\nc\n"},
+		{"a\nb\nc\n", "a\nc\n"},
+		{"a\nc\n", "a\nb\nc\n"},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n"},
+		{"a\r\nb\r\nc\r\n", "a\nb\nc\n"},
+	} {
+		a, b := splitDiffLines(tc.a), splitDiffLines(tc.b)
+		testCheckOps(t, a, b, diffLines(a, b))
+	}
+	r := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, r.Intn(20))
+		for i := range lines {
+			lines[i] = string(rune('a' + r.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		testCheckOps(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLinesAllDifferent(t *testing.T) {
+	const n = 5000
+	a, b := make([]string, n), make([]string, n)
+	for i := range a {
+		a[i] = strings.Repeat("x", i%80) + "\r\n"
+		b[i] = strings.Repeat("x", i%80) + "\n"
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	ops := diffLines(a, b)
+	runtime.ReadMemStats(&after)
+	if len(ops) != 2*n {
+		t.Fatalf("got %d ops, want %d", len(ops), 2*n)
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+		t.Fatalf("allocated %d bytes", alloc)
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a, b string
+		want string
+	}{{
+		name: "change in the middle",
+		a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n",
+		want: "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+	}, {
+		name: "separate hunks",
+		a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+		b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+		want: "@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+			"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+	}, {
+		name: "new file",
+		a:    "",
+		b:    "1\n2\n",
+		want: "@@ -0,0 +1,2 @@\n+1\n+2\n",
+	}, {
+		name: "line endings",
+		a:    "1\r\n2\r\n",
+		b:    "1\n2\n",
+		want: "@@ -1,2 +1,2 @@\n-1\r\n-2\r\n+1\n+2\n",
+	}, {
+		name: "no newline at end of file",
+		a:    "1\n2\n3",
+		b:    "1\n2\n3\n",
+		want: "@@ -1,3 +1,3 @@\n 1\n 2\n-3\n\\ No newline at end of file\n+3\n",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := writeUnifiedDiff(
+				&sb, "a", "b",
+				splitDiffLines(tc.a), splitDiffLines(tc.b),
+			); err != nil {
+				t.Fatal(err)
+			}
+			if want := "--- a\n+++ b\n" + tc.want; sb.String() != want {
+				t.Fatalf("got:\n%q\nwant:\n%q", sb.String(), want)
+			}
+		})
+	}
+}
@@ -47,6 +47,36 @@ var (
 			Value: sqlmodelgen.DrawIOModelContext,
 			Help:  "Draw.io / Diagrams.net ERD",
 		},
+		{
+			Key:   "sql-ddl",
+			Value: sqlmodelgen.SQLDDLParserModelContext,
+			Help:  "SQL DDL script",
+		},
+		{
+			Key:   "dbml",
+			Value: sqlmodelgen.DBMLConfigParserModelContext,
+			Help:  "DBML (dbdiagram.io) schema",
+		},
+		{
+			Key:   "go-structs",
+			Value: sqlmodelgen.GoStructsModelContext,
+			Help:  "Go package with db-tagged structs",
+		},
+		{
+			Key:   "mermaid",
+			Value: sqlmodelgen.MermaidConfigParserModelContext,
+			Help:  "Mermaid erDiagram",
+		},
+		{
+			Key:   "prisma",
+			Value: sqlmodelgen.PrismaConfigParserModelContext,
+			Help:  "Prisma schema",
+		},
+		{
+			Key:   "wvace",
+			Value: sqlmodelgen.WVAceConfigParserModelContext,
+			Help:  "WorkView ACE Excel workbook",
+		},
 	}
 
 	templateChoices = []argModelContextChoice{
@@ -55,6 +85,31 @@ var (
 			Value: sqlmodelgen.CSModelContext,
 			Help:  "C# SQL models",
 		},
+		{
+			Key:   "datadict-html",
+			Value: sqlmodelgen.DataDictHTMLModelContext,
+			Help:  "HTML data dictionary",
+		},
+		{
+			Key:   "datadict-md",
+			Value: sqlmodelgen.DataDictMarkdownModelContext,
+			Help:  "Markdown data dictionary",
+		},
+		{
+			Key:   "dbml",
+			Value: sqlmodelgen.DBMLModelContext,
+			Help:  "DBML (dbdiagram.io) schema",
+		},
+		{
+			Key:   "dot",
+			Value: sqlmodelgen.DotModelContext,
+			Help:  "Graphviz DOT schema diagram",
+		},
+		{
+			Key:   "drawio",
+			Value: sqlmodelgen.DrawIOWriterModelContext,
+			Help:  "Draw.io / Diagrams.net ERD",
+		},
 		{
 			Key:   "go-sql",
 			Value: sqlmodelgen.GoSQLModelContext,
@@ -65,6 +120,16 @@ var (
 			Value: sqlmodelgen.GoModelsModelContext,
 			Help:  "Go domain models",
 		},
+		{
+			Key:   "mermaid",
+			Value: sqlmodelgen.MermaidModelContext,
+			Help:  "Mermaid erDiagram",
+		},
+		{
+			Key:   "prisma",
+			Value: sqlmodelgen.PrismaModelContext,
+			Help:  "Prisma schema",
+		},
 		{
 			Key:   "puwvjson",
 			Value: sqlmodelgen.PUWVJSONModelContext,
@@ -75,6 +140,27 @@ var (
 			Value: sqlmodelgen.MSSQLDDLModelContext,
 			Help:  "SQL DDL for Microsoft SQL Server",
 		},
+		{
+			Key:   "sqlddl-migrate-mssql",
+			Value: sqlmodelgen.MSSQLDDLMigrateModelContext,
+			Help: "SQL DDL for Microsoft SQL Server that migrates " +
+				"from the model in the \"previous\" parameter",
+		},
+		{
+			Key:   "sqlddl-mysql",
+			Value: sqlmodelgen.MySQLSQLDDLModelContext,
+			Help:  "SQL DDL for MySQL and MariaDB",
+		},
+		{
+			Key:   "sqlddl-postgres",
+			Value: sqlmodelgen.PostgresSQLDDLModelContext,
+			Help:  "SQL DDL for PostgreSQL",
+		},
+		{
+			Key:   "sqlddl-sqlite",
+			Value: sqlmodelgen.SQLiteSQLDDLModelContext,
+			Help:  "SQL DDL for SQLite",
+		},
 		{
 			Key:   "wvace",
 			Value: sqlmodelgen.WVAceModelContext,
@@ -85,7 +171,9 @@ var (
 
 type Args struct {
 	LogLevel               logging.Level
+	ProjectFile            string
 	ConfigFile             string
+	Check                  bool
 	GeneratorModelContexts []ArgModelContext
 	TemplateModelContexts  []ArgModelContext
 	valueDefs              []valueDef
@@ -172,9 +260,36 @@ func main() {
 		argparse.Nargs(2),
 		argparse.Help(helpb.String()),
 	).MustBind(&args.TemplateModelContexts)
+	parser.MustAddArgument(
+		argparse.OptionStrings("-f", "--file", "--project"),
+		argparse.MetaVar("PROJECT_FILE"),
+		argparse.Action("store"),
+		argparse.Help(
+			"Load the generators, targets and their "+
+				"parameters from a JSON or YAML project file "+
+				"instead of (or in addition to) the -g, "+
+				"-t and -p options.  The configuration "+
+				"file is also taken from the project "+
+				"file's \"model\" unless it is "+
+				"specified on the command line.",
+		),
+	).MustBind(&args.ProjectFile)
+	parser.MustAddArgument(
+		argparse.OptionStrings("--check"),
+		argparse.Action("store_true"),
+		argparse.Help(
+			"Instead of writing the output files, generate "+
+				"them in memory and compare them against "+
+				"the existing files.  A unified diff is "+
+				"written for every file that is out of "+
+				"date and sqlmodelgen exits with a "+
+				"non-zero status.",
+		),
+	).MustBind(&args.Check)
 	parser.MustAddArgument(
 		argparse.Dest("configfile"),
 		argparse.Action("store"),
+		argparse.Nargs("?"),
 		argparse.Help(
 			"configuration file from which the model is "+
 				"derived except for the \"json\" output type "+
@@ -195,20 +310,51 @@ func main() {
 	// 	&args.TemplateModelContexts,
 	// 	&args.valueDefs,
 	// )
+	if args.ProjectFile != "" {
+		p, err := LoadProject(args.ProjectFile)
+		if err != nil {
+			panic(errors.WithoutParentStackTrace(err))
+		}
+		if err = p.Apply(&args); err != nil {
+			panic(errors.WithoutParentStackTrace(err))
+		}
+	}
 	defs := make([]ArgModelContext,
 		len(args.GeneratorModelContexts),
 		len(args.GeneratorModelContexts)+len(args.TemplateModelContexts))
 	copy(defs, args.GeneratorModelContexts)
 	defs = append(defs, args.TemplateModelContexts...)
 	for _, vd := range args.valueDefs {
+		if vd.target < 0 || vd.target >= len(defs) {
+			panic(errors.Errorf3(
+				"parameter %q refers to index %d, but only "+
+					"%d generators and targets were defined",
+				vd.name, vd.target, len(defs),
+			))
+		}
 		defs[vd.target].Args[vd.name] = vd.value
 	}
 	if err := Main(args); err != nil {
+		if err == errStale {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		panic(errors.WithoutParentStackTrace(err))
 	}
 }
 
+// errStale is returned from Main when Args.Check is set and at least one
+// of the existing output files does not match what would be generated.
+var errStale = errors.Errorf("generated files are out of date")
+
 func Main(args Args) (Err error) {
+	if args.ConfigFile == "" {
+		return errors.Errorf(
+			"a configuration file is required either as a " +
+				"command line argument or as the \"model\" " +
+				"of a project file",
+		)
+	}
 	configReader, err := os.Open(args.ConfigFile)
 	if err != nil {
 		return errors.Errorf1From(
@@ -217,6 +363,7 @@ func Main(args Args) (Err error) {
 		)
 	}
 	defer errors.Catch(&Err, configReader.Close)
+	stale := false
 	var mm *sqlstream.MetaModel
 	// getMetaModel lazily loads the configuration file and re-uses it
 	getMetaModel := func(r io.Reader) (mm2 *sqlstream.MetaModel, err error) {
@@ -242,6 +389,8 @@ func Main(args Args) (Err error) {
 			var err error
 			if amc.ModelFile == "" || amc.ModelFile == "-" {
 				out = nopWriteCloser{os.Stdout}
+			} else if args.Check {
+				out = newCheckWriteCloser(amc.ModelFile, os.Stdout, &stale)
 			} else {
 				out, err = os.Create(amc.ModelFile)
 				if err != nil {
@@ -251,9 +400,23 @@ func Main(args Args) (Err error) {
 					)
 				}
 			}
-			switch mc := amc.ModelContext.(type) {
+			modelCtx := amc.ModelContext
+			if pmc, ok := modelCtx.(sqlmodelgen.ParameterizedModelContext); ok {
+				if modelCtx, err = pmc.WithParameters(amc.Args); err != nil {
+					return errors.Errorf1From(
+						err, "failed to set parameters of %v",
+						amc.ModelFile,
+					)
+				}
+			}
+			switch mc := modelCtx.(type) {
 			case sqlmodelgen.ModelConfigParser:
-				cfg, err := mc.ParseModelConfig(context.TODO(), configReader)
+				var cfg sqlmodelgen.Config
+				if emc, ok := mc.(sqlmodelgen.ExtModelConfigParser); ok {
+					cfg, err = emc.ParseExtModelConfig(context.TODO(), configReader)
+				} else {
+					cfg.Config, err = mc.ParseModelConfig(context.TODO(), configReader)
+				}
 				if err != nil {
 					return errors.Errorf1From(
 						err, "failed to parse model configuration from %v",
@@ -279,7 +442,7 @@ func Main(args Args) (Err error) {
 				}
 				switch mc := mc.(type) {
 				case sqlmodelgen.ModelConfigWriter:
-					err = mc.WriteModelConfig(out, cfg)
+					err = mc.WriteModelConfig(out, cfg.Config)
 					if err != nil {
 						return errors.Errorf2From(
 							err, "failed to write "+
@@ -318,7 +481,7 @@ func Main(args Args) (Err error) {
 				if mm, err = getMetaModel(configReader); err != nil {
 					return err
 				}
-				td, err := sqlmodelgen.TemplateDataFromMetaModel(mm, amc.ModelContext)
+				td, err := sqlmodelgen.TemplateDataFromMetaModel(mm, modelCtx)
 				if err != nil {
 					return err
 				}
@@ -339,7 +502,7 @@ func Main(args Args) (Err error) {
 				}
 				fm := make(template.FuncMap, 8)
 				t := sqlmodelgen.AddFuncs(
-					template.New("<sqlmodelgen>"), fm, amc.ModelContext,
+					template.New("<sqlmodelgen>"), fm, modelCtx,
 				).Funcs(fm)
 				if td, ok := amc.Args[templateDirParam]; ok {
 					t, err = t.ParseFiles(td, "*.txt")
@@ -382,11 +545,19 @@ func Main(args Args) (Err error) {
 				if mm, err = getMetaModel(configReader); err != nil {
 					return err
 				}
-				td, err := sqlmodelgen.TemplateDataFromMetaModel(mm, amc.ModelContext)
+				td, err := sqlmodelgen.TemplateDataFromMetaModel(mm, modelCtx)
 				if err != nil {
 					return err
 				}
+				for k, v := range amc.Args {
+					td.Parameters[k] = v
+				}
 				td.Namespace = amc.Args[namespaceParam]
+				if args.Check {
+					td.CreateFile = func(filename string) (io.WriteCloser, error) {
+						return newCheckWriteCloser(filename, os.Stdout, &stale), nil
+					}
+				}
 				if err = mc.WriteTemplateData(out, td); err != nil {
 					return errors.Errorf1From(
 						err, "error executing template data "+
@@ -398,7 +569,7 @@ func Main(args Args) (Err error) {
 			default:
 				return errors.Errorf1(
 					"Unknown model context %[1]v (type: %[1]T)",
-					amc.ModelContext,
+					modelCtx,
 				)
 			}
 			if err := out.Close(); err != nil {
@@ -408,6 +579,9 @@ func Main(args Args) (Err error) {
 			}
 		}
 	}
+	if stale {
+		return errStale
+	}
 	return nil
 }
 
@@ -441,26 +615,19 @@ func (t templateAction) UpdateNamespace(a *argparse.Argument, ns argparse.Namesp
 	if !ok {
 		s = fmt.Sprint(vs[0])
 	}
-	handledKey := false
-	for _, c := range templateChoices {
-		if c.Key != s {
-			continue
-		}
-		handledKey = true
-		amc := ArgModelContext{
-			ModelContext: c.Value.(sqlmodelgen.ModelContext),
-		}
-		if s, ok = vs[1].(string); !ok {
-			s = fmt.Sprint(vs[1])
-		}
-		amc.ModelFile = s
-		amc.Args = make(map[string]string)
-		ns.Append(a, amc)
-		break
-	}
-	if !handledKey {
+	c, ok := lookupModelContextChoice(templateChoices, s)
+	if !ok {
 		return errors.Errorf1("unknown type choice: %q", s)
 	}
+	amc := ArgModelContext{
+		ModelContext: c.Value,
+	}
+	if s, ok = vs[1].(string); !ok {
+		s = fmt.Sprint(vs[1])
+	}
+	amc.ModelFile = s
+	amc.Args = make(map[string]string)
+	ns.Append(a, amc)
 	return nil
 }
 
@@ -470,6 +637,16 @@ type argModelContextChoice struct {
 	Help  string
 }
 
+// lookupModelContextChoice finds the choice with the given key.
+func lookupModelContextChoice(choices []argModelContextChoice, key string) (argModelContextChoice, bool) {
+	for _, c := range choices {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return argModelContextChoice{}, false
+}
+
 type generatorAction struct{}
 
 var _ argparse.ArgumentAction = generatorAction{}
@@ -487,25 +664,19 @@ func (g generatorAction) UpdateNamespace(a *argparse.Argument, ns argparse.Names
 	if !ok {
 		s = fmt.Sprint(vs[0])
 	}
-	handledKey := false
-	for _, c := range generatorChoices {
-		if c.Key != s {
-			continue
-		}
-		handledKey = true
-		amc := ArgModelContext{
-			ModelContext: c.Value.(sqlmodelgen.ModelContext),
-		}
-		if s, ok = vs[1].(string); !ok {
-			s = fmt.Sprint(vs[1])
-		}
-		amc.ModelFile = s
-		ns.Append(a, amc)
-		break
-	}
-	if !handledKey {
+	c, ok := lookupModelContextChoice(generatorChoices, s)
+	if !ok {
 		return errors.Errorf1("unknown type choice: %q", s)
 	}
+	amc := ArgModelContext{
+		ModelContext: c.Value,
+	}
+	if s, ok = vs[1].(string); !ok {
+		s = fmt.Sprint(vs[1])
+	}
+	amc.ModelFile = s
+	amc.Args = make(map[string]string)
+	ns.Append(a, amc)
 	return nil
 }
 
@@ -559,7 +730,7 @@ func (valueAction) UpdateNamespace(a *argparse.Argument, ns argparse.Namespace,
 	}
 	vd.value, ok = vs[2].(string)
 	if !ok {
-		vd.name = fmt.Sprint(vs[2])
+		vd.value = fmt.Sprint(vs[2])
 	}
 	ns.Append(a, vd)
 	return nil
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/skillian/expr/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a project manifest that declares a whole sqlmodelgen pipeline
+// so that it can be run with just the -f option instead of the
+// positional -g, -t and -p options.  Project files are JSON or, if their
+// extension is .yaml or .yml, YAML.  For example:
+//
+//	{
+//		"model": "models.json",
+//		"targets": [
+//			{
+//				"type": "sqlddl-mssql",
+//				"output": "create-schema.sql"
+//			},
+//			{
+//				"type": "go-sql",
+//				"output": "sqldata/models.go",
+//				"parameters": { "namespace": "sqldata" }
+//			}
+//		]
+//	}
+//
+// Relative paths are relative to the directory of the project file.
+type Project struct {
+	// Model is the configuration file that the model is derived from.
+	// When the project has generators, it is the generators' input
+	// (e.g. a Draw.io diagram).  Otherwise, it is a models.json file.
+	Model string `json:"model" yaml:"model"`
+
+	// Generators are run before Targets and produce the model used by
+	// the Targets.
+	Generators []ProjectStep `json:"generators,omitempty" yaml:"generators,omitempty"`
+
+	// Targets each generate an output file from the model.
+	Targets []ProjectStep `json:"targets,omitempty" yaml:"targets,omitempty"`
+}
+
+// ProjectStep is a single generator or target within a Project.
+type ProjectStep struct {
+	// Type is the name of the generator or target (e.g. "drawio",
+	// "go-sql", "sqlddl-mssql", etc.)
+	Type string `json:"type" yaml:"type"`
+
+	// Output is the file that the step writes to.  If blank or "-",
+	// output is written to stdout.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+
+	// Parameters are the named parameters passed to the step.  These
+	// are the same parameters that can be specified with the -p
+	// command line option.
+	Parameters map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// LoadProject loads a Project from a JSON or YAML project file.
+func LoadProject(filename string) (p Project, err error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return p, errors.Errorf1From(
+			err, "failed to read project file %q", filename,
+		)
+	}
+	unmarshal := json.Unmarshal
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	}
+	if err = unmarshal(bs, &p); err != nil {
+		return p, errors.Errorf1From(
+			err, "failed to parse project file %q", filename,
+		)
+	}
+	dir := filepath.Dir(filename)
+	p.Model = projectPath(dir, p.Model)
+	for _, steps := range [][]ProjectStep{p.Generators, p.Targets} {
+		for i := range steps {
+			steps[i].Output = projectPath(dir, steps[i].Output)
+		}
+	}
+	return
+}
+
+// projectPath makes path relative to the project directory unless it is
+// absolute or refers to stdin/stdout.
+func projectPath(dir, path string) string {
+	if path == "" || path == "-" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// Apply appends the Project's generators and targets to args.  The
+// Project's model file is only used if args doesn't already have one (e.g.
+// from the command line).
+func (p Project) Apply(args *Args) error {
+	if args.ConfigFile == "" {
+		args.ConfigFile = p.Model
+	}
+	for _, x := range []struct {
+		kind    string
+		steps   []ProjectStep
+		choices []argModelContextChoice
+		amcs    *[]ArgModelContext
+	}{
+		{"generator", p.Generators, generatorChoices, &args.GeneratorModelContexts},
+		{"target", p.Targets, templateChoices, &args.TemplateModelContexts},
+	} {
+		for i, step := range x.steps {
+			c, ok := lookupModelContextChoice(x.choices, step.Type)
+			if !ok {
+				return errors.Errorf3(
+					"unknown %v type %q at index %d "+
+						"of the project file",
+					x.kind, step.Type, i,
+				)
+			}
+			amc := ArgModelContext{
+				ModelContext: c.Value,
+				ModelFile:    step.Output,
+				Args:         make(map[string]string, len(step.Parameters)),
+			}
+			for k, v := range step.Parameters {
+				amc.Args[k] = v
+			}
+			*x.amcs = append(*x.amcs, amc)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadProject(t *testing.T) {
+	dir := t.TempDir()
+	want := Project{
+		Model: filepath.Join(dir, "models.json"),
+		Targets: []ProjectStep{
+			{
+				Type:       "sqlddl-mssql",
+				Output:     filepath.Join(dir, "create-schema.sql"),
+				Parameters: map[string]string{"namespace": "ignored"},
+			},
+			{
+				Type:       "go-sql",
+				Output:     "-",
+				Parameters: map[string]string{"namespace": "sqldata", "version": "2"},
+			},
+		},
+	}
+	for _, tc := range []struct{ filename, src string }{
+		{"sqlmodelgen.json", `{
+			"model": "models.json",
+			"targets": [
+				{
+					"type": "sqlddl-mssql",
+					"output": "create-schema.sql",
+					"parameters": { "namespace": "ignored" }
+				},
+				{
+					"type": "go-sql",
+					"output": "-",
+					"parameters": { "namespace": "sqldata", "version": "2" }
+				}
+			]
+		}`},
+		{"project.yaml", `# The same project in YAML.
+model: models.json
+targets:
+  - type: sqlddl-mssql
+    output: create-schema.sql
+    parameters:
+      namespace: ignored
+  - type: go-sql
+    output: "-"
+    parameters: {namespace: sqldata, version: 2}
+`},
+	} {
+		t.Run(tc.filename, func(t *testing.T) {
+			filename := filepath.Join(dir, tc.filename)
+			if err := ioutil.WriteFile(filename, []byte(tc.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			p, err := LoadProject(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, want) {
+				t.Fatalf("got %+v, want %+v", p, want)
+			}
+		})
+	}
+	filename := filepath.Join(dir, "invalid.yml")
+	if err := ioutil.WriteFile(filename, []byte("targets: {type: go-sql"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProject(filename); err == nil {
+		t.Fatal("loaded an invalid YAML project file")
+	}
+}
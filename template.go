@@ -157,6 +157,25 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 		Kind   string
 		Path   string
 		Column *sqlstream.Column
+
+		// ForeignKey and Index are only set for "fkkey" columns:
+		// the multi-column foreign key that the column is part of and
+		// the column's index within it.
+		ForeignKey *ForeignKey
+		Index      int
+	}
+	isKeyColumn := func(t *sqlstream.Table, c *sqlstream.Column) bool {
+		if t.PK != nil && t.PK.Column == c {
+			return true
+		}
+		if t.Key != nil {
+			for _, id := range t.Key.IDs {
+				if id.Column == c {
+					return true
+				}
+			}
+		}
+		return false
 	}
 	add(m, "allmodelcolumns", func(t *sqlstream.Table) (res []modelColumn) {
 		res = make([]modelColumn, 0, len(t.Columns))
@@ -185,6 +204,37 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 					}
 				}
 			}
+			// Multi-column foreign keys to composite keys are
+			// held in a field of the referenced key's type unless
+			// some of their columns are already in the table's own
+			// key.
+			if fk := ColumnExtOf(c).ForeignKey; fk != nil && fk.RefKey != nil {
+				inKey := false
+				for _, fc := range fk.Columns {
+					if isKeyColumn(t, fc) {
+						inKey = true
+						break
+					}
+				}
+				if !inKey {
+					for i, fc := range fk.Columns {
+						if fc != c {
+							continue
+						}
+						res = append(res, modelColumn{
+							Kind: "fkkey",
+							Path: strings.Join([]string{
+								fk.ModelName,
+								fk.RefKey.IDs[i].ModelName,
+							}, "."),
+							Column:     c,
+							ForeignKey: fk,
+							Index:      i,
+						})
+						continue columnLoop
+					}
+				}
+			}
 			if c.FK != nil {
 				res = append(res, modelColumn{
 					Kind:   "fk",
@@ -201,6 +251,12 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 		}
 		return
 	})
+	add(m, "fail", func(format string, args ...interface{}) (string, error) {
+		return "", errors.Errorf(format, args...)
+	})
+	add(m, "databaseext", DatabaseExtOf)
+	add(m, "tableext", TableExtOf)
+	add(m, "columnext", ColumnExtOf)
 	add(m, "pair", pair)
 	add(m, "dict", dict)
 	add(m, "set", set)
@@ -211,6 +267,14 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 		})
 	}
 	add(m, "isnullable", sqltypes.IsNullable)
+	add(m, "nonnullable", func(t sqltypes.Type) sqltypes.Type {
+		if sqltypes.IsNullable(t) {
+			t = t.(sqltypes.Nullable)[0]
+		}
+		return t
+	})
+	add(m, "isint", isIntType)
+	add(m, "isidentity", isIdentityColumn)
 	add(m, "basemodeltype", func(t sqltypes.Type) (name string, err error) {
 		_ = sqltypes.IterInners(t, func(x sqltypes.Type) error {
 			t = x
@@ -221,18 +285,7 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 		_, name, err = mc.ModelType(t)
 		return
 	})
-	specialPluralEndings := []string{"s", "x", "z"}
-	add(m, "pluralize", func(name string) string {
-		if strings.HasSuffix(name, "y") {
-			return name[:len(name)-1] + "ies"
-		}
-		for _, suffix := range specialPluralEndings {
-			if strings.HasSuffix(name, suffix) {
-				return name + "es"
-			}
-		}
-		return name + "s"
-	})
+	add(m, "pluralize", pluralize)
 	add(m, "isassoctable", func(t *sqlstream.Table) bool {
 		if len(t.Columns) != 2 {
 			return false
@@ -267,3 +320,39 @@ func AddFuncs(t *template.Template, m template.FuncMap, mc ModelContext) *templa
 	}
 	return t
 }
+
+func isIntType(t sqltypes.Type) bool {
+	if sqltypes.IsNullable(t) {
+		t = t.(sqltypes.Nullable)[0]
+	}
+	_, ok := t.(sqltypes.IntType)
+	return ok
+}
+
+// isIdentityColumn checks if an integer column's values are generated by the
+// database:  Either the model marks it as generated or it's its table's
+// single primary key column and isn't a foreign key.
+func isIdentityColumn(c *sqlstream.Column) bool {
+	if !isIntType(c.Type) {
+		return false
+	}
+	if ColumnExtOf(c).Generated {
+		return true
+	}
+	return c.Table.PK != nil && c.Table.PK.Column == c && c.FK == nil
+}
+
+var specialPluralEndings = []string{"s", "x", "z"}
+
+// pluralize naively pluralizes an English name.
+func pluralize(name string) string {
+	if strings.HasSuffix(name, "y") {
+		return name[:len(name)-1] + "ies"
+	}
+	for _, suffix := range specialPluralEndings {
+		if strings.HasSuffix(name, suffix) {
+			return name + "es"
+		}
+	}
+	return name + "s"
+}
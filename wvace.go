@@ -1,6 +1,7 @@
 package sqlmodelgen
 
 import (
+	"context"
 	"io"
 	"strconv"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/skillian/expr/errors"
 	"github.com/skillian/expr/stream/sqlstream"
+	"github.com/skillian/expr/stream/sqlstream/config"
 	"github.com/skillian/expr/stream/sqlstream/sqltypes"
 	"github.com/xuri/excelize/v2"
 )
@@ -79,17 +81,27 @@ func (wvAceModelContext) WriteMetaModel(w io.Writer, c *sqlstream.MetaModel) (er
 					}
 					s.RelatedClass = wvAceClassName(fkCol.Table)
 					s.DataType = "Relation"
-					if strings.HasSuffix(s.DisplayName, "ID") ||
-						strings.HasSuffix(s.DisplayName, "Id") {
-						s.DisplayName = strings.TrimSpace(
-							s.DisplayName[:len(s.DisplayName)-len("ID")],
-						)
+					if name, ok := wvAceTrimIDSuffix(s.DisplayName); ok {
+						s.DisplayName = name
 					}
 				}
 				s.Description = col.Doc
-				// TODO: Column datasets?
-				// TODO: Column default values?
+				s.DataSet = ""
+				if e := ColumnExtOf(col).Enum; e != nil {
+					s.DataSet = e.ModelName
+				}
+				s.DefaultValue = wvAceDefaultValue(col)
 				s.PrimaryAttribute = col.PK
+				s.Index = ""
+				for j, ix := range ColumnExtOf(col).Indexes {
+					if j > 0 {
+						s.Index += ", "
+					}
+					s.Index += ix.Name
+					if ix.Unique {
+						s.Index += wvAceUniqueIndexSuffix
+					}
+				}
 				if err = s.writeRow(f, wvClassName, i+2); err != nil {
 					return errors.Errorf2From(
 						err, "error while writing "+
@@ -126,6 +138,10 @@ var wvAceClassSheetHeaders = []string{
 	"Primary Attribute",
 }
 
+// wvAceUniqueIndexSuffix follows the names of unique indexes in the Index
+// column.
+const wvAceUniqueIndexSuffix = " (unique)"
+
 func createWVAceClassSheet(f *excelize.File, name string) {
 	_ = f.NewSheet(name)
 	for i, h := range wvAceClassSheetHeaders {
@@ -171,7 +187,7 @@ func wvAceType(t sqltypes.Type) (dataType string, lengthPrecision int, err error
 			return "Text", 0, nil
 		}
 		if t.Length < 256 {
-			return "Alphanumeric", 0, nil
+			return "Alphanumeric", t.Length, nil
 		}
 		return "Text", 0, nil
 	}
@@ -181,30 +197,73 @@ func wvAceType(t sqltypes.Type) (dataType string, lengthPrecision int, err error
 	)
 }
 
+// wvAceTrimIDSuffix removes the "ID" word from the end of a relation's name
+// (e.g. "customer id", "customer_ID" or "CustomerId" become "customer",
+// "customer" or "Customer").  The suffix is matched case-insensitively
+// but only as its own word, so names like "paid" are unchanged.
+func wvAceTrimIDSuffix(name string) (string, bool) {
+	n := len(name)
+	if n <= 2 || !strings.EqualFold(name[n-2:], "id") {
+		return name, false
+	}
+	prefix := name[:n-2]
+	last := rune(prefix[len(prefix)-1])
+	switch {
+	case last == ' ' || last == '_' || last == '-':
+		prefix = strings.TrimRight(prefix, " _-")
+	case name[n-2] == 'I' && (unicode.IsLower(last) || unicode.IsDigit(last)):
+	default:
+		return name, false
+	}
+	if prefix == "" {
+		return name, false
+	}
+	return prefix, true
+}
+
+// wvAceAddIDSuffix adds the "ID" word that wvAceTrimIDSuffix removes back
+// to a relation's name unless it already has one.  Lower case names get
+// " id" so that raw names like "customer id" survive a round trip.
+func wvAceAddIDSuffix(name string) string {
+	if _, ok := wvAceTrimIDSuffix(name); ok || strings.EqualFold(name, "id") {
+		return name
+	}
+	if name == strings.ToLower(name) {
+		return name + " id"
+	}
+	return name + " ID"
+}
+
 func wvAceClassName(tbl *sqlstream.Table) string {
 	prefix := tbl.Schema.ModelName
 	if prefix == "" {
-		donefirst := false
-		space := false
-		prefix = strings.Map(func(r rune) rune {
-			if !donefirst {
-				donefirst = true
-				return unicode.ToUpper(r)
-			}
-			if unicode.IsSpace(r) {
-				space = true
-				return -1
-			}
-			if space {
-				space = false
-				return unicode.ToLower(r)
-			}
-			return -1
-		}, tbl.Schema.Database.RawName)
+		prefix = wvAceClassPrefix(tbl.Schema.Database.RawName)
 	}
 	return prefix + tbl.ModelName
 }
 
+// wvAceClassPrefix gets the class name prefix from the initials of a
+// database's raw name.
+func wvAceClassPrefix(dbRawName string) string {
+	donefirst := false
+	space := false
+	return strings.Map(func(r rune) rune {
+		if !donefirst {
+			donefirst = true
+			return unicode.ToUpper(r)
+		}
+		if unicode.IsSpace(r) {
+			space = true
+			return -1
+		}
+		if space {
+			space = false
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, dbRawName)
+}
+
 type wvAceClassSheet struct {
 	DisplayName      string
 	DataType         string
@@ -266,3 +325,389 @@ func (s *wvAceClassSheet) writeRow(f *excelize.File, sheet string, index int) (e
 	}
 	return nil
 }
+
+// wvAceDatabaseParam is the name of the parameter that holds the raw name
+// of the database that an ACE workbook's classes are parsed into.
+const wvAceDatabaseParam = "database"
+
+var (
+	// WVAceConfigParserModelContext reads a WorkView ACE workbook
+	// (e.g. one written by WVAceModelContext) into a model
+	// configuration.
+	WVAceConfigParserModelContext interface {
+		ModelContext
+		ModelConfigParser
+		ExtModelConfigParser
+		ParameterizedModelContext
+	} = wvAceConfigParser{}
+)
+
+// wvAceConfigParser is separate from wvAceModelContext so that the wvace
+// template isn't mistaken for a ModelConfigParser.
+type wvAceConfigParser struct {
+	// database is the raw name of the database.  If set, the prefix
+	// that wvAceClassName would add to the class names is removed
+	// from the sheet names.
+	database string
+}
+
+func (wvAceConfigParser) ModelType(t sqltypes.Type) (namespace, typename string, err error) {
+	return "", t.String(), nil
+}
+
+func (p wvAceConfigParser) WithParameters(ps map[string]string) (ModelContext, error) {
+	if s := ps[wvAceDatabaseParam]; s != "" {
+		p.database = s
+	}
+	return p, nil
+}
+
+// wvAceParsedClass is a class (sheet) read from an ACE workbook.
+type wvAceParsedClass struct {
+	Sheet string
+	Rows  []wvAceClassSheet
+
+	// table is the index of the class's table in the schema.
+	table int
+
+	// pk is the index of the table's primary key column or -1 if
+	// the table has a composite key.
+	pk     int
+	pkType sqltypes.Type
+}
+
+func (p wvAceConfigParser) ParseModelConfig(ctx context.Context, r io.Reader) (config.Config, error) {
+	cfg, err := p.ParseExtModelConfig(ctx, r)
+	return cfg.Config, err
+}
+
+// ParseExtModelConfig reads the workbook like ParseModelConfig and also
+// reads the attributes' Data Sets as enumerations, their Default Values
+// and their Indexes.  The members of the data sets aren't in the workbook,
+// so they have to be added to the enumerations afterwards.
+func (p wvAceConfigParser) ParseExtModelConfig(ctx context.Context, r io.Reader) (cfg Config, err error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return cfg, errors.Errorf1From(
+			err, "failed to open Excel file from %v", r,
+		)
+	}
+	prefix, dbName := "", "workview"
+	if p.database != "" {
+		prefix, dbName = wvAceClassPrefix(p.database), p.database
+	}
+	cfg.Databases = []config.Database{
+		{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: dbName,
+				},
+			},
+			Schemas: []config.Schema{
+				{
+					Tables: make([]config.Table, 0, 8),
+				},
+			},
+		},
+	}
+	sch := &cfg.Databases[0].Schemas[0]
+	sheets := f.GetSheetList()
+	classes := make([]*wvAceParsedClass, 0, len(sheets))
+	classesByName := make(map[string]*wvAceParsedClass, len(sheets))
+	for _, sheet := range sheets {
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return cfg, errors.Errorf1From(
+				err, "failed to read rows of sheet %q", sheet,
+			)
+		}
+		if len(rows) == 0 {
+			logger.Warn1("ignoring empty sheet %q", sheet)
+			continue
+		}
+		cls := &wvAceParsedClass{Sheet: sheet, pk: -1}
+		if cls.Rows, err = readWVAceClassSheet(rows); err != nil {
+			return cfg, errors.Errorf1From(
+				err, "failed to read class from sheet %q", sheet,
+			)
+		}
+		className := sheet
+		if prefix != "" && strings.HasPrefix(className, prefix) {
+			rest := className[len(prefix):]
+			if rest != "" && unicode.IsUpper([]rune(rest)[0]) {
+				className = rest
+			}
+		}
+		cls.table = len(sch.Tables)
+		sch.Tables = append(sch.Tables, config.Table{
+			CommonData: config.CommonData{
+				Names: config.Names{
+					RawName: identifierRawName(className),
+				},
+			},
+			Columns: make([]config.Column, 0, len(cls.Rows)+1),
+		})
+		classes = append(classes, cls)
+		classesByName[sheet] = cls
+	}
+	// The primary keys have to be known before the relations can
+	// reference them.
+	for _, cls := range classes {
+		tbl := &sch.Tables[cls.table]
+		pks := 0
+		for _, row := range cls.Rows {
+			if row.PrimaryAttribute {
+				pks++
+			}
+		}
+		offset := 0
+		if pks == 0 {
+			// WorkView relations refer to the related object's
+			// ObjectID.  wvAceModelContext doesn't write it, so
+			// it's added back here.
+			cls.pk, cls.pkType = 0, sqltypes.IntType{Bits: 64}
+			tbl.Columns = append(tbl.Columns, config.Column{
+				CommonData: config.CommonData{
+					Names: config.Names{
+						RawName: "ObjectID",
+					},
+				},
+				Type: cls.pkType.String(),
+				PK:   true,
+			})
+			offset = 1
+		}
+		for i, row := range cls.Rows {
+			if !row.PrimaryAttribute || pks != 1 {
+				continue
+			}
+			cls.pk = offset + i
+			if strings.EqualFold(row.DataType, "Relation") {
+				// its type is the type of the related class's
+				// primary key, which might not be known yet.
+				continue
+			}
+			cls.pkType, err = wvAceParseType(row.DataType, row.LengthPrecision)
+			if err != nil {
+				return cfg, errors.Errorf2From(
+					err, "failed to get type of attribute "+
+						"%q of class %q",
+					row.DisplayName, cls.Sheet,
+				)
+			}
+		}
+	}
+	for _, cls := range classes {
+		tbl := &sch.Tables[cls.table]
+		for _, row := range cls.Rows {
+			col := config.Column{
+				CommonData: config.CommonData{
+					Names: config.Names{
+						RawName: row.DisplayName,
+					},
+					Doc: row.Description,
+				},
+				PK: row.PrimaryAttribute,
+			}
+			var t sqltypes.Type
+			if strings.EqualFold(row.DataType, "Relation") {
+				rel, ok := classesByName[row.RelatedClass]
+				if !ok {
+					rel, ok = classesByName[prefix+row.RelatedClass]
+				}
+				if !ok {
+					return cfg, errors.Errorf3(
+						"attribute %q of class %q is "+
+							"related to unknown class %q",
+						row.DisplayName, cls.Sheet,
+						row.RelatedClass,
+					)
+				}
+				if rel.pk == -1 || rel.pkType == nil {
+					return cfg, errors.Errorf3(
+						"attribute %q of class %q cannot "+
+							"be related to class %q because "+
+							"its primary attributes are not "+
+							"a single, non-relation attribute",
+						row.DisplayName, cls.Sheet, rel.Sheet,
+					)
+				}
+				relTbl := &sch.Tables[rel.table]
+				col.FK = relTbl.RawName + "." + relTbl.Columns[rel.pk].RawName
+				t = rel.pkType
+				// wvAceModelContext removes the "ID" suffix from
+				// relations' display names.
+				col.RawName = wvAceAddIDSuffix(col.RawName)
+			} else if t, err = wvAceParseType(row.DataType, row.LengthPrecision); err != nil {
+				return cfg, errors.Errorf2From(
+					err, "failed to get type of attribute "+
+						"%q of class %q",
+					row.DisplayName, cls.Sheet,
+				)
+			}
+			if err = p.parseColumnExt(&cfg, tbl.RawName, col.RawName, row, t); err != nil {
+				return cfg, errors.Errorf2From(
+					err, "failed to read attribute %q of class %q",
+					row.DisplayName, cls.Sheet,
+				)
+			}
+			if !col.PK {
+				// WorkView attributes can always be blank.
+				t = sqltypes.Nullable{t}
+			}
+			col.Type = t.String()
+			tbl.Columns = append(tbl.Columns, col)
+		}
+	}
+	return
+}
+
+// parseColumnExt reads the Data Set, Default Value and Index of an
+// attribute of type t into the extensions of its column.
+func (p wvAceConfigParser) parseColumnExt(cfg *Config, tblName, colName string, row wvAceClassSheet, t sqltypes.Type) error {
+	dbName := cfg.Databases[0].RawName
+	if row.DefaultValue != "" {
+		cfg.Ext.Column(dbName, "", tblName, colName).Default = wvAceParseDefaultValue(row.DefaultValue, t)
+	}
+	if row.DataSet != "" {
+		switch t.(type) {
+		case sqltypes.IntType, sqltypes.StringType:
+		default:
+			return errors.Errorf2(
+				"data set %q cannot hold %v values",
+				row.DataSet, t,
+			)
+		}
+		enumName := identifierRawName(row.DataSet)
+		cfg.Ext.Column(dbName, "", tblName, colName).Enum = enumName
+		db := cfg.Ext.findDatabase(dbName)
+		found := false
+		for _, e := range db.Enums {
+			if e.RawName == enumName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			db.Enums = append(db.Enums, EnumConfig{
+				CommonData: config.CommonData{
+					Names: config.Names{
+						RawName: enumName,
+					},
+				},
+				Type:    t.String(),
+				Members: []EnumMemberConfig{},
+			})
+		}
+	}
+	for _, name := range strings.Split(row.Index, ",") {
+		name = strings.TrimSpace(name)
+		unique := strings.HasSuffix(name, wvAceUniqueIndexSuffix)
+		if unique {
+			name = strings.TrimSpace(strings.TrimSuffix(name, wvAceUniqueIndexSuffix))
+		}
+		if name == "" {
+			continue
+		}
+		// The key columns are in the order of the attributes.
+		tbl := cfg.Ext.Table(dbName, "", tblName)
+		i := 0
+		for i < len(tbl.Indexes) && tbl.Indexes[i].Name != name {
+			i++
+		}
+		if i == len(tbl.Indexes) {
+			tbl.Indexes = append(tbl.Indexes, IndexConfig{Name: name})
+		}
+		ix := &tbl.Indexes[i]
+		ix.Unique = ix.Unique || unique
+		ix.Columns = append(ix.Columns, IndexColumnConfig{RawName: colName})
+	}
+	return nil
+}
+
+// readWVAceClassSheet reads the attributes from the rows of a class sheet.
+// The first row must have the headers from wvAceClassSheetHeaders but they
+// can be in any order and only Display Name and Data Type are required.
+func readWVAceClassSheet(rows [][]string) (ss []wvAceClassSheet, err error) {
+	headers := make(map[string]int, len(wvAceClassSheetHeaders))
+	for i, h := range rows[0] {
+		headers[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range wvAceClassSheetHeaders[:2] {
+		if _, ok := headers[strings.ToLower(h)]; !ok {
+			return nil, errors.Errorf1(
+				"missing %q header", h,
+			)
+		}
+	}
+	ss = make([]wvAceClassSheet, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		cell := func(header string) string {
+			j, ok := headers[strings.ToLower(header)]
+			if !ok || j >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[j])
+		}
+		s := wvAceClassSheet{
+			DisplayName:  cell("Display Name"),
+			DataType:     cell("Data Type"),
+			RelatedClass: cell("Related Class"),
+			Description:  cell("Description"),
+			DataSet:      cell("Data Set"),
+			DefaultValue: cell("Default Value"),
+			Index:        cell("Index"),
+			Filters:      cell("Filters"),
+			Views:        cell("Views"),
+			Sections:     cell("Sections"),
+		}
+		if s.DisplayName == "" {
+			continue
+		}
+		if v := cell("Length / Precision"); v != "" {
+			if s.LengthPrecision, err = strconv.Atoi(v); err != nil {
+				return nil, errors.Errorf2From(
+					err, "invalid length / precision of "+
+						"row %d: %q",
+					i+2, v,
+				)
+			}
+		}
+		switch strings.ToLower(cell("Primary Attribute")) {
+		case "true", "yes", "y", "x", "1":
+			s.PrimaryAttribute = true
+		}
+		ss = append(ss, s)
+	}
+	return
+}
+
+// wvAceParseType is the inverse of wvAceType.
+func wvAceParseType(dataType string, lengthPrecision int) (sqltypes.Type, error) {
+	switch strings.ToLower(dataType) {
+	case "boolean":
+		return sqltypes.BoolType{}, nil
+	case "integer":
+		return sqltypes.IntType{Bits: 64}, nil
+	case "floating point":
+		return sqltypes.FloatType{Mantissa: 53}, nil
+	case "decimal":
+		return sqltypes.DecimalType{Prec: lengthPrecision}, nil
+	case "alphanumeric":
+		if lengthPrecision <= 0 {
+			return nil, errors.Errorf(
+				"Alphanumeric attributes require a length",
+			)
+		}
+		return sqltypes.StringType{Length: lengthPrecision}, nil
+	case "text":
+		return sqltypes.StringType{Var: true}, nil
+	case "date":
+		return sqltypes.TimeType{Prec: 24 * time.Hour}, nil
+	case "date/time":
+		return sqltypes.TimeType{}, nil
+	}
+	return nil, errors.Errorf1(
+		"unknown WorkView ACE data type: %q", dataType,
+	)
+}
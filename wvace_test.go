@@ -0,0 +1,85 @@
+package sqlmodelgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWVAceRoundTrip(t *testing.T) {
+	mm := testMetaModel(t, `{
+		"databases": [{
+			"rawName": "shop",
+			"enums": [{
+				"rawName": "order status",
+				"type": "string(length: 8)",
+				"members": [{"rawName": "pending"}, {"rawName": "shipped"}]
+			}],
+			"schemas": [{
+				"rawName": "",
+				"tables": [{
+					"rawName": "order",
+					"columns": [
+						{"rawName": "order number", "type": "int(bits: 64)", "pk": true},
+						{"rawName": "status", "type": "string(length: 8)", "enum": "order status", "default": "'pending'"},
+						{"rawName": "quantity", "type": "int(bits: 64)", "default": "1"},
+						{"rawName": "rush", "type": "bool", "default": "FALSE"},
+						{"rawName": "code", "type": "bytes(length: 4)"},
+						{"rawName": "email", "type": "nullable(string(var: true, length: 128))"}
+					],
+					"indexes": [
+						{"unique": true, "columns": ["email"]},
+						{"name": "IX_order_status_quantity", "columns": ["status", "quantity"]}
+					]
+				}]
+			}]
+		}]
+	}`)
+	var buf bytes.Buffer
+	if err := WVAceModelContext.WriteMetaModel(&buf, mm); err != nil {
+		t.Fatal(err)
+	}
+	mc, err := WVAceConfigParserModelContext.WithParameters(map[string]string{"database": "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := mc.(ExtModelConfigParser).ParseExtModelConfig(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := MetaModelFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ReleaseMetaModel(parsed)
+	var sb strings.Builder
+	sb.WriteString(modelSummary(parsed))
+	for _, c := range parsed.Databases[0].Schemas[0].Tables[0].Columns {
+		x := ColumnExtOf(c)
+		if x.Default != "" {
+			fmt.Fprintf(&sb, "%v default %v\n", c.SQLName, x.Default)
+		}
+		if x.Enum != nil {
+			fmt.Fprintf(&sb, "%v enum %v %v\n", c.SQLName, x.Enum.ModelName, testTypeName(x.Enum.Type))
+		}
+	}
+	want := `Order
+	OrderNumber int64 pk
+	Status nullable(char(8))
+	Quantity nullable(int64)
+	Rush nullable(bool)
+	Code nullable(char(4))
+	Email nullable(varchar(0))
+	index IX_order_status_quantity (Status, Quantity)
+	unique UX_Order_Email (Email)
+Status default 'pending'
+Status enum OrderStatus char(8)
+Quantity default 1
+Rush default FALSE
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("parsed:\n%v\nwant:\n%v", got, want)
+	}
+}